@@ -1,14 +1,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
 	"github.com/Mirantis/ktl/pkg/cmd"
 	_ "github.com/Mirantis/ktl/pkg/filters" // register filters
+	"github.com/Mirantis/ktl/pkg/output"
 )
 
+// driftExitCode is returned when the live state differs from the desired one,
+// e.g. for the scheduled checks.
+const driftExitCode = 2
+
 func main() {
 	root := cmd.NewRootCommand()
 
@@ -16,6 +22,11 @@ func main() {
 
 	if err := root.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+
+		if errors.Is(err, output.ErrDrift) {
+			os.Exit(driftExitCode)
+		}
+
 		os.Exit(1)
 	}
 }
@@ -84,7 +84,12 @@ type Pipeline struct {
 	// Output specifies the format of the result
 	Output *Output `protobuf:"bytes,5,opt,name=output,proto3" json:"output,omitempty"`
 	// Args describe pipeline parameters
-	Args          *Args `protobuf:"bytes,6,opt,name=args,proto3,oneof" json:"args,omitempty"`
+	Args *Args `protobuf:"bytes,6,opt,name=args,proto3,oneof" json:"args,omitempty"`
+	// Fleet filters transform the merged manifests of all the clusters at once
+	FleetFilters []*FleetFilter `protobuf:"bytes,7,rep,name=fleet_filters,json=fleetFilters,proto3" json:"fleet_filters,omitempty"`
+	// Drift compares the resources of the source, the desired state, with the
+	// live state of the clusters instead of storing the output
+	Drift         *Drift `protobuf:"bytes,8,opt,name=drift,proto3,oneof" json:"drift,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -161,6 +166,92 @@ func (x *Pipeline) GetArgs() *Args {
 	return nil
 }
 
+func (x *Pipeline) GetFleetFilters() []*FleetFilter {
+	if x != nil {
+		return x.FleetFilters
+	}
+	return nil
+}
+
+func (x *Pipeline) GetDrift() *Drift {
+	if x != nil {
+		return x.Drift
+	}
+	return nil
+}
+
+type Drift struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Source of the live state passing the same filters, e.g. kubeconfig
+	Live *Source `protobuf:"bytes,1,opt,name=live,proto3" json:"live,omitempty"`
+	// Live cluster names by the source cluster names, the same by default
+	ClusterNames map[string]string `protobuf:"bytes,2,rep,name=cluster_names,json=clusterNames,proto3" json:"cluster_names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Report path, stdout by default
+	Path *string `protobuf:"bytes,3,opt,name=path,proto3,oneof" json:"path,omitempty"`
+	// Report format: text (default) or json
+	Format        *string `protobuf:"bytes,4,opt,name=format,proto3,oneof" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Drift) Reset() {
+	*x = Drift{}
+	mi := &file_run_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Drift) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Drift) ProtoMessage() {}
+
+func (x *Drift) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Drift.ProtoReflect.Descriptor instead.
+func (*Drift) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Drift) GetLive() *Source {
+	if x != nil {
+		return x.Live
+	}
+	return nil
+}
+
+func (x *Drift) GetClusterNames() map[string]string {
+	if x != nil {
+		return x.ClusterNames
+	}
+	return nil
+}
+
+func (x *Drift) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+func (x *Drift) GetFormat() string {
+	if x != nil && x.Format != nil {
+		return *x.Format
+	}
+	return ""
+}
+
 type Args struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Schema        *structpb.Struct       `protobuf:"bytes,1,opt,name=schema,proto3,oneof" json:"schema,omitempty"`
@@ -171,7 +262,7 @@ type Args struct {
 
 func (x *Args) Reset() {
 	*x = Args{}
-	mi := &file_run_proto_msgTypes[1]
+	mi := &file_run_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -183,7 +274,7 @@ func (x *Args) String() string {
 func (*Args) ProtoMessage() {}
 
 func (x *Args) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[1]
+	mi := &file_run_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -196,7 +287,7 @@ func (x *Args) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Args.ProtoReflect.Descriptor instead.
 func (*Args) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{1}
+	return file_run_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Args) GetSchema() *structpb.Struct {
@@ -223,7 +314,7 @@ type Source struct {
 
 func (x *Source) Reset() {
 	*x = Source{}
-	mi := &file_run_proto_msgTypes[2]
+	mi := &file_run_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -235,7 +326,7 @@ func (x *Source) String() string {
 func (*Source) ProtoMessage() {}
 
 func (x *Source) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[2]
+	mi := &file_run_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -248,7 +339,7 @@ func (x *Source) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Source.ProtoReflect.Descriptor instead.
 func (*Source) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{2}
+	return file_run_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Source) GetKubeconfig() *KubeConfigSource {
@@ -276,7 +367,7 @@ type KubeConfigSource struct {
 
 func (x *KubeConfigSource) Reset() {
 	*x = KubeConfigSource{}
-	mi := &file_run_proto_msgTypes[3]
+	mi := &file_run_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -288,7 +379,7 @@ func (x *KubeConfigSource) String() string {
 func (*KubeConfigSource) ProtoMessage() {}
 
 func (x *KubeConfigSource) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[3]
+	mi := &file_run_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -301,7 +392,7 @@ func (x *KubeConfigSource) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KubeConfigSource.ProtoReflect.Descriptor instead.
 func (*KubeConfigSource) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{3}
+	return file_run_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *KubeConfigSource) GetPath() string {
@@ -335,7 +426,7 @@ type KustomizeSource struct {
 
 func (x *KustomizeSource) Reset() {
 	*x = KustomizeSource{}
-	mi := &file_run_proto_msgTypes[4]
+	mi := &file_run_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -347,7 +438,7 @@ func (x *KustomizeSource) String() string {
 func (*KustomizeSource) ProtoMessage() {}
 
 func (x *KustomizeSource) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[4]
+	mi := &file_run_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -360,7 +451,7 @@ func (x *KustomizeSource) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KustomizeSource.ProtoReflect.Descriptor instead.
 func (*KustomizeSource) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{4}
+	return file_run_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *KustomizeSource) GetPath() string {
@@ -387,7 +478,7 @@ type ClusterSelector struct {
 
 func (x *ClusterSelector) Reset() {
 	*x = ClusterSelector{}
-	mi := &file_run_proto_msgTypes[5]
+	mi := &file_run_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -399,7 +490,7 @@ func (x *ClusterSelector) String() string {
 func (*ClusterSelector) ProtoMessage() {}
 
 func (x *ClusterSelector) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[5]
+	mi := &file_run_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -412,7 +503,7 @@ func (x *ClusterSelector) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClusterSelector.ProtoReflect.Descriptor instead.
 func (*ClusterSelector) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{5}
+	return file_run_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ClusterSelector) GetMatchNames() *PatternSelector {
@@ -441,7 +532,7 @@ type ResourceMatcher struct {
 
 func (x *ResourceMatcher) Reset() {
 	*x = ResourceMatcher{}
-	mi := &file_run_proto_msgTypes[6]
+	mi := &file_run_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -453,7 +544,7 @@ func (x *ResourceMatcher) String() string {
 func (*ResourceMatcher) ProtoMessage() {}
 
 func (x *ResourceMatcher) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[6]
+	mi := &file_run_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -466,7 +557,7 @@ func (x *ResourceMatcher) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceMatcher.ProtoReflect.Descriptor instead.
 func (*ResourceMatcher) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{6}
+	return file_run_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ResourceMatcher) GetMatchNames() *PatternSelector {
@@ -507,7 +598,7 @@ type PatternSelector struct {
 
 func (x *PatternSelector) Reset() {
 	*x = PatternSelector{}
-	mi := &file_run_proto_msgTypes[7]
+	mi := &file_run_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -519,7 +610,7 @@ func (x *PatternSelector) String() string {
 func (*PatternSelector) ProtoMessage() {}
 
 func (x *PatternSelector) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[7]
+	mi := &file_run_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +623,7 @@ func (x *PatternSelector) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PatternSelector.ProtoReflect.Descriptor instead.
 func (*PatternSelector) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{7}
+	return file_run_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *PatternSelector) GetInclude() []string {
@@ -560,7 +651,7 @@ type Filter struct {
 
 func (x *Filter) Reset() {
 	*x = Filter{}
-	mi := &file_run_proto_msgTypes[8]
+	mi := &file_run_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -572,7 +663,7 @@ func (x *Filter) String() string {
 func (*Filter) ProtoMessage() {}
 
 func (x *Filter) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[8]
+	mi := &file_run_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -585,7 +676,7 @@ func (x *Filter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Filter.ProtoReflect.Descriptor instead.
 func (*Filter) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{8}
+	return file_run_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Filter) GetSkip() *SkipFilter {
@@ -609,16 +700,72 @@ func (x *Filter) GetDefaults() DefaultsFilter {
 	return DefaultsFilter_UNKNOWN
 }
 
-type StarlarkFilter struct {
+type FleetFilter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Script        string                 `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	Starlark      *StarlarkFilter        `protobuf:"bytes,1,opt,name=starlark,proto3,oneof" json:"starlark,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FleetFilter) Reset() {
+	*x = FleetFilter{}
+	mi := &file_run_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FleetFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FleetFilter) ProtoMessage() {}
+
+func (x *FleetFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FleetFilter.ProtoReflect.Descriptor instead.
+func (*FleetFilter) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FleetFilter) GetStarlark() *StarlarkFilter {
+	if x != nil {
+		return x.Starlark
+	}
+	return nil
+}
+
+type StarlarkFilter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Inline script, mutually exclusive with script_file
+	Script string `protobuf:"bytes,1,opt,name=script,proto3" json:"script,omitempty"`
+	// Path to the script relative to the pipeline directory
+	ScriptFile *string `protobuf:"bytes,2,opt,name=script_file,json=scriptFile,proto3,oneof" json:"script_file,omitempty"`
+	// Paths to the CRD manifests relative to the pipeline directory, the custom
+	// resource schemas are registered in addition to the CRDs among the
+	// resources
+	CrdFiles []string `protobuf:"bytes,3,rep,name=crd_files,json=crdFiles,proto3" json:"crd_files,omitempty"`
+	// Reject the assignments not matching the resource schemas: unknown
+	// fields, wrong scalar types, lists instead of maps and vice versa
+	Strict *bool `protobuf:"varint,4,opt,name=strict,proto3,oneof" json:"strict,omitempty"`
+	// Execution limits, unlimited by default
+	Limits        *StarlarkLimits `protobuf:"bytes,5,opt,name=limits,proto3,oneof" json:"limits,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StarlarkFilter) Reset() {
 	*x = StarlarkFilter{}
-	mi := &file_run_proto_msgTypes[9]
+	mi := &file_run_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -630,7 +777,7 @@ func (x *StarlarkFilter) String() string {
 func (*StarlarkFilter) ProtoMessage() {}
 
 func (x *StarlarkFilter) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[9]
+	mi := &file_run_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -643,7 +790,7 @@ func (x *StarlarkFilter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StarlarkFilter.ProtoReflect.Descriptor instead.
 func (*StarlarkFilter) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{9}
+	return file_run_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *StarlarkFilter) GetScript() string {
@@ -653,18 +800,122 @@ func (x *StarlarkFilter) GetScript() string {
 	return ""
 }
 
+func (x *StarlarkFilter) GetScriptFile() string {
+	if x != nil && x.ScriptFile != nil {
+		return *x.ScriptFile
+	}
+	return ""
+}
+
+func (x *StarlarkFilter) GetCrdFiles() []string {
+	if x != nil {
+		return x.CrdFiles
+	}
+	return nil
+}
+
+func (x *StarlarkFilter) GetStrict() bool {
+	if x != nil && x.Strict != nil {
+		return *x.Strict
+	}
+	return false
+}
+
+func (x *StarlarkFilter) GetLimits() *StarlarkLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+type StarlarkLimits struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Maximum number of the Starlark computation steps
+	MaxSteps *uint64 `protobuf:"varint,1,opt,name=max_steps,json=maxSteps,proto3,oneof" json:"max_steps,omitempty"`
+	// Maximum execution time as Go duration, e.g. 30s
+	Timeout *string `protobuf:"bytes,2,opt,name=timeout,proto3,oneof" json:"timeout,omitempty"`
+	// Maximum number of the output resources
+	MaxOutput *uint32 `protobuf:"varint,3,opt,name=max_output,json=maxOutput,proto3,oneof" json:"max_output,omitempty"`
+	// Maximum number of the YAML nodes in the output resources, checked when
+	// the script finishes, so it caps the output size and not the memory used
+	// by the script, which is bounded by max_steps
+	MaxOutputNodes *uint32 `protobuf:"varint,4,opt,name=max_output_nodes,json=maxOutputNodes,proto3,oneof" json:"max_output_nodes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StarlarkLimits) Reset() {
+	*x = StarlarkLimits{}
+	mi := &file_run_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StarlarkLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StarlarkLimits) ProtoMessage() {}
+
+func (x *StarlarkLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StarlarkLimits.ProtoReflect.Descriptor instead.
+func (*StarlarkLimits) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StarlarkLimits) GetMaxSteps() uint64 {
+	if x != nil && x.MaxSteps != nil {
+		return *x.MaxSteps
+	}
+	return 0
+}
+
+func (x *StarlarkLimits) GetTimeout() string {
+	if x != nil && x.Timeout != nil {
+		return *x.Timeout
+	}
+	return ""
+}
+
+func (x *StarlarkLimits) GetMaxOutput() uint32 {
+	if x != nil && x.MaxOutput != nil {
+		return *x.MaxOutput
+	}
+	return 0
+}
+
+func (x *StarlarkLimits) GetMaxOutputNodes() uint32 {
+	if x != nil && x.MaxOutputNodes != nil {
+		return *x.MaxOutputNodes
+	}
+	return 0
+}
+
 type SkipFilter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Resources     []*ResourceSelector    `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
 	KeepResources []*ResourceSelector    `protobuf:"bytes,2,rep,name=keep_resources,json=keepResources,proto3" json:"keep_resources,omitempty"`
 	Fields        []string               `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Apply the filter only to the clusters with matching tags
+	MatchClusterTags *PatternSelector `protobuf:"bytes,4,opt,name=match_cluster_tags,json=matchClusterTags,proto3,oneof" json:"match_cluster_tags,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *SkipFilter) Reset() {
 	*x = SkipFilter{}
-	mi := &file_run_proto_msgTypes[10]
+	mi := &file_run_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -676,7 +927,7 @@ func (x *SkipFilter) String() string {
 func (*SkipFilter) ProtoMessage() {}
 
 func (x *SkipFilter) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[10]
+	mi := &file_run_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -689,7 +940,7 @@ func (x *SkipFilter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SkipFilter.ProtoReflect.Descriptor instead.
 func (*SkipFilter) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{10}
+	return file_run_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *SkipFilter) GetResources() []*ResourceSelector {
@@ -713,6 +964,13 @@ func (x *SkipFilter) GetFields() []string {
 	return nil
 }
 
+func (x *SkipFilter) GetMatchClusterTags() *PatternSelector {
+	if x != nil {
+		return x.MatchClusterTags
+	}
+	return nil
+}
+
 type ResourceSelector struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Group              *string                `protobuf:"bytes,1,opt,name=group,proto3,oneof" json:"group,omitempty"`
@@ -728,7 +986,7 @@ type ResourceSelector struct {
 
 func (x *ResourceSelector) Reset() {
 	*x = ResourceSelector{}
-	mi := &file_run_proto_msgTypes[11]
+	mi := &file_run_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -740,7 +998,7 @@ func (x *ResourceSelector) String() string {
 func (*ResourceSelector) ProtoMessage() {}
 
 func (x *ResourceSelector) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[11]
+	mi := &file_run_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -753,7 +1011,7 @@ func (x *ResourceSelector) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceSelector.ProtoReflect.Descriptor instead.
 func (*ResourceSelector) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{11}
+	return file_run_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *ResourceSelector) GetGroup() string {
@@ -815,13 +1073,17 @@ type Output struct {
 	CrdDescriptions     *CRDDescriptionsOutput     `protobuf:"bytes,6,opt,name=crd_descriptions,json=crdDescriptions,proto3,oneof" json:"crd_descriptions,omitempty"`
 	Kubectl             *KubectlOutput             `protobuf:"bytes,7,opt,name=kubectl,proto3,oneof" json:"kubectl,omitempty"`
 	Json                *JSONOutput                `protobuf:"bytes,8,opt,name=json,proto3,oneof" json:"json,omitempty"`
+	DiffReport          *DiffReportOutput          `protobuf:"bytes,9,opt,name=diff_report,json=diffReport,proto3,oneof" json:"diff_report,omitempty"`
+	Flux                *FluxOutput                `protobuf:"bytes,10,opt,name=flux,proto3,oneof" json:"flux,omitempty"`
+	Argocd              *ArgoCDOutput              `protobuf:"bytes,11,opt,name=argocd,proto3,oneof" json:"argocd,omitempty"`
+	K0Rdent             *K0RdentOutput             `protobuf:"bytes,12,opt,name=k0rdent,proto3,oneof" json:"k0rdent,omitempty"`
 	unknownFields       protoimpl.UnknownFields
 	sizeCache           protoimpl.SizeCache
 }
 
 func (x *Output) Reset() {
 	*x = Output{}
-	mi := &file_run_proto_msgTypes[12]
+	mi := &file_run_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -833,7 +1095,7 @@ func (x *Output) String() string {
 func (*Output) ProtoMessage() {}
 
 func (x *Output) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[12]
+	mi := &file_run_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -846,7 +1108,7 @@ func (x *Output) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Output.ProtoReflect.Descriptor instead.
 func (*Output) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{12}
+	return file_run_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Output) GetKustomize() *KustomizeOutput {
@@ -905,6 +1167,34 @@ func (x *Output) GetJson() *JSONOutput {
 	return nil
 }
 
+func (x *Output) GetDiffReport() *DiffReportOutput {
+	if x != nil {
+		return x.DiffReport
+	}
+	return nil
+}
+
+func (x *Output) GetFlux() *FluxOutput {
+	if x != nil {
+		return x.Flux
+	}
+	return nil
+}
+
+func (x *Output) GetArgocd() *ArgoCDOutput {
+	if x != nil {
+		return x.Argocd
+	}
+	return nil
+}
+
+func (x *Output) GetK0Rdent() *K0RdentOutput {
+	if x != nil {
+		return x.K0Rdent
+	}
+	return nil
+}
+
 type KubectlOutput struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Kubeconfig    *string                `protobuf:"bytes,1,opt,name=kubeconfig,proto3,oneof" json:"kubeconfig,omitempty"`
@@ -915,7 +1205,7 @@ type KubectlOutput struct {
 
 func (x *KubectlOutput) Reset() {
 	*x = KubectlOutput{}
-	mi := &file_run_proto_msgTypes[13]
+	mi := &file_run_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -927,7 +1217,7 @@ func (x *KubectlOutput) String() string {
 func (*KubectlOutput) ProtoMessage() {}
 
 func (x *KubectlOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[13]
+	mi := &file_run_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -940,7 +1230,7 @@ func (x *KubectlOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KubectlOutput.ProtoReflect.Descriptor instead.
 func (*KubectlOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{13}
+	return file_run_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *KubectlOutput) GetKubeconfig() string {
@@ -965,7 +1255,7 @@ type KustomizeOutput struct {
 
 func (x *KustomizeOutput) Reset() {
 	*x = KustomizeOutput{}
-	mi := &file_run_proto_msgTypes[14]
+	mi := &file_run_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -977,7 +1267,7 @@ func (x *KustomizeOutput) String() string {
 func (*KustomizeOutput) ProtoMessage() {}
 
 func (x *KustomizeOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[14]
+	mi := &file_run_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -990,18 +1280,21 @@ func (x *KustomizeOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KustomizeOutput.ProtoReflect.Descriptor instead.
 func (*KustomizeOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{14}
+	return file_run_proto_rawDescGZIP(), []int{17}
 }
 
 type KustomizeComponentsOutput struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Build the overlays of the clusters and compare the resources to the
+	// source ones, failing with the differing fields
+	Verify        *bool `protobuf:"varint,1,opt,name=verify,proto3,oneof" json:"verify,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *KustomizeComponentsOutput) Reset() {
 	*x = KustomizeComponentsOutput{}
-	mi := &file_run_proto_msgTypes[15]
+	mi := &file_run_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1013,7 +1306,7 @@ func (x *KustomizeComponentsOutput) String() string {
 func (*KustomizeComponentsOutput) ProtoMessage() {}
 
 func (x *KustomizeComponentsOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[15]
+	mi := &file_run_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1026,7 +1319,14 @@ func (x *KustomizeComponentsOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KustomizeComponentsOutput.ProtoReflect.Descriptor instead.
 func (*KustomizeComponentsOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{15}
+	return file_run_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *KustomizeComponentsOutput) GetVerify() bool {
+	if x != nil && x.Verify != nil {
+		return *x.Verify
+	}
+	return false
 }
 
 type HelmChartOutput struct {
@@ -1037,13 +1337,55 @@ type HelmChartOutput struct {
 	NoInlineValues *bool                  `protobuf:"varint,4,opt,name=no_inline_values,json=noInlineValues,proto3,oneof" json:"no_inline_values,omitempty"`
 	DefaultPresets []string               `protobuf:"bytes,5,rep,name=default_presets,json=defaultPresets,proto3" json:"default_presets,omitempty"`
 	RemovePresets  []string               `protobuf:"bytes,6,rep,name=remove_presets,json=removePresets,proto3" json:"remove_presets,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// Naming of the values: path (default) with the flat names of the
+	// resource paths, or nested with the values nested by the resource name,
+	// the kind and the field, e.g. myapp.deployment.spec.replicas
+	ValuesNaming *string `protobuf:"bytes,7,opt,name=values_naming,json=valuesNaming,proto3,oneof" json:"values_naming,omitempty"`
+	// Short names of the common fields: replicas, and image, resources and
+	// env of the containers, e.g. myapp.deployment.image
+	ShortAliases *bool `protobuf:"varint,8,opt,name=short_aliases,json=shortAliases,proto3,oneof" json:"short_aliases,omitempty"`
+	// Path of the file with the names of the values by their resource paths,
+	// read as the values aliases when present and rewritten, so the names
+	// stay stable across the generations
+	AliasesFile *string `protobuf:"bytes,9,opt,name=aliases_file,json=aliasesFile,proto3,oneof" json:"aliases_file,omitempty"`
+	// Template the parts of the values differing between the clusters: the
+	// cluster names are referred to as global.clusterName, and the common
+	// prefixes and suffixes are kept in the templates. The instances get
+	// clusterName and clusterTags as the global values
+	SubstringTemplates *bool `protobuf:"varint,10,opt,name=substring_templates,json=substringTemplates,proto3,oneof" json:"substring_templates,omitempty"`
+	// Directory of the packaged chart: the <name>-<version>.tgz archive and
+	// index.yaml of the Helm repository, updated when present
+	PackageDir *string `protobuf:"bytes,11,opt,name=package_dir,json=packageDir,proto3,oneof" json:"package_dir,omitempty"`
+	// Base URL of the archives in index.yaml, the archives are referred to
+	// by the relative URLs by default
+	PackageUrl *string `protobuf:"bytes,12,opt,name=package_url,json=packageUrl,proto3,oneof" json:"package_url,omitempty"`
+	// Directory of the OCI image layout with the chart, updated when present,
+	// e.g. to be pushed by oras cp --from-oci-layout
+	OciLayout *string `protobuf:"bytes,13,opt,name=oci_layout,json=ociLayout,proto3,oneof" json:"oci_layout,omitempty"`
+	// Deployment of the chart to the clusters: kustomize (default) with
+	// overlays/<cluster> inflating the chart by helmCharts, values with
+	// values/<cluster>.yaml for helm install, helmfile with helmfile.yaml
+	// releasing the chart per cluster, or fluxHelmRelease with the Flux
+	// HelmReleases in clusters/<cluster>
+	Deployment *string `protobuf:"bytes,14,opt,name=deployment,proto3,oneof" json:"deployment,omitempty"`
+	// Layer the values files of the values and helmfile deployments:
+	// values/<preset>.yaml with the values of the presets, and
+	// values/<cluster>.yaml with the values of the cluster only
+	LayeredValues *bool `protobuf:"varint,15,opt,name=layered_values,json=layeredValues,proto3,oneof" json:"layered_values,omitempty"`
+	// Render the chart with the values of the clusters read from the files of
+	// the deployment, validated by values.schema.json, and compare the
+	// resources to the source ones, failing with the differing fields
+	Verify *bool `protobuf:"varint,16,opt,name=verify,proto3,oneof" json:"verify,omitempty"`
+	// Flux source of the chart of the fluxHelmRelease deployment, as of the
+	// Flux output: the existing flux-system source by default
+	Flux          *FluxOutput `protobuf:"bytes,17,opt,name=flux,proto3,oneof" json:"flux,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HelmChartOutput) Reset() {
 	*x = HelmChartOutput{}
-	mi := &file_run_proto_msgTypes[16]
+	mi := &file_run_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1055,7 +1397,7 @@ func (x *HelmChartOutput) String() string {
 func (*HelmChartOutput) ProtoMessage() {}
 
 func (x *HelmChartOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[16]
+	mi := &file_run_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1068,7 +1410,7 @@ func (x *HelmChartOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HelmChartOutput.ProtoReflect.Descriptor instead.
 func (*HelmChartOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{16}
+	return file_run_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *HelmChartOutput) GetName() string {
@@ -1113,6 +1455,83 @@ func (x *HelmChartOutput) GetRemovePresets() []string {
 	return nil
 }
 
+func (x *HelmChartOutput) GetValuesNaming() string {
+	if x != nil && x.ValuesNaming != nil {
+		return *x.ValuesNaming
+	}
+	return ""
+}
+
+func (x *HelmChartOutput) GetShortAliases() bool {
+	if x != nil && x.ShortAliases != nil {
+		return *x.ShortAliases
+	}
+	return false
+}
+
+func (x *HelmChartOutput) GetAliasesFile() string {
+	if x != nil && x.AliasesFile != nil {
+		return *x.AliasesFile
+	}
+	return ""
+}
+
+func (x *HelmChartOutput) GetSubstringTemplates() bool {
+	if x != nil && x.SubstringTemplates != nil {
+		return *x.SubstringTemplates
+	}
+	return false
+}
+
+func (x *HelmChartOutput) GetPackageDir() string {
+	if x != nil && x.PackageDir != nil {
+		return *x.PackageDir
+	}
+	return ""
+}
+
+func (x *HelmChartOutput) GetPackageUrl() string {
+	if x != nil && x.PackageUrl != nil {
+		return *x.PackageUrl
+	}
+	return ""
+}
+
+func (x *HelmChartOutput) GetOciLayout() string {
+	if x != nil && x.OciLayout != nil {
+		return *x.OciLayout
+	}
+	return ""
+}
+
+func (x *HelmChartOutput) GetDeployment() string {
+	if x != nil && x.Deployment != nil {
+		return *x.Deployment
+	}
+	return ""
+}
+
+func (x *HelmChartOutput) GetLayeredValues() bool {
+	if x != nil && x.LayeredValues != nil {
+		return *x.LayeredValues
+	}
+	return false
+}
+
+func (x *HelmChartOutput) GetVerify() bool {
+	if x != nil && x.Verify != nil {
+		return *x.Verify
+	}
+	return false
+}
+
+func (x *HelmChartOutput) GetFlux() *FluxOutput {
+	if x != nil {
+		return x.Flux
+	}
+	return nil
+}
+
 type CRDDescriptionsOutput struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Path          *string                `protobuf:"bytes,1,opt,name=path,proto3,oneof" json:"path,omitempty"`
@@ -1122,7 +1541,7 @@ type CRDDescriptionsOutput struct {
 
 func (x *CRDDescriptionsOutput) Reset() {
 	*x = CRDDescriptionsOutput{}
-	mi := &file_run_proto_msgTypes[17]
+	mi := &file_run_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1134,7 +1553,7 @@ func (x *CRDDescriptionsOutput) String() string {
 func (*CRDDescriptionsOutput) ProtoMessage() {}
 
 func (x *CRDDescriptionsOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[17]
+	mi := &file_run_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1147,7 +1566,7 @@ func (x *CRDDescriptionsOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CRDDescriptionsOutput.ProtoReflect.Descriptor instead.
 func (*CRDDescriptionsOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{17}
+	return file_run_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *CRDDescriptionsOutput) GetPath() string {
@@ -1167,7 +1586,7 @@ type JSONOutput struct {
 
 func (x *JSONOutput) Reset() {
 	*x = JSONOutput{}
-	mi := &file_run_proto_msgTypes[18]
+	mi := &file_run_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1179,7 +1598,7 @@ func (x *JSONOutput) String() string {
 func (*JSONOutput) ProtoMessage() {}
 
 func (x *JSONOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[18]
+	mi := &file_run_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1192,7 +1611,7 @@ func (x *JSONOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JSONOutput.ProtoReflect.Descriptor instead.
 func (*JSONOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{18}
+	return file_run_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *JSONOutput) GetPath() string {
@@ -1209,17 +1628,369 @@ func (x *JSONOutput) GetSchema() *structpb.Struct {
 	return nil
 }
 
+type FluxOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Repository URL, the source object is generated when set, otherwise the
+	// Flux objects refer to the existing source, e.g. created by flux bootstrap
+	Url *string `protobuf:"bytes,1,opt,name=url,proto3,oneof" json:"url,omitempty"`
+	// Kind of the source: GitRepository (default) or OCIRepository
+	SourceKind *string `protobuf:"bytes,2,opt,name=source_kind,json=sourceKind,proto3,oneof" json:"source_kind,omitempty"`
+	// Name of the source, fleet by default when the url is set, so the
+	// flux-system source of flux bootstrap is kept, otherwise flux-system
+	SourceName *string `protobuf:"bytes,3,opt,name=source_name,json=sourceName,proto3,oneof" json:"source_name,omitempty"`
+	// Branch of the GitRepository (main by default) or tag of the
+	// OCIRepository (latest by default)
+	Ref *string `protobuf:"bytes,4,opt,name=ref,proto3,oneof" json:"ref,omitempty"`
+	// Reconciliation interval, 10m by default
+	Interval *string `protobuf:"bytes,5,opt,name=interval,proto3,oneof" json:"interval,omitempty"`
+	// Path of the output directory in the source, the paths of the Flux
+	// objects are prefixed with it, the root of the source by default
+	Path          *string `protobuf:"bytes,6,opt,name=path,proto3,oneof" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FluxOutput) Reset() {
+	*x = FluxOutput{}
+	mi := &file_run_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FluxOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FluxOutput) ProtoMessage() {}
+
+func (x *FluxOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FluxOutput.ProtoReflect.Descriptor instead.
+func (*FluxOutput) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FluxOutput) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *FluxOutput) GetSourceKind() string {
+	if x != nil && x.SourceKind != nil {
+		return *x.SourceKind
+	}
+	return ""
+}
+
+func (x *FluxOutput) GetSourceName() string {
+	if x != nil && x.SourceName != nil {
+		return *x.SourceName
+	}
+	return ""
+}
+
+func (x *FluxOutput) GetRef() string {
+	if x != nil && x.Ref != nil {
+		return *x.Ref
+	}
+	return ""
+}
+
+func (x *FluxOutput) GetInterval() string {
+	if x != nil && x.Interval != nil {
+		return *x.Interval
+	}
+	return ""
+}
+
+func (x *FluxOutput) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+type ArgoCDOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Repository URL of the generated manifests
+	RepoUrl string `protobuf:"bytes,1,opt,name=repo_url,json=repoUrl,proto3" json:"repo_url,omitempty"`
+	// Revision of the repository, HEAD by default
+	TargetRevision *string `protobuf:"bytes,2,opt,name=target_revision,json=targetRevision,proto3,oneof" json:"target_revision,omitempty"`
+	// Generator of the ApplicationSet: list (default) with an element per
+	// cluster, clusters selecting the Argo CD clusters labeled with
+	// ktl.mirantis.com/cluster, or applications for an Application per cluster
+	Generator *string `protobuf:"bytes,3,opt,name=generator,proto3,oneof" json:"generator,omitempty"`
+	// Name of the ApplicationSet and the prefix of the Applications, ktl by
+	// default
+	Name *string `protobuf:"bytes,4,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	// Namespace of Argo CD, argocd by default
+	Namespace *string `protobuf:"bytes,5,opt,name=namespace,proto3,oneof" json:"namespace,omitempty"`
+	// Argo CD project, default by default
+	Project *string `protobuf:"bytes,6,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	// Layout of the manifests as the Helm chart deployed by the kustomize
+	// overlays, the Kustomize components are generated by default
+	HelmChart     *HelmChartOutput `protobuf:"bytes,7,opt,name=helm_chart,json=helmChart,proto3,oneof" json:"helm_chart,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArgoCDOutput) Reset() {
+	*x = ArgoCDOutput{}
+	mi := &file_run_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArgoCDOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArgoCDOutput) ProtoMessage() {}
+
+func (x *ArgoCDOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArgoCDOutput.ProtoReflect.Descriptor instead.
+func (*ArgoCDOutput) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ArgoCDOutput) GetRepoUrl() string {
+	if x != nil {
+		return x.RepoUrl
+	}
+	return ""
+}
+
+func (x *ArgoCDOutput) GetTargetRevision() string {
+	if x != nil && x.TargetRevision != nil {
+		return *x.TargetRevision
+	}
+	return ""
+}
+
+func (x *ArgoCDOutput) GetGenerator() string {
+	if x != nil && x.Generator != nil {
+		return *x.Generator
+	}
+	return ""
+}
+
+func (x *ArgoCDOutput) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ArgoCDOutput) GetNamespace() string {
+	if x != nil && x.Namespace != nil {
+		return *x.Namespace
+	}
+	return ""
+}
+
+func (x *ArgoCDOutput) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+func (x *ArgoCDOutput) GetHelmChart() *HelmChartOutput {
+	if x != nil {
+		return x.HelmChart
+	}
+	return nil
+}
+
+type K0RdentOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Chart packaged as the ServiceTemplate, without deployment and
+	// layered_values
+	HelmChart *HelmChartOutput `protobuf:"bytes,1,opt,name=helm_chart,json=helmChart,proto3" json:"helm_chart,omitempty"`
+	// Namespace of the ServiceTemplate, the HelmRepository and the
+	// ClusterDeployments, kcm-system by default
+	Namespace *string `protobuf:"bytes,2,opt,name=namespace,proto3,oneof" json:"namespace,omitempty"`
+	// Name of the HelmRepository serving the chart, ktl by default
+	Repository *string `protobuf:"bytes,3,opt,name=repository,proto3,oneof" json:"repository,omitempty"`
+	// URL of the HelmRepository, the repository is generated when set, the
+	// oci:// URLs are served as the OCI repositories
+	RepositoryUrl *string `protobuf:"bytes,4,opt,name=repository_url,json=repositoryUrl,proto3,oneof" json:"repository_url,omitempty"`
+	// Namespace the service is installed to, the chart name by default
+	ServiceNamespace *string `protobuf:"bytes,5,opt,name=service_namespace,json=serviceNamespace,proto3,oneof" json:"service_namespace,omitempty"`
+	// Objects defining the services: multiClusterService (default) with a
+	// MultiClusterService per the group of the clusters sharing the values,
+	// or clusterDeployment with the services of each ClusterDeployment
+	Mode          *string `protobuf:"bytes,6,opt,name=mode,proto3,oneof" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *K0RdentOutput) Reset() {
+	*x = K0RdentOutput{}
+	mi := &file_run_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *K0RdentOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*K0RdentOutput) ProtoMessage() {}
+
+func (x *K0RdentOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use K0RdentOutput.ProtoReflect.Descriptor instead.
+func (*K0RdentOutput) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *K0RdentOutput) GetHelmChart() *HelmChartOutput {
+	if x != nil {
+		return x.HelmChart
+	}
+	return nil
+}
+
+func (x *K0RdentOutput) GetNamespace() string {
+	if x != nil && x.Namespace != nil {
+		return *x.Namespace
+	}
+	return ""
+}
+
+func (x *K0RdentOutput) GetRepository() string {
+	if x != nil && x.Repository != nil {
+		return *x.Repository
+	}
+	return ""
+}
+
+func (x *K0RdentOutput) GetRepositoryUrl() string {
+	if x != nil && x.RepositoryUrl != nil {
+		return *x.RepositoryUrl
+	}
+	return ""
+}
+
+func (x *K0RdentOutput) GetServiceNamespace() string {
+	if x != nil && x.ServiceNamespace != nil {
+		return *x.ServiceNamespace
+	}
+	return ""
+}
+
+func (x *K0RdentOutput) GetMode() string {
+	if x != nil && x.Mode != nil {
+		return *x.Mode
+	}
+	return ""
+}
+
+type DiffReportOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Path  *string                `protobuf:"bytes,1,opt,name=path,proto3,oneof" json:"path,omitempty"`
+	// Report format: markdown (default), html or csv
+	Format        *string `protobuf:"bytes,2,opt,name=format,proto3,oneof" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffReportOutput) Reset() {
+	*x = DiffReportOutput{}
+	mi := &file_run_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffReportOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffReportOutput) ProtoMessage() {}
+
+func (x *DiffReportOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_run_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffReportOutput.ProtoReflect.Descriptor instead.
+func (*DiffReportOutput) Descriptor() ([]byte, []int) {
+	return file_run_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DiffReportOutput) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+func (x *DiffReportOutput) GetFormat() string {
+	if x != nil && x.Format != nil {
+		return *x.Format
+	}
+	return ""
+}
+
 type ColumnarFileOutput struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          *string                `protobuf:"bytes,1,opt,name=path,proto3,oneof" json:"path,omitempty"`
-	Columns       []*ColumnOutput        `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Path    *string                `protobuf:"bytes,1,opt,name=path,proto3,oneof" json:"path,omitempty"`
+	Columns []*ColumnOutput        `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+	// Columns sorting the rows, prefixed with `-` for the descending order, the
+	// remaining columns break the ties
+	SortBy []string `protobuf:"bytes,3,rep,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	// Columns grouping the rows, the other columns are aggregated
+	GroupBy []string `protobuf:"bytes,4,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	// Column listed per cluster, the clusters become the columns
+	Pivot         *string `protobuf:"bytes,5,opt,name=pivot,proto3,oneof" json:"pivot,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ColumnarFileOutput) Reset() {
 	*x = ColumnarFileOutput{}
-	mi := &file_run_proto_msgTypes[19]
+	mi := &file_run_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1231,7 +2002,7 @@ func (x *ColumnarFileOutput) String() string {
 func (*ColumnarFileOutput) ProtoMessage() {}
 
 func (x *ColumnarFileOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[19]
+	mi := &file_run_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1244,7 +2015,7 @@ func (x *ColumnarFileOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ColumnarFileOutput.ProtoReflect.Descriptor instead.
 func (*ColumnarFileOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{19}
+	return file_run_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ColumnarFileOutput) GetPath() string {
@@ -1261,19 +2032,45 @@ func (x *ColumnarFileOutput) GetColumns() []*ColumnOutput {
 	return nil
 }
 
+func (x *ColumnarFileOutput) GetSortBy() []string {
+	if x != nil {
+		return x.SortBy
+	}
+	return nil
+}
+
+func (x *ColumnarFileOutput) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *ColumnarFileOutput) GetPivot() string {
+	if x != nil && x.Pivot != nil {
+		return *x.Pivot
+	}
+	return ""
+}
+
 type ColumnOutput struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
-	Field         *string                `protobuf:"bytes,3,opt,name=field,proto3,oneof" json:"field,omitempty"`
-	Text          *string                `protobuf:"bytes,4,opt,name=text,proto3,oneof" json:"text,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Field       *string                `protobuf:"bytes,3,opt,name=field,proto3,oneof" json:"field,omitempty"`
+	Text        *string                `protobuf:"bytes,4,opt,name=text,proto3,oneof" json:"text,omitempty"`
+	// Starlark expression with the resource as `it` and the cluster as `cluster`
+	Expr *string `protobuf:"bytes,5,opt,name=expr,proto3,oneof" json:"expr,omitempty"`
+	// Function aggregating the grouped values: count, sum, min, max or
+	// distinct (default)
+	Aggregate     *string `protobuf:"bytes,6,opt,name=aggregate,proto3,oneof" json:"aggregate,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ColumnOutput) Reset() {
 	*x = ColumnOutput{}
-	mi := &file_run_proto_msgTypes[20]
+	mi := &file_run_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1285,7 +2082,7 @@ func (x *ColumnOutput) String() string {
 func (*ColumnOutput) ProtoMessage() {}
 
 func (x *ColumnOutput) ProtoReflect() protoreflect.Message {
-	mi := &file_run_proto_msgTypes[20]
+	mi := &file_run_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1298,7 +2095,7 @@ func (x *ColumnOutput) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ColumnOutput.ProtoReflect.Descriptor instead.
 func (*ColumnOutput) Descriptor() ([]byte, []int) {
-	return file_run_proto_rawDescGZIP(), []int{20}
+	return file_run_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ColumnOutput) GetName() string {
@@ -1329,11 +2126,25 @@ func (x *ColumnOutput) GetText() string {
 	return ""
 }
 
+func (x *ColumnOutput) GetExpr() string {
+	if x != nil && x.Expr != nil {
+		return *x.Expr
+	}
+	return ""
+}
+
+func (x *ColumnOutput) GetAggregate() string {
+	if x != nil && x.Aggregate != nil {
+		return *x.Aggregate
+	}
+	return ""
+}
+
 var File_run_proto protoreflect.FileDescriptor
 
 const file_run_proto_rawDesc = "" +
 	"\n" +
-	"\trun.proto\x12\x04apis\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1cgoogle/api/annotations.proto\"\xe2\x01\n" +
+	"\trun.proto\x12\x04apis\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1cgoogle/api/annotations.proto\"\xcc\x02\n" +
 	"\bPipeline\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12$\n" +
@@ -1341,8 +2152,21 @@ const file_run_proto_rawDesc = "" +
 	"\afilters\x18\x04 \x03(\v2\f.apis.FilterR\afilters\x12$\n" +
 	"\x06output\x18\x05 \x01(\v2\f.apis.OutputR\x06output\x12#\n" +
 	"\x04args\x18\x06 \x01(\v2\n" +
-	".apis.ArgsH\x00R\x04args\x88\x01\x01B\a\n" +
-	"\x05_args\"}\n" +
+	".apis.ArgsH\x00R\x04args\x88\x01\x01\x126\n" +
+	"\rfleet_filters\x18\a \x03(\v2\x11.apis.FleetFilterR\ffleetFilters\x12&\n" +
+	"\x05drift\x18\b \x01(\v2\v.apis.DriftH\x01R\x05drift\x88\x01\x01B\a\n" +
+	"\x05_argsB\b\n" +
+	"\x06_drift\"\xf8\x01\n" +
+	"\x05Drift\x12 \n" +
+	"\x04live\x18\x01 \x01(\v2\f.apis.SourceR\x04live\x12B\n" +
+	"\rcluster_names\x18\x02 \x03(\v2\x1d.apis.Drift.ClusterNamesEntryR\fclusterNames\x12\x17\n" +
+	"\x04path\x18\x03 \x01(\tH\x00R\x04path\x88\x01\x01\x12\x1b\n" +
+	"\x06format\x18\x04 \x01(\tH\x01R\x06format\x88\x01\x01\x1a?\n" +
+	"\x11ClusterNamesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\a\n" +
+	"\x05_pathB\t\n" +
+	"\a_format\"}\n" +
 	"\x04Args\x124\n" +
 	"\x06schema\x18\x01 \x01(\v2\x17.google.protobuf.StructH\x00R\x06schema\x88\x01\x01\x12$\n" +
 	"\vschema_file\x18\x02 \x01(\tH\x01R\n" +
@@ -1389,14 +2213,39 @@ const file_run_proto_rawDesc = "" +
 	"\bdefaults\x18\x03 \x01(\x0e2\x14.apis.DefaultsFilterH\x02R\bdefaults\x88\x01\x01B\a\n" +
 	"\x05_skipB\v\n" +
 	"\t_starlarkB\v\n" +
-	"\t_defaults\"(\n" +
+	"\t_defaults\"Q\n" +
+	"\vFleetFilter\x125\n" +
+	"\bstarlark\x18\x01 \x01(\v2\x14.apis.StarlarkFilterH\x00R\bstarlark\x88\x01\x01B\v\n" +
+	"\t_starlark\"\xe1\x01\n" +
 	"\x0eStarlarkFilter\x12\x16\n" +
-	"\x06script\x18\x01 \x01(\tR\x06script\"\x99\x01\n" +
+	"\x06script\x18\x01 \x01(\tR\x06script\x12$\n" +
+	"\vscript_file\x18\x02 \x01(\tH\x00R\n" +
+	"scriptFile\x88\x01\x01\x12\x1b\n" +
+	"\tcrd_files\x18\x03 \x03(\tR\bcrdFiles\x12\x1b\n" +
+	"\x06strict\x18\x04 \x01(\bH\x01R\x06strict\x88\x01\x01\x121\n" +
+	"\x06limits\x18\x05 \x01(\v2\x14.apis.StarlarkLimitsH\x02R\x06limits\x88\x01\x01B\x0e\n" +
+	"\f_script_fileB\t\n" +
+	"\a_strictB\t\n" +
+	"\a_limits\"\xe2\x01\n" +
+	"\x0eStarlarkLimits\x12 \n" +
+	"\tmax_steps\x18\x01 \x01(\x04H\x00R\bmaxSteps\x88\x01\x01\x12\x1d\n" +
+	"\atimeout\x18\x02 \x01(\tH\x01R\atimeout\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"max_output\x18\x03 \x01(\rH\x02R\tmaxOutput\x88\x01\x01\x12-\n" +
+	"\x10max_output_nodes\x18\x04 \x01(\rH\x03R\x0emaxOutputNodes\x88\x01\x01B\f\n" +
+	"\n" +
+	"_max_stepsB\n" +
+	"\n" +
+	"\b_timeoutB\r\n" +
+	"\v_max_outputB\x13\n" +
+	"\x11_max_output_nodes\"\xfa\x01\n" +
 	"\n" +
 	"SkipFilter\x124\n" +
 	"\tresources\x18\x01 \x03(\v2\x16.apis.ResourceSelectorR\tresources\x12=\n" +
 	"\x0ekeep_resources\x18\x02 \x03(\v2\x16.apis.ResourceSelectorR\rkeepResources\x12\x16\n" +
-	"\x06fields\x18\x03 \x03(\tR\x06fields\"\xe4\x02\n" +
+	"\x06fields\x18\x03 \x03(\tR\x06fields\x12H\n" +
+	"\x12match_cluster_tags\x18\x04 \x01(\v2\x15.apis.PatternSelectorH\x00R\x10matchClusterTags\x88\x01\x01B\x15\n" +
+	"\x13_match_cluster_tags\"\xe4\x02\n" +
 	"\x10ResourceSelector\x12\x19\n" +
 	"\x05group\x18\x01 \x01(\tH\x00R\x05group\x88\x01\x01\x12\x1d\n" +
 	"\aversion\x18\x02 \x01(\tH\x01R\aversion\x88\x01\x01\x12\x17\n" +
@@ -1413,7 +2262,7 @@ const file_run_proto_rawDesc = "" +
 	"\n" +
 	"_namespaceB\x16\n" +
 	"\x14_annotation_selectorB\x11\n" +
-	"\x0f_label_selector\"\xda\x04\n" +
+	"\x0f_label_selector\"\xd8\x06\n" +
 	"\x06Output\x128\n" +
 	"\tkustomize\x18\x01 \x01(\v2\x15.apis.KustomizeOutputH\x00R\tkustomize\x88\x01\x01\x12W\n" +
 	"\x14kustomize_components\x18\x02 \x01(\v2\x1f.apis.KustomizeComponentsOutputH\x01R\x13kustomizeComponents\x88\x01\x01\x129\n" +
@@ -1423,7 +2272,14 @@ const file_run_proto_rawDesc = "" +
 	"\x05table\x18\x05 \x01(\v2\x18.apis.ColumnarFileOutputH\x04R\x05table\x88\x01\x01\x12K\n" +
 	"\x10crd_descriptions\x18\x06 \x01(\v2\x1b.apis.CRDDescriptionsOutputH\x05R\x0fcrdDescriptions\x88\x01\x01\x122\n" +
 	"\akubectl\x18\a \x01(\v2\x13.apis.KubectlOutputH\x06R\akubectl\x88\x01\x01\x12)\n" +
-	"\x04json\x18\b \x01(\v2\x10.apis.JSONOutputH\aR\x04json\x88\x01\x01B\f\n" +
+	"\x04json\x18\b \x01(\v2\x10.apis.JSONOutputH\aR\x04json\x88\x01\x01\x12<\n" +
+	"\vdiff_report\x18\t \x01(\v2\x16.apis.DiffReportOutputH\bR\n" +
+	"diffReport\x88\x01\x01\x12)\n" +
+	"\x04flux\x18\n" +
+	" \x01(\v2\x10.apis.FluxOutputH\tR\x04flux\x88\x01\x01\x12/\n" +
+	"\x06argocd\x18\v \x01(\v2\x12.apis.ArgoCDOutputH\n" +
+	"R\x06argocd\x88\x01\x01\x122\n" +
+	"\ak0rdent\x18\f \x01(\v2\x13.apis.K0rdentOutputH\vR\ak0rdent\x88\x01\x01B\f\n" +
 	"\n" +
 	"_kustomizeB\x17\n" +
 	"\x15_kustomize_componentsB\r\n" +
@@ -1433,7 +2289,12 @@ const file_run_proto_rawDesc = "" +
 	"\x11_crd_descriptionsB\n" +
 	"\n" +
 	"\b_kubectlB\a\n" +
-	"\x05_json\"n\n" +
+	"\x05_jsonB\x0e\n" +
+	"\f_diff_reportB\a\n" +
+	"\x05_fluxB\t\n" +
+	"\a_argocdB\n" +
+	"\n" +
+	"\b_k0rdent\"n\n" +
 	"\rKubectlOutput\x12#\n" +
 	"\n" +
 	"kubeconfig\x18\x01 \x01(\tH\x00R\n" +
@@ -1442,19 +2303,50 @@ const file_run_proto_rawDesc = "" +
 	"\v_kubeconfigB\n" +
 	"\n" +
 	"\b_cluster\"\x11\n" +
-	"\x0fKustomizeOutput\"\x1b\n" +
-	"\x19KustomizeComponentsOutput\"\xe6\x02\n" +
+	"\x0fKustomizeOutput\"C\n" +
+	"\x19KustomizeComponentsOutput\x12\x1b\n" +
+	"\x06verify\x18\x01 \x01(\bH\x00R\x06verify\x88\x01\x01B\t\n" +
+	"\a_verify\"\xd3\a\n" +
 	"\x0fHelmChartOutput\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12O\n" +
 	"\x0evalues_aliases\x18\x03 \x03(\v2(.apis.HelmChartOutput.ValuesAliasesEntryR\rvaluesAliases\x12-\n" +
 	"\x10no_inline_values\x18\x04 \x01(\bH\x00R\x0enoInlineValues\x88\x01\x01\x12'\n" +
 	"\x0fdefault_presets\x18\x05 \x03(\tR\x0edefaultPresets\x12%\n" +
-	"\x0eremove_presets\x18\x06 \x03(\tR\rremovePresets\x1a@\n" +
+	"\x0eremove_presets\x18\x06 \x03(\tR\rremovePresets\x12(\n" +
+	"\rvalues_naming\x18\a \x01(\tH\x01R\fvaluesNaming\x88\x01\x01\x12(\n" +
+	"\rshort_aliases\x18\b \x01(\bH\x02R\fshortAliases\x88\x01\x01\x12&\n" +
+	"\faliases_file\x18\t \x01(\tH\x03R\valiasesFile\x88\x01\x01\x124\n" +
+	"\x13substring_templates\x18\n" +
+	" \x01(\bH\x04R\x12substringTemplates\x88\x01\x01\x12$\n" +
+	"\vpackage_dir\x18\v \x01(\tH\x05R\n" +
+	"packageDir\x88\x01\x01\x12$\n" +
+	"\vpackage_url\x18\f \x01(\tH\x06R\n" +
+	"packageUrl\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"oci_layout\x18\r \x01(\tH\aR\tociLayout\x88\x01\x01\x12#\n" +
+	"\n" +
+	"deployment\x18\x0e \x01(\tH\bR\n" +
+	"deployment\x88\x01\x01\x12*\n" +
+	"\x0elayered_values\x18\x0f \x01(\bH\tR\rlayeredValues\x88\x01\x01\x12\x1b\n" +
+	"\x06verify\x18\x10 \x01(\bH\n" +
+	"R\x06verify\x88\x01\x01\x12)\n" +
+	"\x04flux\x18\x11 \x01(\v2\x10.apis.FluxOutputH\vR\x04flux\x88\x01\x01\x1a@\n" +
 	"\x12ValuesAliasesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x13\n" +
-	"\x11_no_inline_values\"9\n" +
+	"\x11_no_inline_valuesB\x10\n" +
+	"\x0e_values_namingB\x10\n" +
+	"\x0e_short_aliasesB\x0f\n" +
+	"\r_aliases_fileB\x16\n" +
+	"\x14_substring_templatesB\x0e\n" +
+	"\f_package_dirB\x0e\n" +
+	"\f_package_urlB\r\n" +
+	"\v_oci_layoutB\r\n" +
+	"\v_deploymentB\x11\n" +
+	"\x0f_layered_valuesB\t\n" +
+	"\a_verifyB\a\n" +
+	"\x05_flux\"9\n" +
 	"\x15CRDDescriptionsOutput\x12\x17\n" +
 	"\x04path\x18\x01 \x01(\tH\x00R\x04path\x88\x01\x01B\a\n" +
 	"\x05_path\"o\n" +
@@ -1463,19 +2355,83 @@ const file_run_proto_rawDesc = "" +
 	"\x04path\x18\x01 \x01(\tH\x00R\x04path\x88\x01\x01\x124\n" +
 	"\x06schema\x18\x02 \x01(\v2\x17.google.protobuf.StructH\x01R\x06schema\x88\x01\x01B\a\n" +
 	"\x05_pathB\t\n" +
-	"\a_schema\"d\n" +
+	"\a_schema\"\x86\x02\n" +
+	"\n" +
+	"FluxOutput\x12\x15\n" +
+	"\x03url\x18\x01 \x01(\tH\x00R\x03url\x88\x01\x01\x12$\n" +
+	"\vsource_kind\x18\x02 \x01(\tH\x01R\n" +
+	"sourceKind\x88\x01\x01\x12$\n" +
+	"\vsource_name\x18\x03 \x01(\tH\x02R\n" +
+	"sourceName\x88\x01\x01\x12\x15\n" +
+	"\x03ref\x18\x04 \x01(\tH\x03R\x03ref\x88\x01\x01\x12\x1f\n" +
+	"\binterval\x18\x05 \x01(\tH\x04R\binterval\x88\x01\x01\x12\x17\n" +
+	"\x04path\x18\x06 \x01(\tH\x05R\x04path\x88\x01\x01B\x06\n" +
+	"\x04_urlB\x0e\n" +
+	"\f_source_kindB\x0e\n" +
+	"\f_source_nameB\x06\n" +
+	"\x04_refB\v\n" +
+	"\t_intervalB\a\n" +
+	"\x05_path\"\xe4\x02\n" +
+	"\fArgoCDOutput\x12\x19\n" +
+	"\brepo_url\x18\x01 \x01(\tR\arepoUrl\x12,\n" +
+	"\x0ftarget_revision\x18\x02 \x01(\tH\x00R\x0etargetRevision\x88\x01\x01\x12!\n" +
+	"\tgenerator\x18\x03 \x01(\tH\x01R\tgenerator\x88\x01\x01\x12\x17\n" +
+	"\x04name\x18\x04 \x01(\tH\x02R\x04name\x88\x01\x01\x12!\n" +
+	"\tnamespace\x18\x05 \x01(\tH\x03R\tnamespace\x88\x01\x01\x12\x1d\n" +
+	"\aproject\x18\x06 \x01(\tH\x04R\aproject\x88\x01\x01\x129\n" +
+	"\n" +
+	"helm_chart\x18\a \x01(\v2\x15.apis.HelmChartOutputH\x05R\thelmChart\x88\x01\x01B\x12\n" +
+	"\x10_target_revisionB\f\n" +
+	"\n" +
+	"_generatorB\a\n" +
+	"\x05_nameB\f\n" +
+	"\n" +
+	"_namespaceB\n" +
+	"\n" +
+	"\b_projectB\r\n" +
+	"\v_helm_chart\"\xd3\x02\n" +
+	"\rK0rdentOutput\x124\n" +
+	"\n" +
+	"helm_chart\x18\x01 \x01(\v2\x15.apis.HelmChartOutputR\thelmChart\x12!\n" +
+	"\tnamespace\x18\x02 \x01(\tH\x00R\tnamespace\x88\x01\x01\x12#\n" +
+	"\n" +
+	"repository\x18\x03 \x01(\tH\x01R\n" +
+	"repository\x88\x01\x01\x12*\n" +
+	"\x0erepository_url\x18\x04 \x01(\tH\x02R\rrepositoryUrl\x88\x01\x01\x120\n" +
+	"\x11service_namespace\x18\x05 \x01(\tH\x03R\x10serviceNamespace\x88\x01\x01\x12\x17\n" +
+	"\x04mode\x18\x06 \x01(\tH\x04R\x04mode\x88\x01\x01B\f\n" +
+	"\n" +
+	"_namespaceB\r\n" +
+	"\v_repositoryB\x11\n" +
+	"\x0f_repository_urlB\x14\n" +
+	"\x12_service_namespaceB\a\n" +
+	"\x05_mode\"\\\n" +
+	"\x10DiffReportOutput\x12\x17\n" +
+	"\x04path\x18\x01 \x01(\tH\x00R\x04path\x88\x01\x01\x12\x1b\n" +
+	"\x06format\x18\x02 \x01(\tH\x01R\x06format\x88\x01\x01B\a\n" +
+	"\x05_pathB\t\n" +
+	"\a_format\"\xbd\x01\n" +
 	"\x12ColumnarFileOutput\x12\x17\n" +
 	"\x04path\x18\x01 \x01(\tH\x00R\x04path\x88\x01\x01\x12,\n" +
-	"\acolumns\x18\x02 \x03(\v2\x12.apis.ColumnOutputR\acolumnsB\a\n" +
-	"\x05_path\"\xa0\x01\n" +
+	"\acolumns\x18\x02 \x03(\v2\x12.apis.ColumnOutputR\acolumns\x12\x17\n" +
+	"\asort_by\x18\x03 \x03(\tR\x06sortBy\x12\x19\n" +
+	"\bgroup_by\x18\x04 \x03(\tR\agroupBy\x12\x19\n" +
+	"\x05pivot\x18\x05 \x01(\tH\x01R\x05pivot\x88\x01\x01B\a\n" +
+	"\x05_pathB\b\n" +
+	"\x06_pivot\"\xf3\x01\n" +
 	"\fColumnOutput\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
 	"\vdescription\x18\x02 \x01(\tH\x00R\vdescription\x88\x01\x01\x12\x19\n" +
 	"\x05field\x18\x03 \x01(\tH\x01R\x05field\x88\x01\x01\x12\x17\n" +
-	"\x04text\x18\x04 \x01(\tH\x02R\x04text\x88\x01\x01B\x0e\n" +
+	"\x04text\x18\x04 \x01(\tH\x02R\x04text\x88\x01\x01\x12\x17\n" +
+	"\x04expr\x18\x05 \x01(\tH\x03R\x04expr\x88\x01\x01\x12!\n" +
+	"\taggregate\x18\x06 \x01(\tH\x04R\taggregate\x88\x01\x01B\x0e\n" +
 	"\f_descriptionB\b\n" +
 	"\x06_fieldB\a\n" +
-	"\x05_text*'\n" +
+	"\x05_textB\a\n" +
+	"\x05_exprB\f\n" +
+	"\n" +
+	"_aggregate*'\n" +
 	"\x0eDefaultsFilter\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\b\n" +
 	"\x04NONE\x10\x012H\n" +
@@ -1495,72 +2451,94 @@ func file_run_proto_rawDescGZIP() []byte {
 }
 
 var file_run_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_run_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_run_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_run_proto_goTypes = []any{
 	(DefaultsFilter)(0),               // 0: apis.DefaultsFilter
 	(*Pipeline)(nil),                  // 1: apis.Pipeline
-	(*Args)(nil),                      // 2: apis.Args
-	(*Source)(nil),                    // 3: apis.Source
-	(*KubeConfigSource)(nil),          // 4: apis.KubeConfigSource
-	(*KustomizeSource)(nil),           // 5: apis.KustomizeSource
-	(*ClusterSelector)(nil),           // 6: apis.ClusterSelector
-	(*ResourceMatcher)(nil),           // 7: apis.ResourceMatcher
-	(*PatternSelector)(nil),           // 8: apis.PatternSelector
-	(*Filter)(nil),                    // 9: apis.Filter
-	(*StarlarkFilter)(nil),            // 10: apis.StarlarkFilter
-	(*SkipFilter)(nil),                // 11: apis.SkipFilter
-	(*ResourceSelector)(nil),          // 12: apis.ResourceSelector
-	(*Output)(nil),                    // 13: apis.Output
-	(*KubectlOutput)(nil),             // 14: apis.KubectlOutput
-	(*KustomizeOutput)(nil),           // 15: apis.KustomizeOutput
-	(*KustomizeComponentsOutput)(nil), // 16: apis.KustomizeComponentsOutput
-	(*HelmChartOutput)(nil),           // 17: apis.HelmChartOutput
-	(*CRDDescriptionsOutput)(nil),     // 18: apis.CRDDescriptionsOutput
-	(*JSONOutput)(nil),                // 19: apis.JSONOutput
-	(*ColumnarFileOutput)(nil),        // 20: apis.ColumnarFileOutput
-	(*ColumnOutput)(nil),              // 21: apis.ColumnOutput
-	nil,                               // 22: apis.HelmChartOutput.ValuesAliasesEntry
-	(*structpb.Struct)(nil),           // 23: google.protobuf.Struct
-	(*emptypb.Empty)(nil),             // 24: google.protobuf.Empty
+	(*Drift)(nil),                     // 2: apis.Drift
+	(*Args)(nil),                      // 3: apis.Args
+	(*Source)(nil),                    // 4: apis.Source
+	(*KubeConfigSource)(nil),          // 5: apis.KubeConfigSource
+	(*KustomizeSource)(nil),           // 6: apis.KustomizeSource
+	(*ClusterSelector)(nil),           // 7: apis.ClusterSelector
+	(*ResourceMatcher)(nil),           // 8: apis.ResourceMatcher
+	(*PatternSelector)(nil),           // 9: apis.PatternSelector
+	(*Filter)(nil),                    // 10: apis.Filter
+	(*FleetFilter)(nil),               // 11: apis.FleetFilter
+	(*StarlarkFilter)(nil),            // 12: apis.StarlarkFilter
+	(*StarlarkLimits)(nil),            // 13: apis.StarlarkLimits
+	(*SkipFilter)(nil),                // 14: apis.SkipFilter
+	(*ResourceSelector)(nil),          // 15: apis.ResourceSelector
+	(*Output)(nil),                    // 16: apis.Output
+	(*KubectlOutput)(nil),             // 17: apis.KubectlOutput
+	(*KustomizeOutput)(nil),           // 18: apis.KustomizeOutput
+	(*KustomizeComponentsOutput)(nil), // 19: apis.KustomizeComponentsOutput
+	(*HelmChartOutput)(nil),           // 20: apis.HelmChartOutput
+	(*CRDDescriptionsOutput)(nil),     // 21: apis.CRDDescriptionsOutput
+	(*JSONOutput)(nil),                // 22: apis.JSONOutput
+	(*FluxOutput)(nil),                // 23: apis.FluxOutput
+	(*ArgoCDOutput)(nil),              // 24: apis.ArgoCDOutput
+	(*K0RdentOutput)(nil),             // 25: apis.K0rdentOutput
+	(*DiffReportOutput)(nil),          // 26: apis.DiffReportOutput
+	(*ColumnarFileOutput)(nil),        // 27: apis.ColumnarFileOutput
+	(*ColumnOutput)(nil),              // 28: apis.ColumnOutput
+	nil,                               // 29: apis.Drift.ClusterNamesEntry
+	nil,                               // 30: apis.HelmChartOutput.ValuesAliasesEntry
+	(*structpb.Struct)(nil),           // 31: google.protobuf.Struct
+	(*emptypb.Empty)(nil),             // 32: google.protobuf.Empty
 }
 var file_run_proto_depIdxs = []int32{
-	3,  // 0: apis.Pipeline.source:type_name -> apis.Source
-	9,  // 1: apis.Pipeline.filters:type_name -> apis.Filter
-	13, // 2: apis.Pipeline.output:type_name -> apis.Output
-	2,  // 3: apis.Pipeline.args:type_name -> apis.Args
-	23, // 4: apis.Args.schema:type_name -> google.protobuf.Struct
-	4,  // 5: apis.Source.kubeconfig:type_name -> apis.KubeConfigSource
-	5,  // 6: apis.Source.kustomize:type_name -> apis.KustomizeSource
-	6,  // 7: apis.KubeConfigSource.clusters:type_name -> apis.ClusterSelector
-	7,  // 8: apis.KubeConfigSource.resources:type_name -> apis.ResourceMatcher
-	6,  // 9: apis.KustomizeSource.clusters:type_name -> apis.ClusterSelector
-	8,  // 10: apis.ClusterSelector.match_names:type_name -> apis.PatternSelector
-	8,  // 11: apis.ResourceMatcher.match_names:type_name -> apis.PatternSelector
-	8,  // 12: apis.ResourceMatcher.match_namespaces:type_name -> apis.PatternSelector
-	8,  // 13: apis.ResourceMatcher.match_api_resources:type_name -> apis.PatternSelector
-	11, // 14: apis.Filter.skip:type_name -> apis.SkipFilter
-	10, // 15: apis.Filter.starlark:type_name -> apis.StarlarkFilter
-	0,  // 16: apis.Filter.defaults:type_name -> apis.DefaultsFilter
-	12, // 17: apis.SkipFilter.resources:type_name -> apis.ResourceSelector
-	12, // 18: apis.SkipFilter.keep_resources:type_name -> apis.ResourceSelector
-	15, // 19: apis.Output.kustomize:type_name -> apis.KustomizeOutput
-	16, // 20: apis.Output.kustomize_components:type_name -> apis.KustomizeComponentsOutput
-	17, // 21: apis.Output.helm_chart:type_name -> apis.HelmChartOutput
-	20, // 22: apis.Output.csv:type_name -> apis.ColumnarFileOutput
-	20, // 23: apis.Output.table:type_name -> apis.ColumnarFileOutput
-	18, // 24: apis.Output.crd_descriptions:type_name -> apis.CRDDescriptionsOutput
-	14, // 25: apis.Output.kubectl:type_name -> apis.KubectlOutput
-	19, // 26: apis.Output.json:type_name -> apis.JSONOutput
-	22, // 27: apis.HelmChartOutput.values_aliases:type_name -> apis.HelmChartOutput.ValuesAliasesEntry
-	23, // 28: apis.JSONOutput.schema:type_name -> google.protobuf.Struct
-	21, // 29: apis.ColumnarFileOutput.columns:type_name -> apis.ColumnOutput
-	24, // 30: apis.KTL.Config:input_type -> google.protobuf.Empty
-	1,  // 31: apis.KTL.Config:output_type -> apis.Pipeline
-	31, // [31:32] is the sub-list for method output_type
-	30, // [30:31] is the sub-list for method input_type
-	30, // [30:30] is the sub-list for extension type_name
-	30, // [30:30] is the sub-list for extension extendee
-	0,  // [0:30] is the sub-list for field type_name
+	4,  // 0: apis.Pipeline.source:type_name -> apis.Source
+	10, // 1: apis.Pipeline.filters:type_name -> apis.Filter
+	16, // 2: apis.Pipeline.output:type_name -> apis.Output
+	3,  // 3: apis.Pipeline.args:type_name -> apis.Args
+	11, // 4: apis.Pipeline.fleet_filters:type_name -> apis.FleetFilter
+	2,  // 5: apis.Pipeline.drift:type_name -> apis.Drift
+	4,  // 6: apis.Drift.live:type_name -> apis.Source
+	29, // 7: apis.Drift.cluster_names:type_name -> apis.Drift.ClusterNamesEntry
+	31, // 8: apis.Args.schema:type_name -> google.protobuf.Struct
+	5,  // 9: apis.Source.kubeconfig:type_name -> apis.KubeConfigSource
+	6,  // 10: apis.Source.kustomize:type_name -> apis.KustomizeSource
+	7,  // 11: apis.KubeConfigSource.clusters:type_name -> apis.ClusterSelector
+	8,  // 12: apis.KubeConfigSource.resources:type_name -> apis.ResourceMatcher
+	7,  // 13: apis.KustomizeSource.clusters:type_name -> apis.ClusterSelector
+	9,  // 14: apis.ClusterSelector.match_names:type_name -> apis.PatternSelector
+	9,  // 15: apis.ResourceMatcher.match_names:type_name -> apis.PatternSelector
+	9,  // 16: apis.ResourceMatcher.match_namespaces:type_name -> apis.PatternSelector
+	9,  // 17: apis.ResourceMatcher.match_api_resources:type_name -> apis.PatternSelector
+	14, // 18: apis.Filter.skip:type_name -> apis.SkipFilter
+	12, // 19: apis.Filter.starlark:type_name -> apis.StarlarkFilter
+	0,  // 20: apis.Filter.defaults:type_name -> apis.DefaultsFilter
+	12, // 21: apis.FleetFilter.starlark:type_name -> apis.StarlarkFilter
+	13, // 22: apis.StarlarkFilter.limits:type_name -> apis.StarlarkLimits
+	15, // 23: apis.SkipFilter.resources:type_name -> apis.ResourceSelector
+	15, // 24: apis.SkipFilter.keep_resources:type_name -> apis.ResourceSelector
+	9,  // 25: apis.SkipFilter.match_cluster_tags:type_name -> apis.PatternSelector
+	18, // 26: apis.Output.kustomize:type_name -> apis.KustomizeOutput
+	19, // 27: apis.Output.kustomize_components:type_name -> apis.KustomizeComponentsOutput
+	20, // 28: apis.Output.helm_chart:type_name -> apis.HelmChartOutput
+	27, // 29: apis.Output.csv:type_name -> apis.ColumnarFileOutput
+	27, // 30: apis.Output.table:type_name -> apis.ColumnarFileOutput
+	21, // 31: apis.Output.crd_descriptions:type_name -> apis.CRDDescriptionsOutput
+	17, // 32: apis.Output.kubectl:type_name -> apis.KubectlOutput
+	22, // 33: apis.Output.json:type_name -> apis.JSONOutput
+	26, // 34: apis.Output.diff_report:type_name -> apis.DiffReportOutput
+	23, // 35: apis.Output.flux:type_name -> apis.FluxOutput
+	24, // 36: apis.Output.argocd:type_name -> apis.ArgoCDOutput
+	25, // 37: apis.Output.k0rdent:type_name -> apis.K0rdentOutput
+	30, // 38: apis.HelmChartOutput.values_aliases:type_name -> apis.HelmChartOutput.ValuesAliasesEntry
+	23, // 39: apis.HelmChartOutput.flux:type_name -> apis.FluxOutput
+	31, // 40: apis.JSONOutput.schema:type_name -> google.protobuf.Struct
+	20, // 41: apis.ArgoCDOutput.helm_chart:type_name -> apis.HelmChartOutput
+	20, // 42: apis.K0rdentOutput.helm_chart:type_name -> apis.HelmChartOutput
+	28, // 43: apis.ColumnarFileOutput.columns:type_name -> apis.ColumnOutput
+	32, // 44: apis.KTL.Config:input_type -> google.protobuf.Empty
+	1,  // 45: apis.KTL.Config:output_type -> apis.Pipeline
+	45, // [45:46] is the sub-list for method output_type
+	44, // [44:45] is the sub-list for method input_type
+	44, // [44:44] is the sub-list for extension type_name
+	44, // [44:44] is the sub-list for extension extendee
+	0,  // [0:44] is the sub-list for field type_name
 }
 
 func init() { file_run_proto_init() }
@@ -1572,24 +2550,34 @@ func file_run_proto_init() {
 	file_run_proto_msgTypes[1].OneofWrappers = []any{}
 	file_run_proto_msgTypes[2].OneofWrappers = []any{}
 	file_run_proto_msgTypes[3].OneofWrappers = []any{}
-	file_run_proto_msgTypes[5].OneofWrappers = []any{}
+	file_run_proto_msgTypes[4].OneofWrappers = []any{}
 	file_run_proto_msgTypes[6].OneofWrappers = []any{}
-	file_run_proto_msgTypes[8].OneofWrappers = []any{}
+	file_run_proto_msgTypes[7].OneofWrappers = []any{}
+	file_run_proto_msgTypes[9].OneofWrappers = []any{}
+	file_run_proto_msgTypes[10].OneofWrappers = []any{}
 	file_run_proto_msgTypes[11].OneofWrappers = []any{}
 	file_run_proto_msgTypes[12].OneofWrappers = []any{}
 	file_run_proto_msgTypes[13].OneofWrappers = []any{}
+	file_run_proto_msgTypes[14].OneofWrappers = []any{}
+	file_run_proto_msgTypes[15].OneofWrappers = []any{}
 	file_run_proto_msgTypes[16].OneofWrappers = []any{}
-	file_run_proto_msgTypes[17].OneofWrappers = []any{}
 	file_run_proto_msgTypes[18].OneofWrappers = []any{}
 	file_run_proto_msgTypes[19].OneofWrappers = []any{}
 	file_run_proto_msgTypes[20].OneofWrappers = []any{}
+	file_run_proto_msgTypes[21].OneofWrappers = []any{}
+	file_run_proto_msgTypes[22].OneofWrappers = []any{}
+	file_run_proto_msgTypes[23].OneofWrappers = []any{}
+	file_run_proto_msgTypes[24].OneofWrappers = []any{}
+	file_run_proto_msgTypes[25].OneofWrappers = []any{}
+	file_run_proto_msgTypes[26].OneofWrappers = []any{}
+	file_run_proto_msgTypes[27].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_run_proto_rawDesc), len(file_run_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   22,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
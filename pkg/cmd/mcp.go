@@ -5,21 +5,31 @@ import (
 	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 
 	"github.com/Mirantis/ktl/pkg/apis"
 	"github.com/Mirantis/ktl/pkg/fsutil"
 	"github.com/Mirantis/ktl/pkg/kubectl"
+	"github.com/Mirantis/ktl/pkg/output"
 	"github.com/Mirantis/ktl/pkg/runner"
 	"github.com/Mirantis/ktl/pkg/types"
 	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+const (
+	mcpMaxSteps       = 100_000_000
+	mcpTimeout        = "1m"
+	mcpMaxOutput      = 100_000
+	mcpMaxOutputNodes = 10_000_000
+)
+
 func newMCPCommand() *cobra.Command {
 	//TODO: add e2e tests
 
@@ -47,6 +57,8 @@ func newMCPCommand() *cobra.Command {
 					return fmt.Errorf("missing name for tool %s", toolPath)
 				}
 
+				applyDefaultLimits(toolSpec, mcpDefaultLimits())
+
 				tool := &mcp.Tool{
 					Name:        toolSpec.Name,
 					Description: toolSpec.GetDescription(),
@@ -101,6 +113,41 @@ func newMCPCommand() *cobra.Command {
 	return mcpCmd
 }
 
+// mcpDefaultLimits are the execution limits of the tool scripts, as the tool
+// arguments come from the MCP clients.
+func mcpDefaultLimits() *apis.StarlarkLimits {
+	return &apis.StarlarkLimits{
+		MaxSteps:       proto.Uint64(mcpMaxSteps),
+		Timeout:        proto.String(mcpTimeout),
+		MaxOutput:      proto.Uint32(mcpMaxOutput),
+		MaxOutputNodes: proto.Uint32(mcpMaxOutputNodes),
+	}
+}
+
+// applyDefaultLimits sets the limits not set by the Starlark filters of the
+// pipeline, e.g. maxSteps: 0 keeps the steps unlimited.
+func applyDefaultLimits(spec *apis.Pipeline, defaults *apis.StarlarkLimits) {
+	filters := []*apis.StarlarkFilter{}
+
+	for _, filter := range spec.GetFilters() {
+		filters = append(filters, filter.GetStarlark())
+	}
+
+	for _, filter := range spec.GetFleetFilters() {
+		filters = append(filters, filter.GetStarlark())
+	}
+
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+
+		limits := proto.CloneOf(defaults)
+		proto.Merge(limits, filter.GetLimits())
+		filter.Limits = limits
+	}
+}
+
 func newMCPHandler[In any](workdir string, spec *apis.Pipeline) mcp.ToolHandlerFor[In, map[string]any] {
 	return func(ctx context.Context, ss *mcp.ServerSession, ctpf *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[map[string]any], error) {
 		result := &mcp.CallToolResultFor[map[string]any]{
@@ -131,7 +178,8 @@ func newMCPHandler[In any](workdir string, spec *apis.Pipeline) mcp.ToolHandlerF
 			return nil, err
 		}
 
-		if err := pipeline.Run(env); err != nil {
+		// the drift report is the result
+		if err := pipeline.RunContext(ctx, env); err != nil && !errors.Is(err, output.ErrDrift) {
 			return nil, err
 		}
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/Mirantis/ktl/pkg/filters"
@@ -26,6 +27,10 @@ func newQueryCommand() *cobra.Command {
 	columns := []string{}
 	extraColumns := []string{}
 	format := ""
+	sortBy := []string{}
+	groupBy := []string{}
+	pivot := ""
+	aggregates := map[string]string{}
 
 	export := &cobra.Command{
 		Use:   "query RESOURCES [FILTER]",
@@ -69,7 +74,11 @@ func newQueryCommand() *cobra.Command {
 				return err
 			}
 
-			csvOut := output.CSVOutput{}
+			csvOut := output.CSVOutput{
+				SortBy:  sortBy,
+				GroupBy: groupBy,
+				Pivot:   pivot,
+			}
 
 			pipeline := &runner.Pipeline{
 				Source: runner.Source{
@@ -96,12 +105,27 @@ func newQueryCommand() *cobra.Command {
 				}
 			}
 
-			for _, pairs := range extraColumns {
+			for _, pairs := range splitColumns(extraColumns) {
 				parts := strings.SplitN(pairs, ":", 2)
 				if len(parts) < 2 {
 					return fmt.Errorf("%s is not a valid column definition", pairs)
 				}
 
+				// expressions are marked with the equal sign, e.g. NAME:=it.kind
+				if exprText, isExpr := strings.CutPrefix(parts[1], "="); isExpr {
+					expr, err := filters.NewExpr(exprText)
+					if err != nil {
+						return err
+					}
+
+					csvOut.Columns = append(csvOut.Columns, output.ValueRef{
+						Name: parts[0],
+						Expr: expr,
+					})
+
+					continue
+				}
+
 				qYNode := yaml.NewStringRNode(parts[1]).YNode()
 				q := resource.Query{}
 
@@ -115,6 +139,19 @@ func newQueryCommand() *cobra.Command {
 				})
 			}
 
+			for name, fn := range aggregates {
+				colIdx := slices.IndexFunc(csvOut.Columns, func(col output.ValueRef) bool { return col.Name == name })
+				if colIdx < 0 {
+					return fmt.Errorf("%s is not a valid aggregate column", name)
+				}
+
+				if err := output.ValidateAggregate(fn); err != nil {
+					return err
+				}
+
+				csvOut.Columns[colIdx].Aggregate = fn
+			}
+
 			if format == "table" {
 				pipeline.Output = runner.Output{
 					Impl: &output.TableOutput{CSVOutput: csvOut},
@@ -145,8 +182,44 @@ func newQueryCommand() *cobra.Command {
 	export.Flags().StringVar(&format, "format", "table", "format, one of: table,csv (default: table)")
 	export.Flags().StringVar(&clusters, "clusters", "*", "clusters pattern")
 	export.Flags().StringSliceVarP(&columns, "columns", "c", []string{}, "columns, comma-separated <NAME>:<QUERY> pairs (default: CLUSTER, KIND, NAMESPACE, NAME)")
-	export.Flags().StringSliceVarP(&extraColumns, "extra-columns", "C", []string{}, "additional columns, comma-separated <NAME>:<QUERY> pairs")
+	export.Flags().StringArrayVarP(&extraColumns, "extra-columns", "C", []string{}, "additional columns, comma-separated <NAME>:<QUERY> or <NAME>:=<EXPR> pairs, e.g. 'READY:=it.status.readyReplicas'")
+	export.Flags().StringSliceVar(&sortBy, "sort-by", []string{}, "columns sorting the rows, prefixed with - for the descending order")
+	export.Flags().StringSliceVar(&groupBy, "group-by", []string{}, "columns grouping the rows, the other columns are aggregated")
+	export.Flags().StringVar(&pivot, "pivot", "", "column listed per cluster, the clusters become the columns")
+	export.Flags().StringToStringVar(&aggregates, "aggregate", map[string]string{}, "aggregates of the grouped columns, comma-separated <NAME>=<FUNC> pairs, one of: count,sum,min,max,distinct (default: distinct)")
 	export.Flags().StringVarP(&namespaces, "namespaces", "n", "*", "namespaces pattern (default: all)")
 
 	return export
 }
+
+// splitColumns splits the comma-separated column definitions, keeping the
+// commas of the expressions within the brackets and the quotes.
+func splitColumns(values []string) []string {
+	columns := []string{}
+
+	for _, value := range values {
+		depth, quote, start := 0, rune(0), 0
+
+		for idx, char := range value {
+			switch {
+			case quote != 0:
+				if char == quote {
+					quote = 0
+				}
+			case char == '"' || char == '\'':
+				quote = char
+			case strings.ContainsRune("([{", char):
+				depth++
+			case strings.ContainsRune(")]}", char):
+				depth--
+			case char == ',' && depth == 0:
+				columns = append(columns, value[start:idx])
+				start = idx + 1
+			}
+		}
+
+		columns = append(columns, value[start:])
+	}
+
+	return columns
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQueryUnknownAggregate(t *testing.T) {
+	query := newQueryCommand()
+	query.SetArgs([]string{"deployments", "-C", "NAME:.metadata.name", "--aggregate", "NAME=summ"})
+	query.SetOut(bytes.NewBuffer(nil))
+	query.SetErr(bytes.NewBuffer(nil))
+
+	if err := query.Execute(); err == nil || !strings.Contains(err.Error(), "unknown aggregate: summ") {
+		t.Errorf("want unknown aggregate error, got %v", err)
+	}
+}
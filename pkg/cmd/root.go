@@ -27,6 +27,7 @@ func NewRootCommand() *cobra.Command {
 	root.AddCommand(newRunCommand())
 	root.AddCommand(newMCPCommand())
 	root.AddCommand(newQueryCommand())
+	root.AddCommand(newShellCommand())
 
 	return root
 }
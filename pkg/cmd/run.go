@@ -1,15 +1,11 @@
 package cmd
 
 import (
-	"os"
-	"path/filepath"
+	"errors"
 
-	"github.com/Mirantis/ktl/pkg/fsutil"
-	"github.com/Mirantis/ktl/pkg/kubectl"
+	"github.com/Mirantis/ktl/pkg/output"
 	"github.com/Mirantis/ktl/pkg/runner"
-	"github.com/Mirantis/ktl/pkg/types"
 	"github.com/spf13/cobra"
-	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 func newRunCommand() *cobra.Command {
@@ -18,24 +14,10 @@ func newRunCommand() *cobra.Command {
 		Short: "execute the pipeline",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error { //nolint:revive
-			fileName := args[0]
-			workDir := filepath.Dir(fileName)
-			if err := os.Chdir(workDir); err != nil {
+			env, fileName, err := pipelineEnv(cmd, args[0])
+			if err != nil {
 				return err
 			}
-			// FIXME: clenup
-			workDir = "."
-			fileName = filepath.Base(fileName)
-
-			fileSys := fsutil.Stdio(
-				fsutil.Sub(filesys.MakeFsOnDisk(), workDir),
-				cmd.InOrStdin(), cmd.OutOrStdout(),
-			)
-			env := &types.Env{
-				WorkDir: workDir,
-				FileSys: fileSys,
-				Cmd:     kubectl.New(),
-			}
 
 			pipelineSpec, err := loadPipelineSpec(fileName)
 			if err != nil {
@@ -47,7 +29,12 @@ func newRunCommand() *cobra.Command {
 				return err
 			}
 
-			return pipeline.Run(env)
+			err = pipeline.Run(env)
+			if errors.Is(err, output.ErrDrift) {
+				cmd.SilenceUsage = true
+			}
+
+			return err
 		},
 	}
 
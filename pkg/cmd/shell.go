@@ -0,0 +1,466 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/filters"
+	"github.com/Mirantis/ktl/pkg/kstar"
+	"github.com/Mirantis/ktl/pkg/source"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	shellPrompt         = ">>> "
+	shellContinuePrompt = "... "
+	shellCompletionStep = 10000
+)
+
+var (
+	errShellNoClusters = errors.New("no clusters loaded")
+	errShellArgs       = errors.New("args must be a map")
+	errShellPanic      = errors.New("internal error")
+)
+
+//nolint:gochecknoglobals
+var (
+	shellFileOptions = &syntax.FileOptions{
+		TopLevelControl:   true,
+		GlobalReassign:    true,
+		LoadBindsGlobally: true,
+	}
+
+	// completionExpr matches the trailing attribute access without calls,
+	// e.g. resources[0].metadata.na
+	completionExpr = regexp.MustCompile(`[A-Za-z_]\w*(?:\.[A-Za-z_]\w*|\[[^\[\]()]*\])*\.?$`)
+)
+
+func newShellCommand() *cobra.Command {
+	clusterName := ""
+	argsText := ""
+
+	shellCmd := &cobra.Command{
+		Use:   "shell FILENAME",
+		Short: "run Starlark shell over the pipeline source resources",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			env, fileName, err := pipelineEnv(cmd, args[0])
+			if err != nil {
+				return err
+			}
+
+			pipelineSpec, err := loadPipelineSpec(fileName)
+			if err != nil {
+				return err
+			}
+
+			pipelineArgs, err := parseShellArgs(argsText)
+			if err != nil {
+				return err
+			}
+
+			src, err := source.New(pipelineSpec.GetSource())
+			if err != nil {
+				return err
+			}
+
+			state, err := src.Load(env)
+			if err != nil {
+				return err
+			}
+
+			session, err := newShellSession(
+				env, state, shellFilterSpec(pipelineSpec), pipelineArgs,
+				cmd.OutOrStdout(), cmd.ErrOrStderr(),
+			)
+			if err != nil {
+				return err
+			}
+
+			if clusterName == "" {
+				clusterName = state.Clusters.Cluster(state.Clusters.IDs()[0]).Name
+			}
+
+			if err := session.use(clusterName); err != nil {
+				return err
+			}
+
+			return session.run(cmd.InOrStdin())
+		},
+	}
+
+	shellCmd.Flags().StringVarP(&clusterName, "cluster", "c", "", "cluster to start with (default: first)")
+	shellCmd.Flags().StringVarP(&argsText, "args", "a", "", "pipeline args as YAML or JSON map")
+
+	return shellCmd
+}
+
+// shellFilterSpec returns the Starlark filter of the shell, registering the
+// CRD files of the pipeline filters and checking the assignments if any of
+// them is strict. The scripts and their limits are not used.
+func shellFilterSpec(pipeline *apis.Pipeline) *apis.StarlarkFilter {
+	spec := &apis.StarlarkFilter{}
+	filters := []*apis.StarlarkFilter{}
+
+	for _, filter := range pipeline.GetFilters() {
+		filters = append(filters, filter.GetStarlark())
+	}
+
+	for _, filter := range pipeline.GetFleetFilters() {
+		filters = append(filters, filter.GetStarlark())
+	}
+
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+
+		for _, path := range filter.GetCrdFiles() {
+			if !slices.Contains(spec.CrdFiles, path) {
+				spec.CrdFiles = append(spec.CrdFiles, path)
+			}
+		}
+
+		if filter.GetStrict() {
+			spec.Strict = proto.Bool(true)
+		}
+	}
+
+	return spec
+}
+
+func parseShellArgs(text string) (*yaml.RNode, error) {
+	if strings.TrimSpace(text) == "" {
+		return yaml.NewMapRNode(nil), nil
+	}
+
+	args, err := yaml.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid args: %w", err)
+	}
+
+	if args.YNode().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: %s", errShellArgs, strings.TrimSpace(text))
+	}
+
+	return args, nil
+}
+
+// shellSession holds the globals of the shell, the source resources are
+// loaded once and switched per cluster via use(NAME).
+type shellSession struct {
+	filter  *filters.StarlarkFilter
+	scope   *filters.Scope
+	state   *source.State
+	globals starlark.StringDict
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+//nolint:lll
+func newShellSession(env *types.Env, state *source.State, spec *apis.StarlarkFilter, args *yaml.RNode, stdout, stderr io.Writer) (*shellSession, error) {
+	if len(state.Clusters.IDs()) == 0 {
+		return nil, errShellNoClusters
+	}
+
+	kfilter, err := filters.New(&apis.Filter{Starlark: spec}, args)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, _ := kfilter.Filter.(*filters.StarlarkFilter)
+	scope := filters.NewScope(env)
+	filter.SetScope(scope)
+
+	clusters := starlark.Tuple{}
+	for name := range state.Clusters.Names(state.Clusters.IDs()...) {
+		clusters = append(clusters, starlark.String(name))
+	}
+
+	session := &shellSession{
+		filter: filter,
+		scope:  scope,
+		state:  state,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	session.globals = starlark.StringDict{
+		"clusters": clusters,
+		"use":      starlark.NewBuiltin("use", session.useBuiltin),
+	}
+
+	return session, nil
+}
+
+// use replaces the predeclared values with the ones of the cluster, keeping
+// the variables defined in the shell.
+func (session *shellSession) use(name string) error {
+	clusterID, err := session.state.Clusters.ID(name)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	cluster := session.state.Clusters.Cluster(clusterID)
+	session.scope.Cluster = &cluster
+
+	resources := session.state.Resources[clusterID]
+
+	predeclared, err := session.filter.Predeclared(resources)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	maps.Copy(session.globals, predeclared)
+	fmt.Fprintf(session.stdout, "cluster %s: %d resources\n", name, len(resources))
+
+	return nil
+}
+
+func (session *shellSession) useBuiltin(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &name); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if err := session.use(name); err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	return starlark.None, nil
+}
+
+func (session *shellSession) run(stdin io.Reader) error {
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:       shellPrompt,
+		AutoComplete: session,
+		Stdin:        io.NopCloser(stdin),
+		Stdout:       session.stdout,
+		Stderr:       session.stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to start shell: %w", err)
+	}
+	defer rl.Close()
+
+	for {
+		err := session.rep(rl)
+
+		switch {
+		case errors.Is(err, readline.ErrInterrupt):
+			fmt.Fprintln(session.stderr, err)
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// rep reads, evaluates and prints a single statement, the evaluation is
+// cancelled on interrupt.
+func (session *shellSession) rep(rl *readline.Instance) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	thread := session.filter.NewThread("shell")
+	thread.Print = func(_ *starlark.Thread, msg string) {
+		fmt.Fprintln(session.stdout, msg)
+	}
+
+	defer context.AfterFunc(ctx, func() { thread.Cancel("interrupted") })()
+
+	eof := false
+	rl.SetPrompt(shellPrompt)
+
+	readLine := func() ([]byte, error) {
+		line, err := rl.Readline()
+		rl.SetPrompt(shellContinuePrompt)
+
+		if errors.Is(err, io.EOF) {
+			eof = true
+		}
+
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		return []byte(line + "\n"), nil
+	}
+
+	file, err := shellFileOptions.ParseCompoundStmt("<stdin>", readLine)
+	if err != nil {
+		if eof {
+			return io.EOF
+		}
+
+		if errors.Is(err, readline.ErrInterrupt) {
+			return err //nolint:wrapcheck
+		}
+
+		session.printError(err)
+
+		return nil
+	}
+
+	// the values not supported by kstar yet panic, e.g. the YAML aliases
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic("shell statement failed", r)
+			session.printError(fmt.Errorf("%w: %v", errShellPanic, r))
+		}
+	}()
+
+	if expr := soleExpr(file); expr != nil {
+		value, err := starlark.EvalExprOptions(file.Options, thread, expr, session.globals)
+		if err != nil {
+			session.printError(err)
+
+			return nil
+		}
+
+		session.globals["_"] = value
+
+		if value != starlark.None {
+			fmt.Fprintln(session.stdout, formatValue(value))
+		}
+
+		return nil
+	}
+
+	if err := starlark.ExecREPLChunk(file, thread, session.globals); err != nil {
+		session.printError(err)
+	}
+
+	return nil
+}
+
+func (session *shellSession) printError(err error) {
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		fmt.Fprintln(session.stderr, evalErr.Backtrace())
+
+		return
+	}
+
+	fmt.Fprintln(session.stderr, err)
+}
+
+// Do implements readline.AutoCompleter, completing the global names and the
+// attributes of the values, including the schema fields not set yet.
+func (session *shellSession) Do(line []rune, pos int) (newLine [][]rune, length int) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic("shell completion failed", r)
+
+			newLine, length = nil, 0
+		}
+	}()
+
+	expr := completionExpr.FindString(string(line[:pos]))
+	prefix := expr
+	names := slices.Concat(
+		slices.Collect(maps.Keys(session.globals)),
+		slices.Collect(maps.Keys(starlark.Universe)),
+	)
+
+	if dot := strings.LastIndex(expr, "."); dot >= 0 {
+		if strings.Contains(expr[dot:], "]") {
+			return nil, 0
+		}
+
+		thread := &starlark.Thread{Name: "completion"}
+		thread.SetMaxExecutionSteps(shellCompletionStep)
+
+		value, err := starlark.EvalOptions(shellFileOptions, thread, "<completion>", expr[:dot], session.globals)
+		if err != nil {
+			return nil, 0
+		}
+
+		prefix = expr[dot+1:]
+		names = kstar.Completions(value)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range slices.Compact(names) {
+		if strings.HasPrefix(name, prefix) && !strings.HasPrefix(name, "_") {
+			newLine = append(newLine, []rune(name[len(prefix):]))
+		}
+	}
+
+	return newLine, len([]rune(prefix))
+}
+
+// logPanic logs the panic recovered by the shell with the stack, shown with
+// the debug logging. Only the panics of the values not supported by kstar
+// yet, with kstar.ErrNotImplemented, are recovered, the rest are the bugs
+// and are panicked again.
+func logPanic(msg string, recovered any) {
+	if err, isErr := recovered.(error); !isErr || !errors.Is(err, kstar.ErrNotImplemented) {
+		panic(recovered)
+	}
+
+	slog.Info(msg, "panic", recovered, "stack", string(debug.Stack()))
+}
+
+func soleExpr(file *syntax.File) syntax.Expr {
+	if len(file.Stmts) != 1 {
+		return nil
+	}
+
+	if stmt, ok := file.Stmts[0].(*syntax.ExprStmt); ok {
+		return stmt.X
+	}
+
+	return nil
+}
+
+// formatValue prints the nodes, and the lists and dicts containing them, as
+// YAML, the values without the string form are printed as their type.
+func formatValue(value starlark.Value) (text string) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic("shell formatting failed", r)
+
+			text = "<" + value.Type() + ">"
+		}
+	}()
+
+	switch value := value.(type) {
+	case *kstar.ScalarNode:
+		scalar, err := value.Value()
+		if err == nil {
+			return scalar.String()
+		}
+	case *kstar.MappingNode, *kstar.SequenceNode, *starlark.List, *starlark.Dict, starlark.Tuple:
+		ynode, err := kstar.FromStarlark(value)
+		if err != nil {
+			break
+		}
+
+		text, err := yaml.String(ynode)
+		if err == nil {
+			return strings.TrimSuffix(text, "\n")
+		}
+	}
+
+	return value.String()
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/kstar"
+	"github.com/Mirantis/ktl/pkg/source"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func newShellTestSession(
+	t *testing.T, spec *apis.StarlarkFilter, args string,
+) (*shellSession, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	clusters := types.BuildClusterIndex([]string{"dev-a", "prod-a"}, []types.ClusterSelector{
+		{Names: types.PatternSelector{Include: []string{"*"}}},
+	})
+	deployment := func(name string) *yaml.RNode {
+		return yaml.MustParse("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: " + name)
+	}
+	state := &source.State{
+		Clusters: clusters,
+		Resources: map[types.ClusterID][]*yaml.RNode{
+			0: {deployment("web-dev")},
+			1: {deployment("web-prod"), deployment("api-prod")},
+		},
+	}
+
+	pipelineArgs, err := parseShellArgs(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	env := &types.Env{FileSys: filesys.MakeFsInMemory()}
+
+	session, err := newShellSession(env, state, spec, pipelineArgs, stdout, stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := session.use("dev-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	return session, stdout, stderr
+}
+
+func TestShellSession(t *testing.T) {
+	session, stdout, stderr := newShellTestSession(t, &apis.StarlarkFilter{}, `{"team": "web"}`)
+
+	input := strings.Join([]string{
+		`name = resources[0].metadata.name`,
+		`cluster.name`,
+		`use("prod-a")`,
+		`cluster.name`,
+		`[it.metadata.name for it in resources]`,
+		`name`,
+		`args.team`,
+		`regex("^web-", [it.metadata.name for it in resources])`,
+		`sorted(clusters)`,
+	}, "\n") + "\n"
+
+	if err := session.run(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"cluster dev-a: 1 resources",
+		`"dev-a"`,
+		"cluster prod-a: 2 resources",
+		`"prod-a"`,
+		"- web-prod\n- api-prod",
+		`"web-dev"`,
+		`"web"`,
+		"- web-prod",
+		"- dev-a\n- prod-a",
+	}
+	if diff := cmp.Diff(strings.Join(want, "\n")+"\n", stdout.String()); diff != "" {
+		t.Errorf("stdout mismatch, -want +got:\n%s", diff)
+	}
+
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected stderr:\n%s", stderr)
+	}
+}
+
+func TestShellSessionErrors(t *testing.T) {
+	session, stdout, stderr := newShellTestSession(t, &apis.StarlarkFilter{Strict: proto.Bool(true)}, "")
+
+	input := strings.Join([]string{
+		`use("missing")`,
+		`undefined`,
+		`resources[0].spec = {"replica": 3}`,
+		`if True`,
+		`{resources[0]: 1}`,
+		`resources[0][resources[0]]`,
+		`cluster.name`,
+	}, "\n") + "\n"
+
+	if err := session.run(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff("cluster dev-a: 1 resources\n\"dev-a\"\n", stdout.String()); diff != "" {
+		t.Errorf("stdout mismatch, -want +got:\n%s", diff)
+	}
+
+	for _, want := range []string{
+		"use: cluster not found: missing",
+		"undefined: undefined",
+		"replica: unknown field of io.k8s.api.apps.v1.DeploymentSpec",
+		"got newline, want ':'",
+		"unhashable type: MappingNode",
+		"not implemented: mapping keys",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("want %q in stderr, got:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	if _, err := parseShellArgs("[1, 2]"); err == nil {
+		t.Error("want error for list args, got none")
+	}
+
+	spec := shellFilterSpec(&apis.Pipeline{
+		Filters: []*apis.Filter{
+			{Starlark: &apis.StarlarkFilter{Script: "pass", CrdFiles: []string{"crds/a.yaml"}}},
+			{Starlark: &apis.StarlarkFilter{CrdFiles: []string{"crds/a.yaml", "crds/b.yaml"}, Strict: proto.Bool(true)}},
+		},
+	})
+
+	want := &apis.StarlarkFilter{CrdFiles: []string{"crds/a.yaml", "crds/b.yaml"}, Strict: proto.Bool(true)}
+	if !proto.Equal(want, spec) {
+		t.Errorf("want %v, got %v", want, spec)
+	}
+}
+
+func TestShellLogPanic(t *testing.T) {
+	recovered := func(value any) (result any) {
+		defer func() { result = recover() }()
+
+		logPanic("test", value)
+
+		return nil
+	}
+
+	if got := recovered(fmt.Errorf("%w: test", kstar.ErrNotImplemented)); got != nil {
+		t.Errorf("want not implemented panic recovered, got %v", got)
+	}
+
+	if got := recovered("nil dereference"); got != "nil dereference" {
+		t.Errorf("want other panics panicked again, got %v", got)
+	}
+}
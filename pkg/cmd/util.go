@@ -3,9 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/kubectl"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/spf13/cobra"
 	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/yaml"
 )
 
@@ -28,3 +34,27 @@ func loadPipelineSpec(path string) (*apis.Pipeline, error) {
 
 	return pipelineSpec, nil
 }
+
+// pipelineEnv changes the working directory to the pipeline directory, so
+// the paths of the pipeline are relative to it, and returns the environment
+// with the pipeline file name.
+func pipelineEnv(cmd *cobra.Command, path string) (*types.Env, string, error) {
+	if err := os.Chdir(filepath.Dir(path)); err != nil {
+		return nil, "", err
+	}
+
+	// FIXME: clenup
+	workDir := "."
+
+	fileSys := fsutil.Stdio(
+		fsutil.Sub(filesys.MakeFsOnDisk(), workDir),
+		cmd.InOrStdin(), cmd.OutOrStdout(),
+	)
+	env := &types.Env{
+		WorkDir: workDir,
+		FileSys: fileSys,
+		Cmd:     kubectl.New(),
+	}
+
+	return env, filepath.Base(path), nil
+}
@@ -0,0 +1,110 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/Mirantis/ktl/pkg/kstar"
+	"github.com/Mirantis/ktl/pkg/types"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	exprName     = "expr"
+	exprMaxSteps = 1_000_000
+	exprTimeout  = 10 * time.Second
+)
+
+// Expr is the Starlark expression evaluated per resource, e.g. the output
+// column, with the resource predeclared as `it` and its cluster as
+// `cluster`, next to the library globals of the scripts. Each evaluation
+// is constrained by the step limit and the timeout of the Limits.
+type Expr struct {
+	Limits StarlarkLimits
+
+	text    string
+	globals starlark.StringDict
+	schemas *kstar.SchemaIndex
+}
+
+func NewExpr(text string) (*Expr, error) {
+	if _, err := starlarkFileOptions.ParseExpr(exprName, text, 0); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", text, err)
+	}
+
+	globals, err := libraryGlobals()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expr{
+		Limits: StarlarkLimits{
+			MaxSteps: exprMaxSteps,
+			Timeout:  exprTimeout,
+		},
+		text:    text,
+		globals: globals,
+		schemas: kstar.NewSchemaIndex(nil),
+	}, nil
+}
+
+func (expr *Expr) String() string {
+	return expr.text
+}
+
+func (expr *Expr) UnmarshalYAML(node *yaml.Node) error {
+	var text string
+	if err := node.Decode(&text); err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+
+	parsed, err := NewExpr(text)
+	if err != nil {
+		return err
+	}
+
+	*expr = *parsed
+
+	return nil
+}
+
+// Eval returns the value of the expression as a node, nil for None.
+func (expr *Expr) Eval(rnode *yaml.RNode, cluster *types.Cluster) (*yaml.Node, error) {
+	thread := &starlark.Thread{
+		Name: exprName,
+		Print: func(_ *starlark.Thread, msg string) {
+			slog.Info("starlark expression output", "msg", msg)
+		},
+	}
+
+	ctx, cancel := expr.Limits.context(context.Background())
+	defer cancel()
+
+	limits := &threadLimits{ctx: ctx, maxSteps: expr.Limits.MaxSteps}
+	stop := limits.apply(thread)
+
+	defer stop()
+
+	predeclared := maps.Clone(expr.globals)
+	predeclared["it"] = kstar.FromRNode(expr.schemas, rnode)
+	predeclared["cluster"] = clusterValue(cluster)
+
+	value, err := starlark.EvalOptions(starlarkFileOptions, thread, exprName, expr.text, predeclared)
+	if err != nil {
+		if limitErr := limits.exceeded(thread); limitErr != nil {
+			err = limitErr
+		}
+
+		return nil, fmt.Errorf("unable to evaluate %q: %w", expr.text, err)
+	}
+
+	if value == starlark.None {
+		return nil, nil //nolint:nilnil
+	}
+
+	return kstar.FromStarlark(value) //nolint:wrapcheck
+}
@@ -0,0 +1,314 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/kstar"
+	"github.com/Mirantis/ktl/pkg/types"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var (
+	errFleetDuplicate   = errors.New("duplicate resource")
+	errFleetFilter      = errors.New("unsupported filter")
+	errFleetResult      = errors.New("starlark filter returned unsupported result")
+	errFleetClusterName = errors.New("unsupported cluster name")
+)
+
+// FleetFilter transforms the merged resources of all the clusters at once.
+type FleetFilter interface {
+	FilterFleet(input *types.ClusterResources) (*types.ClusterResources, error)
+}
+
+func NewFleet(spec *apis.FleetFilter, args *yaml.RNode) (FleetFilter, error) {
+	if impl := spec.GetStarlark(); impl != nil {
+		sf, err := newStarlarkFilter(impl, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return sf, nil
+	}
+
+	return nil, errFleetFilter
+}
+
+var _ FleetFilter = new(StarlarkFilter)
+
+// FilterFleet runs the script with all the clusters at once:
+//
+//   - clusters: tuple of the clusters, e.g. clusters[0].name
+//   - resources: dict of the resource ID to the dict of the cluster name to
+//     the resource, the changes of both dicts are applied to the result
+//   - resources_by_cluster: read-only dict of the cluster name to the tuple
+//     of its resources
+//   - records: dict of the cluster name, or None, to the list of the records,
+//     the changes are applied to the result
+func (filter *StarlarkFilter) FilterFleet(input *types.ClusterResources) (*types.ClusterResources, error) {
+	clusters := starlark.Tuple{}
+	byCluster := map[types.ClusterID]starlark.Tuple{}
+
+	for _, cluster := range input.Clusters.All() {
+		clusters = append(clusters, clusterValue(&cluster))
+	}
+
+	ids := slices.Collect(maps.Keys(input.Resources))
+	slices.SortFunc(ids, func(a, b resid.ResId) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	crds := []*yaml.RNode{}
+
+	for _, id := range ids {
+		for clusterID := range input.Clusters.All() {
+			if rnode, found := input.Resources[id][clusterID]; found && rnode.GetKind() == crdKind {
+				crds = append(crds, rnode)
+			}
+		}
+	}
+
+	schemas, err := filter.schemas(crds)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := starlark.NewDict(len(ids))
+
+	for _, id := range ids {
+		variants := starlark.NewDict(len(input.Resources[id]))
+
+		for clusterID, cluster := range input.Clusters.All() {
+			rnode, found := input.Resources[id][clusterID]
+			if !found {
+				continue
+			}
+
+			node := kstar.FromRNode(schemas, rnode)
+			byCluster[clusterID] = append(byCluster[clusterID], node)
+
+			if err := variants.SetKey(starlark.String(cluster.Name), node); err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+		}
+
+		if err := resources.SetKey(starlark.String(id.String()), variants); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	resourcesByCluster := starlark.NewDict(len(clusters))
+
+	for clusterID, cluster := range input.Clusters.All() {
+		nodes := byCluster[clusterID]
+		if nodes == nil {
+			nodes = starlark.Tuple{}
+		}
+
+		if err := resourcesByCluster.SetKey(starlark.String(cluster.Name), nodes); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	resourcesByCluster.Freeze()
+
+	records, err := fleetRecordsValue(input)
+	if err != nil {
+		return nil, err
+	}
+
+	slPredeclared := starlark.StringDict{
+		"clusters":             clusters,
+		"resources":            resources,
+		"resources_by_cluster": resourcesByCluster,
+		"records":              records,
+		"schema":               schemas,
+	}
+
+	globals, err := filter.exec(slPredeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	slOutput, found := globals["resources"]
+	if !found {
+		slOutput = resources
+	}
+
+	budget := &outputBudget{limits: filter.Limits}
+
+	output, err := fleetResources(input.Clusters, slOutput, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	slRecords, found := globals["records"]
+	if !found {
+		slRecords = records
+	}
+
+	output.Records, err = fleetRecords(input.Clusters, slRecords, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// fleetRecordsValue returns the records of every cluster, the records not
+// related to a cluster are listed under None.
+func fleetRecordsValue(input *types.ClusterResources) (*starlark.Dict, error) {
+	byCluster := map[types.ClusterID]*starlark.List{}
+	fleetWide := starlark.NewList(nil)
+	records := starlark.NewDict(len(input.Clusters.IDs()) + 1)
+
+	for clusterID, cluster := range input.Clusters.All() {
+		byCluster[clusterID] = starlark.NewList(nil)
+
+		if err := records.SetKey(starlark.String(cluster.Name), byCluster[clusterID]); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	if err := records.SetKey(starlark.None, fleetWide); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	for _, record := range input.Records {
+		value, err := kstar.NewRecord(record.Fields)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		list := fleetWide
+		if record.Cluster != nil {
+			list = byCluster[*record.Cluster]
+		}
+
+		if err := list.Append(value); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	return records, nil
+}
+
+func fleetRecords(clusters *types.ClusterIndex, value starlark.Value, budget *outputBudget) ([]types.Record, error) {
+	slRecords, ok := value.(starlark.IterableMapping)
+	if !ok {
+		return nil, fmt.Errorf("%w: records %s", errFleetResult, value.Type())
+	}
+
+	records := []types.Record{}
+
+	for _, item := range slRecords.Items() {
+		var clusterID *types.ClusterID
+
+		if item[0] != starlark.None {
+			name, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", errFleetClusterName, item[0])
+			}
+
+			id, err := clusters.ID(name)
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+
+			clusterID = &id
+		}
+
+		slList, ok := item[1].(starlark.Iterable)
+		if !ok {
+			return nil, fmt.Errorf("%w: records of %s: %s", errFleetResult, item[0], item[1].Type())
+		}
+
+		for slRecord := range starlark.Elements(slList) {
+			ynode, err := kstar.FromStarlark(slRecord)
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+
+			if err := budget.add(ynode); err != nil {
+				return nil, err
+			}
+
+			// plain dicts are accepted as the record fields
+			fields, isRecord := types.RecordFields(yaml.NewRNode(ynode))
+			if !isRecord && ynode.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("%w: record of %s: %s", errFleetResult, item[0], slRecord.Type())
+			}
+
+			if !isRecord {
+				fields = yaml.NewRNode(ynode)
+			}
+
+			records = append(records, types.Record{Cluster: clusterID, Fields: fields})
+		}
+	}
+
+	return records, nil
+}
+
+func fleetResources(clusters *types.ClusterIndex, value starlark.Value, budget *outputBudget) (*types.ClusterResources, error) {
+	output := &types.ClusterResources{
+		Clusters:  clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{},
+	}
+
+	slResources, ok := value.(starlark.IterableMapping)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errFleetResult, value.Type())
+	}
+
+	for _, item := range slResources.Items() {
+		slVariants, ok := item[1].(starlark.IterableMapping)
+		if !ok {
+			return nil, fmt.Errorf("%w: variants of %s: %s", errFleetResult, item[0], item[1].Type())
+		}
+
+		for _, variant := range slVariants.Items() {
+			name, ok := starlark.AsString(variant[0])
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", errFleetClusterName, variant[0])
+			}
+
+			clusterID, err := clusters.ID(name)
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+
+			ynode, err := kstar.FromStarlark(variant[1])
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+
+			if err := budget.add(ynode); err != nil {
+				return nil, err
+			}
+
+			rnode := yaml.NewRNode(ynode)
+			nodeID := resid.FromRNode(rnode)
+
+			byCluster, idFound := output.Resources[nodeID]
+			if !idFound {
+				byCluster = map[types.ClusterID]*yaml.RNode{}
+				output.Resources[nodeID] = byCluster
+			}
+
+			if _, exists := byCluster[clusterID]; exists {
+				return nil, fmt.Errorf("%w: %s in cluster %s", errFleetDuplicate, nodeID, name)
+			}
+
+			byCluster[clusterID] = rnode
+		}
+	}
+
+	return output, nil
+}
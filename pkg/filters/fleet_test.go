@@ -0,0 +1,198 @@
+package filters_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/filters"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func fleetTestResources() *types.ClusterResources {
+	clusters := types.NewClusterIndex()
+	devID := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodID := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+
+	resources := map[resid.ResId]map[types.ClusterID]*yaml.RNode{}
+	add := func(clusterID types.ClusterID, body string) {
+		rnode := yaml.MustParse(body)
+		nodeID := resid.FromRNode(rnode)
+
+		if resources[nodeID] == nil {
+			resources[nodeID] = map[types.ClusterID]*yaml.RNode{}
+		}
+
+		resources[nodeID][clusterID] = rnode
+	}
+
+	add(devID, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: common\ndata:\n  url: https://dev-a.example.com\n")
+	add(prodID, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: common\ndata:\n  url: https://prod-a.example.com\n")
+	add(devID, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: debug\n")
+
+	return &types.ClusterResources{
+		Clusters:  clusters,
+		Resources: resources,
+	}
+}
+
+func TestStarlarkFleet(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "drop-partial",
+			script: `
+for id, variants in list(resources.items()):
+    if len(variants) < len(clusters):
+        resources.pop(id)
+`,
+			want: map[string]string{
+				"dev-a/common":  "https://dev-a.example.com",
+				"prod-a/common": "https://prod-a.example.com",
+			},
+		},
+		{
+			name: "normalize",
+			script: `
+for variants in resources.values():
+    for name, res in variants.items():
+        if res.data:
+            res.data.url = res.data.url.replace(name, "${CLUSTER}")
+`,
+			want: map[string]string{
+				"dev-a/common":  "https://${CLUSTER}.example.com",
+				"prod-a/common": "https://${CLUSTER}.example.com",
+				"dev-a/debug":   "",
+			},
+		},
+		{
+			name: "synthesize",
+			script: `
+for res in resources_by_cluster["dev-a"]:
+    if res.metadata.name == "debug":
+        prod = {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "debug"}, "data": {"url": "none"}}
+        resources["prod-debug"] = {clusters[1].name: prod}
+`,
+			want: map[string]string{
+				"dev-a/common":  "https://dev-a.example.com",
+				"prod-a/common": "https://prod-a.example.com",
+				"dev-a/debug":   "",
+				"prod-a/debug":  "none",
+			},
+		},
+		{
+			name:   "reassign",
+			script: `resources = {"common": {"prod-a": resources_by_cluster["prod-a"][0]}}`,
+			want: map[string]string{
+				"prod-a/common": "https://prod-a.example.com",
+			},
+		},
+		{
+			name:    "read-only",
+			script:  `resources_by_cluster.pop("dev-a")`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown-cluster",
+			script:  `resources["debug"] = {"stage-a": resources_by_cluster["dev-a"][0]}`,
+			wantErr: true,
+		},
+		{
+			name:    "duplicate",
+			script:  `resources["copy"] = {"prod-a": resources_by_cluster["prod-a"][0]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter, err := filters.NewFleet(&apis.FleetFilter{
+				Starlark: &apis.StarlarkFilter{Script: test.script},
+			}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := filter.FilterFleet(fleetTestResources())
+
+			switch {
+			case err != nil && test.wantErr:
+				t.Logf("got expected error: %v", err)
+				return
+			case err != nil:
+				t.Fatalf("want no error, got: %v", err)
+			case test.wantErr:
+				t.Fatalf("want error, got none")
+			}
+
+			got := map[string]string{}
+
+			for clusterID, cluster := range result.Clusters.All() {
+				for _, rnode := range result.All(&clusterID) {
+					url, _ := rnode.GetString("data.url")
+					got[cluster.Name+"/"+rnode.GetName()] = strings.TrimSpace(url)
+				}
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStarlarkFleetRecords(t *testing.T) {
+	input := fleetTestResources()
+	devID, _ := input.Clusters.ID("dev-a")
+	input.Records = []types.Record{
+		{Cluster: &devID, Fields: yaml.MustParse("name: common\nkeys: 1\n")},
+	}
+
+	script := `
+total = 0
+for r in records["dev-a"]:
+    total += r.keys
+records[None].append(record(clusters=len(clusters), keys=total))
+records["prod-a"].append({"name": "common", "keys": 1})
+`
+
+	filter, err := filters.NewFleet(&apis.FleetFilter{
+		Starlark: &apis.StarlarkFilter{Script: script},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := filter.FilterFleet(input)
+	if err != nil {
+		t.Fatalf("want no error, got: %v", err)
+	}
+
+	got := []string{}
+
+	for _, record := range result.Records {
+		cluster := ""
+		if record.Cluster != nil {
+			cluster = result.Clusters.Cluster(*record.Cluster).Name
+		}
+
+		got = append(got, cluster+": "+strings.TrimSpace(record.Fields.MustString()))
+	}
+
+	want := []string{
+		"dev-a: name: common\nkeys: 1",
+		"prod-a: name: common\nkeys: 1",
+		": clusters: 2\nkeys: 1",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("-want +got:\n%s", diff)
+	}
+}
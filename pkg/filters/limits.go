@@ -0,0 +1,119 @@
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const limitsLocalID = "ktl.limits"
+
+var errLimitExceeded = errors.New("starlark limit exceeded")
+
+// StarlarkLimits constrains the script execution, zero values are unlimited.
+type StarlarkLimits struct {
+	MaxSteps       uint64        `yaml:"maxSteps,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	MaxOutput      int           `yaml:"maxOutput,omitempty"`
+	MaxOutputNodes int           `yaml:"maxOutputNodes,omitempty"`
+}
+
+func newStarlarkLimits(spec *apis.StarlarkLimits) (StarlarkLimits, error) {
+	limits := StarlarkLimits{
+		MaxSteps:       spec.GetMaxSteps(),
+		MaxOutput:      int(spec.GetMaxOutput()),
+		MaxOutputNodes: int(spec.GetMaxOutputNodes()),
+	}
+
+	if spec.GetTimeout() != "" {
+		timeout, err := time.ParseDuration(spec.GetTimeout())
+		if err != nil {
+			return limits, fmt.Errorf("invalid timeout %q: %w", spec.GetTimeout(), err)
+		}
+
+		limits.Timeout = timeout
+	}
+
+	return limits, nil
+}
+
+// context returns the context of the script execution, cancelled with the
+// parent or after the timeout.
+func (limits StarlarkLimits) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if limits.Timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeoutCause(
+		parent,
+		limits.Timeout,
+		fmt.Errorf("%w: timeout %s", errLimitExceeded, limits.Timeout),
+	)
+}
+
+// threadLimits are applied to the script thread and the threads of the
+// modules loaded by the script.
+type threadLimits struct {
+	ctx      context.Context //nolint:containedctx
+	maxSteps uint64
+}
+
+func (limits *threadLimits) apply(thread *starlark.Thread) (stop func() bool) {
+	thread.SetLocal(limitsLocalID, limits)
+
+	if limits.maxSteps > 0 {
+		thread.SetMaxExecutionSteps(limits.maxSteps)
+	}
+
+	return context.AfterFunc(limits.ctx, func() {
+		thread.Cancel(context.Cause(limits.ctx).Error())
+	})
+}
+
+// exceeded returns the error of the limit cancelling the thread, if any.
+func (limits *threadLimits) exceeded(thread *starlark.Thread) error {
+	switch {
+	case limits.ctx.Err() != nil:
+		return context.Cause(limits.ctx)
+	case limits.maxSteps > 0 && thread.ExecutionSteps() >= limits.maxSteps:
+		return fmt.Errorf("%w: max steps %d", errLimitExceeded, limits.maxSteps)
+	default:
+		return nil
+	}
+}
+
+// outputBudget counts the output resources and their nodes once the script
+// has finished, so it caps the size of the output passed to the next stages.
+type outputBudget struct {
+	limits StarlarkLimits
+	items  int
+	nodes  int
+}
+
+func (budget *outputBudget) add(ynode *yaml.Node) error {
+	budget.items++
+	budget.nodes += countNodes(ynode)
+
+	switch {
+	case budget.limits.MaxOutput > 0 && budget.items > budget.limits.MaxOutput:
+		return fmt.Errorf("%w: max output %d resources", errLimitExceeded, budget.limits.MaxOutput)
+	case budget.limits.MaxOutputNodes > 0 && budget.nodes > budget.limits.MaxOutputNodes:
+		return fmt.Errorf("%w: max output %d nodes", errLimitExceeded, budget.limits.MaxOutputNodes)
+	default:
+		return nil
+	}
+}
+
+func countNodes(ynode *yaml.Node) int {
+	count := 1
+	for _, child := range ynode.Content {
+		count += countNodes(child)
+	}
+
+	return count
+}
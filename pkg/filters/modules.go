@@ -0,0 +1,153 @@
+package filters
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	stdlibPrefix   = "@ktl/"
+	loadingStackID = "ktl.loading"
+)
+
+var (
+	//go:embed data/ktl/*.star
+	stdlibFS embed.FS
+
+	errModuleNotFound = errors.New("module not found")
+	errModuleCycle    = errors.New("cycle in load graph")
+	errModuleNoFS     = errors.New("module loading requires pipeline environment")
+)
+
+type moduleEntry struct {
+	globals starlark.StringDict
+	err     error
+	ready   chan struct{}
+}
+
+// ModuleCache loads and caches Starlark modules referenced via load().
+//
+// Modules prefixed with "@ktl/" are taken from the standard library shipped
+// with the binary, all the other modules are read from the file system
+// relative to the pipeline directory. Each module is executed once and its
+// frozen globals are shared by all the filters.
+type ModuleCache struct {
+	fileSys filesys.FileSystem
+	mutex   sync.Mutex
+	entries map[string]*moduleEntry
+}
+
+func NewModuleCache(fileSys filesys.FileSystem) *ModuleCache {
+	return &ModuleCache{
+		fileSys: fileSys,
+		entries: map[string]*moduleEntry{},
+	}
+}
+
+func (cache *ModuleCache) resolve(module string) (string, []byte, error) {
+	if name, found := strings.CutPrefix(module, stdlibPrefix); found {
+		body, err := fs.ReadFile(stdlibFS, path.Join("data", "ktl", path.Clean("/"+name)))
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %s", errModuleNotFound, module)
+		}
+
+		return module, body, nil
+	}
+
+	if err := checkLocalPath(module); err != nil {
+		return "", nil, fmt.Errorf("invalid module %s: %w", module, err)
+	}
+
+	if cache == nil || cache.fileSys == nil {
+		return "", nil, fmt.Errorf("%w: %s", errModuleNoFS, module)
+	}
+
+	key := filepath.Clean(module)
+
+	body, err := cache.fileSys.ReadFile(key)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s: %w", errModuleNotFound, module, err)
+	}
+
+	return key, body, nil
+}
+
+// Load implements starlark.Thread.Load.
+func (cache *ModuleCache) Load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	key, body, err := cache.resolve(module)
+	if err != nil {
+		return nil, err
+	}
+
+	stack, _ := thread.Local(loadingStackID).([]string)
+	if slices.Contains(stack, key) {
+		return nil, fmt.Errorf("%w: %s", errModuleCycle, strings.Join(append(stack, key), " -> "))
+	}
+
+	cache.mutex.Lock()
+	entry, found := cache.entries[key]
+
+	if !found {
+		entry = &moduleEntry{ready: make(chan struct{})}
+		cache.entries[key] = entry
+	}
+	cache.mutex.Unlock()
+
+	if found {
+		<-entry.ready
+
+		return entry.globals, entry.err
+	}
+
+	defer close(entry.ready)
+
+	entry.globals, entry.err = cache.exec(thread, key, body, append(slices.Clone(stack), key))
+
+	return entry.globals, entry.err
+}
+
+func (cache *ModuleCache) exec(parent *starlark.Thread, key string, body []byte, stack []string) (starlark.StringDict, error) {
+	predeclared, err := libraryGlobals()
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &starlark.Thread{
+		Name:  key,
+		Print: parent.Print,
+		Load:  cache.Load,
+	}
+	thread.SetLocal(loadingStackID, stack)
+
+	limits, limited := parent.Local(limitsLocalID).(*threadLimits)
+	if limited {
+		stop := limits.apply(thread)
+
+		defer stop()
+	}
+
+	globals, err := starlark.ExecFileOptions(starlarkFileOptions, thread, key, body, predeclared)
+	if err != nil && limited {
+		if limitErr := limits.exceeded(thread); limitErr != nil {
+			err = limitErr
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to load %s: %w", key, err)
+	}
+
+	globals.Freeze()
+
+	return globals, nil
+}
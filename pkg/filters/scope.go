@@ -0,0 +1,36 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// Scope holds the pipeline state shared by the filters during execution.
+type Scope struct {
+	Env     *types.Env
+	Modules *ModuleCache
+
+	// Cluster being processed, nil if the filters are not cluster-specific
+	Cluster *types.Cluster
+
+	// Context of the pipeline run, cancelling the running scripts
+	Context context.Context //nolint:containedctx
+}
+
+func NewScope(env *types.Env) *Scope {
+	scope := &Scope{Env: env, Context: context.Background()}
+
+	if env != nil {
+		scope.Modules = NewModuleCache(env.FileSys)
+	}
+
+	return scope
+}
+
+// Scoped is implemented by the filters depending on the execution scope.
+type Scoped interface {
+	kio.Filter
+	SetScope(scope *Scope)
+}
@@ -50,17 +50,50 @@ func newSkipFilter(spec *apis.SkipFilter) (*SkipFilter, error) {
 		sf.Fields = append(sf.Fields, q)
 	}
 
+	if tagsSpec := spec.GetMatchClusterTags(); tagsSpec != nil {
+		tags, err := types.NewPatternSelector(tagsSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		sf.ClusterTags = &tags
+	}
+
 	return sf, nil
 }
 
 type SkipFilter struct {
-	Kind      string            `yaml:"kind"`
-	Resources []*types.Selector `yaml:"resources"`
-	Except    []*types.Selector `yaml:"except"`
-	Fields    []resource.Query  `yaml:"fields"`
+	Kind        string                 `yaml:"kind"`
+	Resources   []*types.Selector      `yaml:"resources"`
+	Except      []*types.Selector      `yaml:"except"`
+	Fields      []resource.Query       `yaml:"fields"`
+	ClusterTags *types.PatternSelector `yaml:"clusterTags"`
+	scope       *Scope
+}
+
+var _ Scoped = new(SkipFilter)
+
+func (filter *SkipFilter) SetScope(scope *Scope) {
+	filter.scope = scope
+}
+
+func (filter *SkipFilter) matchCluster() bool {
+	if filter.ClusterTags == nil {
+		return true
+	}
+
+	if filter.scope == nil || filter.scope.Cluster == nil {
+		return false
+	}
+
+	return filter.ClusterTags.MatchAny(filter.scope.Cluster.Tags)
 }
 
 func (filter *SkipFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	if !filter.matchCluster() {
+		return input, nil
+	}
+
 	match := &ResourceMatcher{
 		Resources: filter.Resources,
 		Except:    filter.Except,
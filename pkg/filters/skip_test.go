@@ -0,0 +1,66 @@
+package filters_test
+
+import (
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/filters"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestSkipFilterClusterTags(t *testing.T) {
+	spec := &apis.SkipFilter{
+		Fields: []string{"spec"},
+		MatchClusterTags: &apis.PatternSelector{
+			Include: []string{"prod*"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cluster *types.Cluster
+		want    string
+	}{
+		{
+			name: "no-cluster",
+			want: "kind: ConfigMap\nspec: {}\n",
+		},
+		{
+			name:    "dev",
+			cluster: &types.Cluster{Name: "dev-a", Tags: []string{"dev"}},
+			want:    "kind: ConfigMap\nspec: {}\n",
+		},
+		{
+			name:    "prod",
+			cluster: &types.Cluster{Name: "prod-a", Tags: []string{"prod-eu"}},
+			want:    "kind: ConfigMap\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kfilter, err := filters.New(&apis.Filter{Skip: spec}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			scoped, ok := kfilter.Filter.(filters.Scoped)
+			if !ok {
+				t.Fatalf("skip filter is not scoped")
+			}
+
+			scoped.SetScope(&filters.Scope{Cluster: test.cluster})
+
+			got, err := kfilter.Filter.Filter([]*yaml.RNode{yaml.MustParse("kind: ConfigMap\nspec: {}\n")})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.want, got[0].MustString()); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
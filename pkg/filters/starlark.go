@@ -1,71 +1,348 @@
 package filters
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
 
 	"github.com/Mirantis/ktl/pkg/apis"
 	"github.com/Mirantis/ktl/pkg/kstar"
+	"github.com/Mirantis/ktl/pkg/types"
 	"github.com/qri-io/starlib/encoding/base64"
+	"github.com/qri-io/starlib/re"
+	"go.starlark.net/lib/json"
+	"go.starlark.net/lib/math"
+	"go.starlark.net/lib/time"
 	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
 	"go.starlark.net/syntax"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+const (
+	starlarkFilterName = "starlark-filter"
+	crdKind            = "CustomResourceDefinition"
+)
+
+var (
+	errMutuallyExclusive = errors.New("only one attribute allowed")
+	errAbsPath           = errors.New("absolute path not allowed")
+	errParentPath        = errors.New("path outside of the pipeline directory not allowed")
+
+	//nolint:gochecknoglobals
+	starlarkFileOptions = &syntax.FileOptions{
+		TopLevelControl: true,
+		GlobalReassign:  true,
+	}
+)
+
 //nolint:gochecknoinits
 func init() {
 	filters.Filters["Starlark"] = func() kio.Filter { return &StarlarkFilter{} }
 }
 
 func newStarlarkFilter(spec *apis.StarlarkFilter, args *yaml.RNode) (*StarlarkFilter, error) {
+	if spec.GetScript() != "" && spec.GetScriptFile() != "" {
+		return nil, fmt.Errorf("%w: script,script_file", errMutuallyExclusive)
+	}
+
+	limits, err := newStarlarkLimits(spec.GetLimits())
+	if err != nil {
+		return nil, err
+	}
+
+	// the pipelines run without the args are given the empty ones
+	if args == nil {
+		args = yaml.NewMapRNode(nil)
+	}
+
 	return &StarlarkFilter{
-		Kind:   "Starlark",
-		Script: spec.GetScript(),
-		args:   args,
+		Kind:       "Starlark",
+		Script:     spec.GetScript(),
+		ScriptFile: spec.GetScriptFile(),
+		CRDFiles:   spec.GetCrdFiles(),
+		Strict:     spec.GetStrict(),
+		Limits:     limits,
+		args:       args,
 	}, nil
 }
 
 type StarlarkFilter struct {
-	Kind   string `yaml:"kind"`
-	Script string `yaml:"script"`
-	args   *yaml.RNode
+	Kind       string         `yaml:"kind"`
+	Script     string         `yaml:"script"`
+	ScriptFile string         `yaml:"scriptFile"`
+	CRDFiles   []string       `yaml:"crdFiles"`
+	Strict     bool           `yaml:"strict"`
+	Limits     StarlarkLimits `yaml:"limits"`
+	args       *yaml.RNode
+	scope      *Scope
 }
 
-func (filter *StarlarkFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
-	output := []*yaml.RNode{}
-	schemas := kstar.NewSchemaIndex(nil)
+var _ Scoped = new(StarlarkFilter)
+
+func (filter *StarlarkFilter) SetScope(scope *Scope) {
+	filter.scope = scope
+}
+
+// libraryGlobals returns the predeclared values available both to the
+// filter scripts and the loaded modules.
+func libraryGlobals() (starlark.StringDict, error) {
 	b64, err := base64.LoadModule()
 	if err != nil {
 		return nil, fmt.Errorf("b64 module: %w", err)
 	}
 
-	slPredeclared := starlark.StringDict{
-		"resources": kstar.FromRNodes(schemas, input),
-		"output":    starlark.NewList(nil),
-		"args":      kstar.FromYNode(filter.args.YNode()),
-		"base64":    b64["base64"],
+	regexp, err := re.LoadModule()
+	if err != nil {
+		return nil, fmt.Errorf("re module: %w", err)
 	}
-	slOpts := &syntax.FileOptions{
-		TopLevelControl: true,
-		GlobalReassign:  true,
+
+	globals := starlark.StringDict{
+		"base64": b64["base64"],
+		"json":   json.Module,
+		"math":   math.Module,
+		"re":     regexp["re"],
+		"time":   time.Module,
+	}
+	maps.Copy(globals, kstar.Builtins())
+
+	return globals, nil
+}
+
+// clusterValue returns the cluster as a frozen struct, e.g. cluster.name and
+// cluster.tags. The clusters are only known by the kubeconfig contexts and
+// the tags of the cluster selectors, so there are no inventory labels.
+func clusterValue(cluster *types.Cluster) starlark.Value {
+	if cluster == nil {
+		return starlark.None
+	}
+
+	tags := starlark.Tuple{}
+	for _, tag := range cluster.Tags {
+		tags = append(tags, starlark.String(tag))
 	}
 
-	slThread := &starlark.Thread{
-		Name: "starlark-filter",
+	value := starlarkstruct.FromStringDict(starlark.String("cluster"), starlark.StringDict{
+		"name": starlark.String(cluster.Name),
+		"tags": tags,
+	})
+	value.Freeze()
+
+	return value
+}
+
+// cluster returns the cluster being processed.
+func (filter *StarlarkFilter) cluster() starlark.Value {
+	if filter.scope == nil {
+		return starlark.None
+	}
+
+	return clusterValue(filter.scope.Cluster)
+}
+
+func (filter *StarlarkFilter) context() context.Context {
+	if filter.scope == nil || filter.scope.Context == nil {
+		return context.Background()
+	}
+
+	return filter.scope.Context
+}
+
+func (filter *StarlarkFilter) modules() *ModuleCache {
+	if filter.scope == nil || filter.scope.Modules == nil {
+		return NewModuleCache(nil)
+	}
+
+	return filter.scope.Modules
+}
+
+// checkLocalPath rejects the paths outside of the pipeline directory.
+func checkLocalPath(path string) error {
+	if filepath.IsAbs(path) {
+		return errAbsPath
+	}
+
+	if !filepath.IsLocal(path) {
+		return errParentPath
+	}
+
+	return nil
+}
+
+func (filter *StarlarkFilter) source() (string, any, error) {
+	if filter.ScriptFile == "" {
+		return starlarkFilterName, filter.Script, nil
+	}
+
+	if err := checkLocalPath(filter.ScriptFile); err != nil {
+		return "", nil, fmt.Errorf("invalid script file %s: %w", filter.ScriptFile, err)
+	}
+
+	if filter.scope == nil || filter.scope.Env == nil {
+		return "", nil, fmt.Errorf("%w: %s", errModuleNoFS, filter.ScriptFile)
+	}
+
+	body, err := filter.scope.Env.FileSys.ReadFile(filter.ScriptFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read script file: %w", err)
+	}
+
+	return filter.ScriptFile, body, nil
+}
+
+// schemas returns the schema index with the CRDs from the input resources
+// and the CRD files registered.
+func (filter *StarlarkFilter) schemas(input []*yaml.RNode) (*kstar.SchemaIndex, error) {
+	schemas := kstar.NewSchemaIndex(nil)
+	schemas.SetStrict(filter.Strict)
+
+	crds := []*yaml.RNode{}
+
+	for _, path := range filter.CRDFiles {
+		if err := checkLocalPath(path); err != nil {
+			return nil, fmt.Errorf("invalid CRD file %s: %w", path, err)
+		}
+
+		if filter.scope == nil || filter.scope.Env == nil {
+			return nil, fmt.Errorf("%w: %s", errModuleNoFS, path)
+		}
+
+		body, err := filter.scope.Env.FileSys.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CRD file: %w", err)
+		}
+
+		rnodes, err := kio.FromBytes(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse CRD file %s: %w", path, err)
+		}
+
+		crds = append(crds, rnodes...)
+	}
+
+	for _, rnode := range slices.Concat(input, crds) {
+		if rnode.GetKind() != crdKind {
+			continue
+		}
+
+		if err := schemas.AddCRD(rnode); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	return schemas, nil
+}
+
+// globals returns the library globals, args and cluster with the predeclared
+// values added.
+func (filter *StarlarkFilter) globals(predeclared starlark.StringDict) (starlark.StringDict, error) {
+	slPredeclared, err := libraryGlobals()
+	if err != nil {
+		return nil, err
+	}
+
+	slPredeclared["args"] = kstar.FromYNode(filter.args.YNode())
+	slPredeclared["cluster"] = filter.cluster()
+	maps.Copy(slPredeclared, predeclared)
+
+	return slPredeclared, nil
+}
+
+// Predeclared returns the values predeclared for the script filtering the
+// input, e.g. for the interactive sessions.
+func (filter *StarlarkFilter) Predeclared(input []*yaml.RNode) (starlark.StringDict, error) {
+	schemas, err := filter.schemas(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.globals(starlark.StringDict{
+		"resources": kstar.FromRNodes(schemas, input),
+		"output":    starlark.NewList(nil),
+		"schema":    schemas,
+	})
+}
+
+// NewThread returns the thread loading the modules of the filter scope.
+func (filter *StarlarkFilter) NewThread(name string) *starlark.Thread {
+	return &starlark.Thread{
+		Name: name,
 		Print: func(thread *starlark.Thread, msg string) {
 			slog.Info("starlark filter output", "msg", msg)
 		},
+		Load: filter.modules().Load,
 	}
-	_, err = starlark.ExecFileOptions(
-		slOpts,
+}
+
+// exec runs the script with the library globals, args and cluster added to
+// the predeclared values.
+func (filter *StarlarkFilter) exec(predeclared starlark.StringDict) (starlark.StringDict, error) {
+	fileName, script, err := filter.source()
+	if err != nil {
+		return nil, err
+	}
+
+	slPredeclared, err := filter.globals(predeclared)
+	if err != nil {
+		return nil, err
+	}
+
+	slThread := filter.NewThread(starlarkFilterName)
+
+	ctx, cancel := filter.Limits.context(filter.context())
+	defer cancel()
+
+	limits := &threadLimits{ctx: ctx, maxSteps: filter.Limits.MaxSteps}
+	stop := limits.apply(slThread)
+
+	defer stop()
+
+	globals, err := starlark.ExecFileOptions(
+		starlarkFileOptions,
 		slThread,
-		"starlark-filter",
-		filter.Script,
+		fileName,
+		script,
 		slPredeclared,
 	)
 
+	if err != nil {
+		if limitErr := limits.exceeded(slThread); limitErr != nil {
+			return nil, limitErr
+		}
+	}
+
+	// report the innermost script position, skipping the built-ins
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		for depth := range len(evalErr.CallStack) {
+			if frame := evalErr.CallStack.At(depth); frame.Pos.Line > 0 {
+				err = fmt.Errorf("%s: %w", frame.Pos, err)
+				break
+			}
+		}
+	}
+
+	return globals, err //nolint:wrapcheck
+}
+
+func (filter *StarlarkFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error) {
+	output := []*yaml.RNode{}
+
+	slPredeclared, err := filter.Predeclared(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := filter.exec(slPredeclared); err != nil {
+		return nil, err
+	}
+
 	slOutput, ok := slPredeclared["output"].(starlark.Iterable)
 	if !ok {
 		return nil, fmt.Errorf("starlark filter returned unsupported result")
@@ -75,14 +352,20 @@ func (filter *StarlarkFilter) Filter(input []*yaml.RNode) ([]*yaml.RNode, error)
 	iter := slOutput.Iterate()
 	defer iter.Done()
 
+	budget := &outputBudget{limits: filter.Limits}
+
 	for iter.Next(&value) {
 		ynode, err := kstar.FromStarlark(value)
 		if err != nil {
 			return nil, err
 		}
 
+		if err := budget.add(ynode); err != nil {
+			return nil, err
+		}
+
 		output = append(output, yaml.NewRNode(ynode))
 	}
 
-	return output, err
+	return output, nil
 }
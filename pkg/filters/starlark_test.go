@@ -0,0 +1,586 @@
+package filters_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/filters"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const starlarkTestDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+spec:
+  template:
+    spec:
+      containers:
+      - name: myapp
+        image: myapp:v1
+      initContainers:
+      - name: init
+        image: init:v1
+`
+
+func runStarlarkFilter(t *testing.T, scope *filters.Scope, spec *apis.StarlarkFilter) ([]string, error) {
+	t.Helper()
+
+	kfilter, err := filters.New(&apis.Filter{Starlark: spec}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if scoped, ok := kfilter.Filter.(filters.Scoped); ok {
+		scoped.SetScope(scope)
+	}
+
+	result, err := kfilter.Filter.Filter([]*yaml.RNode{yaml.MustParse(starlarkTestDeployment)})
+	if err != nil {
+		return nil, err
+	}
+
+	got := []string{}
+	for _, rnode := range result {
+		got = append(got, strings.TrimSpace(rnode.MustString()))
+	}
+
+	return got, nil
+}
+
+func TestStarlarkLoad(t *testing.T) {
+	fileSys := filesys.MakeFsInMemory()
+	files := map[string]string{
+		"lib/helpers.star": `
+load("lib/names.star", "prefix")
+def name(it):
+    return prefix + it.metadata.name
+`,
+		"lib/names.star":   `prefix = "name: "`,
+		"lib/cycle-a.star": `load("lib/cycle-b.star", "b")`,
+		"lib/cycle-b.star": `load("lib/cycle-a.star", "a")`,
+		"script.star": `
+load("lib/helpers.star", "name")
+output.append({"value": name(resources[0])})
+`,
+	}
+
+	for path, body := range files {
+		if err := fileSys.WriteFile(path, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scope := filters.NewScope(&types.Env{FileSys: fileSys})
+
+	tests := []struct {
+		name    string
+		spec    *apis.StarlarkFilter
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "relative",
+			spec: &apis.StarlarkFilter{Script: `
+load("lib/helpers.star", "name")
+output.append({"value": name(resources[0])})
+`},
+			want: []string{`value: 'name: myapp'`},
+		},
+		{
+			name: "script-file",
+			spec: &apis.StarlarkFilter{ScriptFile: ptr("script.star")},
+			want: []string{`value: 'name: myapp'`},
+		},
+		{
+			name: "stdlib",
+			spec: &apis.StarlarkFilter{Script: `
+load("@ktl/k8s.star", "images", "set_label")
+set_label(resources[0], "app", "myapp")
+output.append({"images": images(resources[0]), "labels": resources[0].metadata.labels})
+`},
+			want: []string{"images:\n- myapp:v1\n- init:v1\nlabels:\n  app: myapp"},
+		},
+		{
+			name:    "missing",
+			spec:    &apis.StarlarkFilter{Script: `load("lib/missing.star", "name")`},
+			wantErr: true,
+		},
+		{
+			name:    "absolute",
+			spec:    &apis.StarlarkFilter{Script: `load("/lib/helpers.star", "name")`},
+			wantErr: true,
+		},
+		{
+			name:    "parent",
+			spec:    &apis.StarlarkFilter{Script: `load("lib/../../helpers.star", "name")`},
+			wantErr: true,
+		},
+		{
+			name:    "parent-script-file",
+			spec:    &apis.StarlarkFilter{ScriptFile: ptr("../script.star")},
+			wantErr: true,
+		},
+		{
+			name:    "cycle",
+			spec:    &apis.StarlarkFilter{Script: `load("lib/cycle-a.star", "a")`},
+			wantErr: true,
+		},
+		{
+			name:    "mutually-exclusive",
+			spec:    &apis.StarlarkFilter{Script: `pass`, ScriptFile: ptr("script.star")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := runStarlarkFilter(t, scope, test.spec)
+
+			switch {
+			case err != nil && test.wantErr:
+				t.Logf("got expected error: %v", err)
+				return
+			case err != nil:
+				t.Fatalf("want no error, got: %v", err)
+			case test.wantErr:
+				t.Fatalf("want error, got none")
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		if err := fileSys.WriteFile("lib/names.star", []byte(`prefix = "changed: "`)); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := runStarlarkFilter(t, scope, &apis.StarlarkFilter{ScriptFile: ptr("script.star")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if diff := cmp.Diff([]string{`value: 'name: myapp'`}, got); diff != "" {
+			t.Fatalf("-want +got:\n%s", diff)
+		}
+	})
+}
+
+func TestStarlarkCluster(t *testing.T) {
+	script := `
+if cluster and "prod" in cluster.tags:
+    resources[0].metadata.annotations = {"example.com/cluster": cluster.name}
+output.append(resources[0].metadata)
+`
+	tests := []struct {
+		name    string
+		cluster *types.Cluster
+		want    []string
+	}{
+		{
+			name: "no-cluster",
+			want: []string{"name: myapp"},
+		},
+		{
+			name:    "dev",
+			cluster: &types.Cluster{Name: "dev-a", Tags: []string{"dev"}},
+			want:    []string{"name: myapp"},
+		},
+		{
+			name:    "prod",
+			cluster: &types.Cluster{Name: "prod-a", Tags: []string{"prod"}},
+			want:    []string{"name: myapp\nannotations:\n  example.com/cluster: prod-a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scope := &filters.Scope{Cluster: test.cluster}
+
+			got, err := runStarlarkFilter(t, scope, &apis.StarlarkFilter{Script: script})
+			if err != nil {
+				t.Fatalf("want no error, got: %v", err)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStarlarkLibrary(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{
+			name:   "json",
+			script: `json.decode('{"replicas": 3}')["replicas"]`,
+			want:   `value: "3"`,
+		},
+		{
+			name:   "json-encode-node",
+			script: `json.encode(resources[0].spec.template.spec.containers[0])`,
+			want:   `value: '{"image":"myapp:v1","name":"myapp"}'`,
+		},
+		{
+			name:   "yaml",
+			script: `yaml.encode(resources[0].metadata)`,
+			want:   "value: |\n  name: myapp",
+		},
+		{
+			name:   "re",
+			script: `re.sub(r":v\d+$", ":v2", resources[0].spec.template.spec.containers[0].image)`,
+			want:   "value: myapp:v2",
+		},
+		{
+			name:   "time",
+			script: `time.parse_time("2025-01-02T00:00:00Z") - time.parse_time("2025-01-01T00:00:00Z")`,
+			want:   "value: 24h0m0s",
+		},
+		{
+			name:   "math",
+			script: `math.ceil(2.1)`,
+			want:   `value: "3"`,
+		},
+		{
+			name:   "quantity",
+			script: `quantity("500m") + quantity("1")`,
+			want:   "value: 1500m",
+		},
+		{
+			name:   "duration",
+			script: `duration("1m") + duration(30)`,
+			want:   "value: 1m30s",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := runStarlarkFilter(t, nil, &apis.StarlarkFilter{
+				Script: "output.append({'value': str(" + test.script + ")})",
+			})
+			if err != nil {
+				t.Fatalf("want no error, got: %v", err)
+			}
+
+			if diff := cmp.Diff([]string{test.want}, got); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStarlarkStrict(t *testing.T) {
+	script := `
+for it in resources:
+    it.spec.replica = 3
+    output.append(it)
+`
+
+	got, err := runStarlarkFilter(t, nil, &apis.StarlarkFilter{Script: script})
+	if err != nil {
+		t.Fatalf("want no error, got: %v", err)
+	}
+
+	if !strings.Contains(got[0], "replica: 3") {
+		t.Fatalf("want replica field, got:\n%s", got[0])
+	}
+
+	_, err = runStarlarkFilter(t, nil, &apis.StarlarkFilter{Script: script, Strict: ptr(true)})
+
+	want := "starlark-filter:3:12: schema violation: Deployment/myapp: replica: unknown field of io.k8s.api.apps.v1.DeploymentSpec"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got: %v", want, err)
+	}
+}
+
+func TestStarlarkLimits(t *testing.T) {
+	fileSys := filesys.MakeFsInMemory()
+	if err := fileSys.WriteFile("lib/loop.star", []byte("for i in range(1 << 60):\n    pass")); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		script  string
+		limits  *apis.StarlarkLimits
+		ctx     context.Context //nolint:containedctx
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "within-limits",
+			script: `output.append({"name": resources[0].metadata.name})`,
+			limits: &apis.StarlarkLimits{
+				MaxSteps:       ptr(uint64(1000)),
+				Timeout:        ptr("10s"),
+				MaxOutput:      ptr(uint32(1)),
+				MaxOutputNodes: ptr(uint32(3)),
+			},
+			want: []string{"name: myapp"},
+		},
+		{
+			name:    "max-steps",
+			script:  "for i in range(1 << 60):\n    pass",
+			limits:  &apis.StarlarkLimits{MaxSteps: ptr(uint64(1000))},
+			wantErr: "starlark limit exceeded: max steps 1000",
+		},
+		{
+			name:    "max-steps-module",
+			script:  `load("lib/loop.star", "x")`,
+			limits:  &apis.StarlarkLimits{MaxSteps: ptr(uint64(1000))},
+			wantErr: "starlark limit exceeded: max steps 1000",
+		},
+		{
+			name:    "timeout",
+			script:  "for i in range(1 << 60):\n    pass",
+			limits:  &apis.StarlarkLimits{Timeout: ptr("50ms")},
+			wantErr: "starlark limit exceeded: timeout 50ms",
+		},
+		{
+			name:    "cancelled",
+			script:  "for i in range(1 << 60):\n    pass",
+			ctx:     cancelled,
+			wantErr: "context canceled",
+		},
+		{
+			name:    "max-output",
+			script:  "for i in range(3):\n    output.append({'i': i})",
+			limits:  &apis.StarlarkLimits{MaxOutput: ptr(uint32(2))},
+			wantErr: "starlark limit exceeded: max output 2 resources",
+		},
+		{
+			name:    "max-output-nodes",
+			script:  `output.append(resources[0])`,
+			limits:  &apis.StarlarkLimits{MaxOutputNodes: ptr(uint32(10))},
+			wantErr: "starlark limit exceeded: max output 10 nodes",
+		},
+		{
+			name:    "invalid-timeout",
+			script:  `pass`,
+			limits:  &apis.StarlarkLimits{Timeout: ptr("1 minute")},
+			wantErr: `invalid timeout "1 minute"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scope := filters.NewScope(&types.Env{FileSys: fileSys})
+			if test.ctx != nil {
+				scope.Context = test.ctx
+			}
+
+			got, err := runStarlarkFilter(t, scope, &apis.StarlarkFilter{
+				Script: test.script,
+				Limits: test.limits,
+			})
+
+			switch {
+			case err != nil && test.wantErr != "":
+				if !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("want error %q, got: %v", test.wantErr, err)
+				}
+
+				return
+			case err != nil:
+				t.Fatalf("want no error, got: %v", err)
+			case test.wantErr != "":
+				t.Fatalf("want error %q, got none", test.wantErr)
+			}
+
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestStarlarkCRD(t *testing.T) {
+	crd := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.cert-manager.io
+spec:
+  group: cert-manager.io
+  names:
+    kind: Certificate
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              additionalOutputFormats:
+                type: array
+                x-kubernetes-list-type: map
+                x-kubernetes-list-map-keys:
+                - type
+                items:
+                  type: object
+`
+	cert := `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: web
+spec:
+  additionalOutputFormats:
+  - type: DER
+  - type: CombinedPEM
+`
+	script := `
+for it in resources:
+    if it.kind == "Certificate":
+        it.spec += {"additionalOutputFormats": [{"type": "DER", "suffix": "der"}]}
+        output.append(it)
+
+output.append(schema.Certificate({"metadata": {"name": "api"}}))
+`
+	want := []string{
+		strings.Join([]string{
+			`apiVersion: cert-manager.io/v1`,
+			`kind: Certificate`,
+			`metadata:`,
+			`  name: web`,
+			`spec:`,
+			`  additionalOutputFormats:`,
+			`  - type: DER`,
+			`    suffix: der`,
+			`  - type: CombinedPEM`,
+		}, "\n"),
+		"metadata:\n  name: api",
+	}
+
+	fileSys := filesys.MakeFsInMemory()
+	if err := fileSys.WriteFile("crds/certificates.yaml", []byte(crd)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		crdFiles []string
+		input    []string
+		wantErr  bool
+	}{
+		{
+			name:  "input",
+			input: []string{crd, cert},
+		},
+		{
+			name:     "crd-files",
+			crdFiles: []string{"crds/certificates.yaml"},
+			input:    []string{cert},
+		},
+		{
+			name:    "missing",
+			input:   []string{cert},
+			wantErr: true,
+		},
+		{
+			name:     "missing-file",
+			crdFiles: []string{"crds/missing.yaml"},
+			input:    []string{cert},
+			wantErr:  true,
+		},
+		{
+			name:     "absolute",
+			crdFiles: []string{"/crds/certificates.yaml"},
+			input:    []string{cert},
+			wantErr:  true,
+		},
+		{
+			name:     "parent",
+			crdFiles: []string{"../crds/certificates.yaml"},
+			input:    []string{cert},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kfilter, err := filters.New(&apis.Filter{Starlark: &apis.StarlarkFilter{
+				Script:   script,
+				CrdFiles: test.crdFiles,
+			}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kfilter.Filter.(filters.Scoped).SetScope(filters.NewScope(&types.Env{FileSys: fileSys}))
+
+			input := []*yaml.RNode{}
+			for _, body := range test.input {
+				input = append(input, yaml.MustParse(body))
+			}
+
+			result, err := kfilter.Filter.Filter(input)
+
+			switch {
+			case err != nil && test.wantErr:
+				t.Logf("got expected error: %v", err)
+				return
+			case err != nil:
+				t.Fatalf("want no error, got: %v", err)
+			case test.wantErr:
+				t.Fatalf("want error, got none")
+			}
+
+			got := []string{}
+			for _, rnode := range result {
+				got = append(got, strings.TrimSpace(rnode.MustString()))
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStarlarkRecords(t *testing.T) {
+	script := `
+for it in resources:
+    for c in it.spec.template.spec.containers:
+        output.append(record(name=it.metadata.name, container=c.name, image=c.image))
+`
+
+	got, err := runStarlarkFilter(t, nil, &apis.StarlarkFilter{Script: script})
+	if err != nil {
+		t.Fatalf("want no error, got: %v", err)
+	}
+
+	want := []string{strings.Join([]string{
+		`apiVersion: ktl.mirantis.com/v1`,
+		`kind: Record`,
+		`fields:`,
+		`  name: myapp`,
+		`  container: myapp`,
+		`  image: myapp:v1`,
+	}, "\n")}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("-want +got:\n%s", diff)
+	}
+}
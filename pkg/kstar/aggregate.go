@@ -0,0 +1,477 @@
+package kstar
+
+import (
+	"fmt"
+	"slices"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"sigs.k8s.io/kustomize/kyaml/utils"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// sequenceMethods are the aggregation helpers of SequenceNode. The path
+// arguments are either dot-separated field paths, e.g. "metadata.namespace"
+// or "spec.containers.[app.kubernetes.io/name]", or callables returning the
+// value for an element. Sequences found along the path are flattened.
+//
+//nolint:gochecknoglobals
+var sequenceMethods = map[string]*starlark.Builtin{
+	"count":    starlark.NewBuiltin("count", sequenceCount),
+	"distinct": starlark.NewBuiltin("distinct", sequenceDistinct),
+	"flat_map": starlark.NewBuiltin("flat_map", sequenceFlatMap),
+	"group_by": starlark.NewBuiltin("group_by", sequenceGroupBy),
+	"sort_by":  starlark.NewBuiltin("sort_by", sequenceSortBy),
+	"sum":      starlark.NewBuiltin("sum", sequenceSum),
+}
+
+type nodePath func(th *starlark.Thread, value starlark.Value) ([]starlark.Value, error)
+
+func newNodePath(fnName string, path starlark.Value) (nodePath, error) {
+	switch path := path.(type) {
+	case starlark.NoneType:
+		return func(_ *starlark.Thread, value starlark.Value) ([]starlark.Value, error) {
+			return expandSequences([]starlark.Value{value}), nil
+		}, nil
+	case starlark.String:
+		parts := utils.SmarterPathSplitter(path.GoString(), ".")
+
+		return func(_ *starlark.Thread, value starlark.Value) ([]starlark.Value, error) {
+			return walkPath(value, parts)
+		}, nil
+	case starlark.Callable:
+		return func(th *starlark.Thread, value starlark.Value) ([]starlark.Value, error) {
+			result, err := starlark.Call(th, path, starlark.Tuple{value}, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			if result == starlark.None {
+				return nil, nil
+			}
+
+			return []starlark.Value{result}, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%s: %w: path %s", fnName, errUnsupportedType, path.Type())
+	}
+}
+
+func expandSequences(values []starlark.Value) []starlark.Value {
+	result := make([]starlark.Value, 0, len(values))
+
+	for _, value := range values {
+		seq, ok := value.(*SequenceNode)
+		if !ok {
+			result = append(result, value)
+			continue
+		}
+
+		for idx := range seq.len() {
+			result = append(result, seq.index(idx))
+		}
+	}
+
+	return result
+}
+
+func walkPath(value starlark.Value, parts []string) ([]starlark.Value, error) {
+	values := []starlark.Value{value}
+
+	for _, part := range parts {
+		next := []starlark.Value{}
+
+		for _, value := range expandSequences(values) {
+			attrs, ok := value.(starlark.HasAttrs)
+			if !ok {
+				continue
+			}
+
+			field, err := attrs.Attr(part)
+			if err != nil {
+				return nil, err
+			}
+
+			if field == nil || field == starlark.None {
+				continue
+			}
+
+			next = append(next, field)
+		}
+
+		values = next
+	}
+
+	return expandSequences(values), nil
+}
+
+func (node *SequenceNode) Attr(name string) (starlark.Value, error) {
+	method, found := sequenceMethods[name]
+	if !found {
+		return nil, nil
+	}
+
+	return method.BindReceiver(node), nil
+}
+
+func (node *SequenceNode) AttrNames() []string {
+	names := make([]string, 0, len(sequenceMethods))
+	for name := range sequenceMethods {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// values returns the path values of each element.
+func (node *SequenceNode) values(th *starlark.Thread, fnName string, pathArg starlark.Value) ([][]starlark.Value, error) {
+	path, err := newNodePath(fnName, pathArg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]starlark.Value, node.len())
+
+	for idx := range node.len() {
+		result[idx], err = path(th, node.index(idx))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (node *SequenceNode) subset(indices []int) *SequenceNode {
+	if node.elems == nil {
+		node.loadElements()
+	}
+
+	ynodes := make([]*yaml.Node, 0, len(indices))
+	elems := make([]starlark.Value, 0, len(indices))
+
+	for _, idx := range indices {
+		ynodes = append(ynodes, node.ynode.Content[idx])
+		elems = append(elems, node.elems[idx])
+	}
+
+	return &SequenceNode{
+		schema: node.schema,
+		ynode: &yaml.Node{
+			Kind:    yaml.SequenceNode,
+			Tag:     yaml.NodeTagSeq,
+			Content: ynodes,
+		},
+		elems: elems,
+	}
+}
+
+func sequenceCount(th *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value = starlark.None
+
+	node, _ := fn.Receiver().(*SequenceNode)
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0, &path)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == starlark.None {
+		return starlark.MakeInt(node.len()), nil
+	}
+
+	values, err := node.values(th, fn.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	count := 0
+
+	for _, elemValues := range values {
+		if len(elemValues) > 0 {
+			count++
+		}
+	}
+
+	return starlark.MakeInt(count), nil
+}
+
+func sequenceSum(th *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value = starlark.None
+
+	node, _ := fn.Receiver().(*SequenceNode)
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0, &path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := node.values(th, fn.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	var total starlark.Value
+
+	for _, value := range slices.Concat(values...) {
+		switch value.(type) {
+		case starlark.Int, starlark.Float, *Quantity:
+		default:
+			quantity, err := toQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+			}
+
+			value = &Quantity{quantity}
+		}
+
+		if total == nil {
+			total = value
+			continue
+		}
+
+		total, err = starlark.Binary(syntax.PLUS, total, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+	}
+
+	if total == nil {
+		return starlark.MakeInt(0), nil
+	}
+
+	return total, nil
+}
+
+func distinctKey(value starlark.Value) (string, error) {
+	switch value.(type) {
+	case *MappingNode, *SequenceNode:
+		ynode, err := FromStarlark(value)
+		if err != nil {
+			return "", err
+		}
+
+		text, err := yaml.String(ynode)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", errInvalid, err)
+		}
+
+		return value.Type() + ":" + text, nil
+	default:
+		return value.Type() + ":" + value.String(), nil
+	}
+}
+
+func sequenceDistinct(th *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value = starlark.None
+
+	node, _ := fn.Receiver().(*SequenceNode)
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0, &path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := node.values(th, fn.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	result := []starlark.Value{}
+
+	for _, value := range slices.Concat(values...) {
+		key, err := distinctKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+
+		if _, found := seen[key]; found {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		result = append(result, value)
+	}
+
+	return starlark.NewList(result), nil
+}
+
+func sequenceFlatMap(th *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value
+
+	node, _ := fn.Receiver().(*SequenceNode)
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := node.values(th, fn.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SequenceNode{
+		ynode: &yaml.Node{
+			Kind: yaml.SequenceNode,
+			Tag:  yaml.NodeTagSeq,
+		},
+		elems: []starlark.Value{},
+	}
+
+	for _, value := range slices.Concat(values...) {
+		switch value := value.(type) {
+		case *MappingNode:
+			result.ynode.Content = append(result.ynode.Content, value.ynode)
+			result.elems = append(result.elems, value)
+		case *SequenceNode:
+			result.ynode.Content = append(result.ynode.Content, value.ynode)
+			result.elems = append(result.elems, value)
+		default:
+			ynode, err := FromStarlark(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+			}
+
+			result.ynode.Content = append(result.ynode.Content, ynode)
+			result.elems = append(result.elems, FromYNode(ynode))
+		}
+	}
+
+	return result, nil
+}
+
+func sequenceGroupBy(th *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value
+
+	node, _ := fn.Receiver().(*SequenceNode)
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &path)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := node.values(th, fn.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []starlark.Value{}
+	groups := map[string][]int{}
+
+	for idx, elemValues := range values {
+		if len(elemValues) == 0 {
+			elemValues = []starlark.Value{starlark.None}
+		}
+
+		for _, key := range elemValues {
+			if _, isNode := key.(nodeValue); isNode {
+				return nil, fmt.Errorf("%s: %w: key %s", fn.Name(), errUnsupportedType, key.Type())
+			}
+
+			groupKey, err := distinctKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+			}
+
+			if _, found := groups[groupKey]; !found {
+				keys = append(keys, key)
+			}
+
+			if !slices.Contains(groups[groupKey], idx) {
+				groups[groupKey] = append(groups[groupKey], idx)
+			}
+		}
+	}
+
+	result := starlark.NewDict(len(keys))
+
+	for _, key := range keys {
+		groupKey, _ := distinctKey(key)
+
+		if err := result.SetKey(key, node.subset(groups[groupKey])); err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+	}
+
+	return result, nil
+}
+
+func sequenceSortBy(th *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var path starlark.Value
+
+	var reverse bool
+
+	node, _ := fn.Receiver().(*SequenceNode)
+
+	err := starlark.UnpackArgs(fn.Name(), args, kwargs, "path", &path, "reverse?", &reverse)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := node.values(th, fn.Name(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]int, node.len())
+	for idx := range indices {
+		indices[idx] = idx
+	}
+
+	var cmpErr error
+
+	slices.SortStableFunc(indices, func(a, b int) int {
+		result, err := compareKeys(values[a], values[b])
+		if err != nil && cmpErr == nil {
+			cmpErr = fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+
+		if reverse {
+			return -result
+		}
+
+		return result
+	})
+
+	if cmpErr != nil {
+		return nil, cmpErr
+	}
+
+	return node.subset(indices), nil
+}
+
+// compareKeys compares the first path values, the missing ones go first.
+func compareKeys(left, right []starlark.Value) (int, error) {
+	switch {
+	case len(left) == 0 && len(right) == 0:
+		return 0, nil
+	case len(left) == 0:
+		return -1, nil
+	case len(right) == 0:
+		return 1, nil
+	}
+
+	less, err := starlark.Compare(syntax.LT, left[0], right[0])
+	if err != nil {
+		return 0, err
+	}
+
+	if less {
+		return -1, nil
+	}
+
+	greater, err := starlark.Compare(syntax.GT, left[0], right[0])
+	if err != nil {
+		return 0, err
+	}
+
+	if greater {
+		return 1, nil
+	}
+
+	return 0, nil
+}
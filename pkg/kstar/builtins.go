@@ -0,0 +1,16 @@
+package kstar
+
+import "go.starlark.net/starlark"
+
+// Builtins returns the kstar functions and modules to be predeclared for the
+// scripts operating on resources.
+func Builtins() starlark.StringDict {
+	return starlark.StringDict{
+		fnMatchPattern: starlark.NewBuiltin(fnMatchPattern, newMatchPattern),
+		fnRegexPattern: starlark.NewBuiltin(fnRegexPattern, newRegexPattern),
+		fnQuantity:     starlark.NewBuiltin(fnQuantity, newQuantity),
+		fnDuration:     starlark.NewBuiltin(fnDuration, newDuration),
+		fnRecord:       starlark.NewBuiltin(fnRecord, newRecord),
+		moduleYAML:     YAMLModule,
+	}
+}
@@ -0,0 +1,49 @@
+package kstar
+
+import (
+	"maps"
+	"slices"
+
+	"go.starlark.net/starlark"
+)
+
+// Completions returns the attribute names of the value, including the schema
+// fields not set yet, e.g. for the shell completion.
+func Completions(value starlark.Value) []string {
+	names := map[string]struct{}{}
+
+	addNames := func(keys ...string) {
+		for _, key := range keys {
+			names[key] = struct{}{}
+		}
+	}
+
+	switch value := value.(type) {
+	case *MappingNode:
+		addNames(value.AttrNames()...)
+		addNames(schemaFields(value.schema)...)
+
+		if value.resources != nil {
+			addNames(slices.Collect(maps.Keys(resourceMethods))...)
+		}
+	case *SchemaIndex:
+		for name, ns := range value.aliases {
+			if ns != nil {
+				addNames(name)
+			}
+		}
+	case starlark.HasAttrs:
+		addNames(value.AttrNames()...)
+	}
+
+	return slices.Sorted(maps.Keys(names))
+}
+
+func schemaFields(ns *NodeSchema) []string {
+	ns = ns.Resolve()
+	if ns == nil || ns.schema == nil {
+		return nil
+	}
+
+	return slices.Collect(maps.Keys(ns.schema.Properties))
+}
@@ -0,0 +1,90 @@
+package kstar
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestCompletions(t *testing.T) {
+	idx := NewSchemaIndex(nil)
+	deployment := strings.Join([]string{
+		`apiVersion: apps/v1`,
+		`kind: Deployment`,
+		`metadata:`,
+		`  name: web`,
+		`spec:`,
+		`  replicas: 2`,
+	}, "\n")
+	resources := FromRNodes(idx, []*yaml.RNode{yaml.MustParse(deployment)})
+	resource := resources.index(0)
+
+	spec, err := resource.(starlark.HasAttrs).Attr("spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		value    starlark.Value
+		want     []string
+		wantNone []string
+	}{
+		{
+			name:     "resource",
+			value:    resource,
+			want:     []string{"apiVersion", "kind", "metadata", "spec", "status", "owners", "selected_by"},
+			wantNone: []string{"replicas"},
+		},
+		{
+			name:     "schema-fields",
+			value:    spec,
+			want:     []string{"replicas", "selector", "strategy", "template"},
+			wantNone: []string{"owners"},
+		},
+		{
+			name:  "schema-index",
+			value: idx,
+			want:  []string{"ConfigMap", "Deployment", "ObjectMeta"},
+		},
+		{
+			name:  "sequence",
+			value: resources,
+			want:  []string{"group_by", "sum"},
+		},
+		{
+			name:  "string",
+			value: starlark.String("web"),
+			want:  []string{"startswith"},
+		},
+		{
+			name:  "int",
+			value: starlark.MakeInt(1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Completions(test.value)
+
+			if !slices.IsSorted(got) {
+				t.Errorf("not sorted: %v", got)
+			}
+
+			for _, name := range test.want {
+				if !slices.Contains(got, name) {
+					t.Errorf("%s not found in %v", name, got)
+				}
+			}
+
+			for _, name := range test.wantNone {
+				if slices.Contains(got, name) {
+					t.Errorf("unexpected %s in %v", name, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package kstar
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	gvkExtension           = "x-kubernetes-group-version-kind"
+	listTypeExtension      = "x-kubernetes-list-type"
+	listMapKeysExtension   = "x-kubernetes-list-map-keys"
+	patchStrategyExtension = "x-kubernetes-patch-strategy"
+	mergeKeyExtension      = "x-kubernetes-patch-merge-key"
+	objectMetaRef          = "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+	crdKind                = "CustomResourceDefinition"
+)
+
+var errInvalidCRD = errors.New("invalid CRD")
+
+// gvkRef returns the reference of the resource type as set by FromRNode,
+// e.g. apps/v1.Deployment.
+func gvkRef(group, version, kind string) string {
+	apiVersion := version
+	if group != "" {
+		apiVersion = group + "/" + version
+	}
+
+	return strings.Trim(apiVersion+"."+kind, ".")
+}
+
+func schemaGVKs(schema *spec.Schema) []string {
+	ext, found := schema.Extensions[gvkExtension]
+	if !found {
+		return nil
+	}
+
+	items, _ := ext.([]any)
+	refs := []string{}
+
+	for _, item := range items {
+		gvk, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		group, _ := gvk["group"].(string)
+		version, _ := gvk["version"].(string)
+		kind, _ := gvk["kind"].(string)
+
+		refs = append(refs, gvkRef(group, version, kind))
+	}
+
+	return refs
+}
+
+// resourceRef returns the definition of the resource type if known.
+func (idx *SchemaIndex) resourceRef(apiVersion, kind string) refName {
+	ref := strings.Trim(apiVersion+"."+kind, ".")
+
+	if idx == nil {
+		return ref
+	}
+
+	if defRef, found := idx.gvkRefs[ref]; found {
+		return defRef
+	}
+
+	return ref
+}
+
+func crdDefinitionRef(group, version, kind string) refName {
+	parts := strings.Split(group, ".")
+	slices.Reverse(parts)
+
+	return strings.Join(slices.Concat(parts, []string{version, kind}), ".")
+}
+
+func crdVersionSchema(crd, version *yaml.RNode) (*spec.Schema, error) {
+	node, err := version.Pipe(yaml.Lookup("schema", "openAPIV3Schema"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidCRD, err)
+	}
+
+	if node == nil {
+		node, err = crd.Pipe(yaml.Lookup("spec", "validation", "openAPIV3Schema"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidCRD, err)
+		}
+	}
+
+	if node == nil {
+		return nil, nil
+	}
+
+	body, err := node.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidCRD, err)
+	}
+
+	schema := &spec.Schema{}
+	if err := schema.UnmarshalJSON(body); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidCRD, err)
+	}
+
+	addMergeKeys(schema)
+
+	if meta, found := schema.Properties["metadata"]; found && len(meta.Properties) == 0 {
+		schema.Properties["metadata"] = *spec.RefSchema(schemaDefinitionsPrefix + objectMetaRef)
+	}
+
+	return schema, nil
+}
+
+// addMergeKeys converts the structural schema map lists to the patch merge
+// extensions of the built-in types, used when merging lists.
+func addMergeKeys(schema *spec.Schema) {
+	if listType, _ := schema.Extensions.GetString(listTypeExtension); listType == "map" {
+		keys, _ := schema.Extensions[listMapKeysExtension].([]any)
+		if len(keys) > 0 {
+			schema.AddExtension(patchStrategyExtension, "merge")
+			schema.AddExtension(mergeKeyExtension, keys[0])
+		}
+	}
+
+	for name, prop := range schema.Properties {
+		addMergeKeys(&prop)
+		schema.Properties[name] = prop
+	}
+
+	if schema.Items != nil && schema.Items.Schema != nil {
+		addMergeKeys(schema.Items.Schema)
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		addMergeKeys(schema.AdditionalProperties.Schema)
+	}
+}
+
+// AddCRD registers the OpenAPI v3 schemas of the CustomResourceDefinition
+// versions, named after the reversed group, e.g. io.cert-manager.v1.Certificate.
+// The kind alias refers to the storage version.
+func (idx *SchemaIndex) AddCRD(crd *yaml.RNode) error {
+	if crd.GetKind() != crdKind {
+		return fmt.Errorf("%w: unexpected kind %s", errInvalidCRD, crd.GetKind())
+	}
+
+	group, _ := crd.GetString("spec.group")
+	kind, _ := crd.GetString("spec.names.kind")
+
+	if group == "" || kind == "" {
+		return fmt.Errorf("%w: %s: missing group or kind", errInvalidCRD, crd.GetName())
+	}
+
+	versions, err := crd.Pipe(yaml.Lookup("spec", "versions"))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidCRD, err)
+	}
+
+	elems := []*yaml.RNode{}
+	if versions != nil {
+		elems, _ = versions.Elements()
+	}
+
+	if version, _ := crd.GetString("spec.version"); len(elems) == 0 && version != "" {
+		elems = append(elems, yaml.NewMapRNode(&map[string]string{"name": version, "storage": "true"}))
+	}
+
+	if !idx.ownGlobal {
+		global := *idx.global
+		global.Definitions = maps.Clone(idx.global.Definitions)
+		idx.global = &global
+		idx.ownGlobal = true
+	}
+
+	for _, version := range elems {
+		name, _ := version.GetString("name")
+		storage, _ := version.Pipe(yaml.Lookup("storage"))
+
+		schema, err := crdVersionSchema(crd, version)
+		if err != nil {
+			return fmt.Errorf("%s/%s: %w", crd.GetName(), name, err)
+		}
+
+		if schema == nil {
+			continue
+		}
+
+		schema.AddExtension(gvkExtension, []any{
+			map[string]any{"group": group, "version": name, "kind": kind},
+		})
+
+		ref := crdDefinitionRef(group, name, kind)
+		idx.global.Definitions[ref] = *schema
+		idx.gvkRefs[gvkRef(group, name, kind)] = ref
+
+		if yaml.GetValue(storage) == "true" {
+			idx.addAlias(kind, ref)
+		}
+	}
+
+	clear(idx.cachedPaths)
+	clear(idx.refFields)
+
+	return nil
+}
+
+func (idx *SchemaIndex) addAlias(name string, ref refName) {
+	ns, dup := idx.aliases[name]
+
+	switch {
+	case dup && ns != nil && strings.HasPrefix(ns.ref, iok8s):
+		return
+	case dup && (ns == nil || ns.ref != ref):
+		idx.aliases[name] = nil
+	default:
+		idx.aliases[name] = &NodeSchema{
+			idx: idx,
+			ref: ref,
+		}
+	}
+}
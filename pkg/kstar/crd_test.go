@@ -0,0 +1,253 @@
+package kstar
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var testCRD = strings.Join([]string{
+	`apiVersion: apiextensions.k8s.io/v1`,
+	`kind: CustomResourceDefinition`,
+	`metadata:`,
+	`  name: certificates.cert-manager.io`,
+	`spec:`,
+	`  group: cert-manager.io`,
+	`  names:`,
+	`    kind: Certificate`,
+	`  versions:`,
+	`  - name: v1alpha1`,
+	`    served: true`,
+	`    storage: false`,
+	`    schema:`,
+	`      openAPIV3Schema:`,
+	`        type: object`,
+	`  - name: v1`,
+	`    served: true`,
+	`    storage: true`,
+	`    schema:`,
+	`      openAPIV3Schema:`,
+	`        type: object`,
+	`        properties:`,
+	`          apiVersion:`,
+	`            type: string`,
+	`          kind:`,
+	`            type: string`,
+	`          metadata:`,
+	`            type: object`,
+	`          spec:`,
+	`            type: object`,
+	`            properties:`,
+	`              secretName:`,
+	`                type: string`,
+	`              additionalOutputFormats:`,
+	`                type: array`,
+	`                x-kubernetes-list-type: map`,
+	`                x-kubernetes-list-map-keys:`,
+	`                - type`,
+	`                items:`,
+	`                  type: object`,
+	`                  properties:`,
+	`                    type:`,
+	`                      type: string`,
+}, "\n")
+
+func TestSchemaIndexAddCRD(t *testing.T) {
+	const (
+		v1Ref       = `io.cert-manager.v1.Certificate`
+		v1alpha1Ref = `io.cert-manager.v1alpha1.Certificate`
+	)
+
+	tests := []struct {
+		name      string
+		crd       string
+		wantRefs  map[string]refName // apiVersion to the definition
+		wantAlias refName
+		wantErr   wantErr
+	}{
+		{
+			name: "versions",
+			crd:  testCRD,
+			wantRefs: map[string]refName{
+				"cert-manager.io/v1":       v1Ref,
+				"cert-manager.io/v1alpha1": v1alpha1Ref,
+			},
+			wantAlias: v1Ref,
+		},
+		{
+			name: "v1beta1-validation",
+			crd: strings.Join([]string{
+				`apiVersion: apiextensions.k8s.io/v1beta1`,
+				`kind: CustomResourceDefinition`,
+				`metadata:`,
+				`  name: certificates.cert-manager.io`,
+				`spec:`,
+				`  group: cert-manager.io`,
+				`  version: v1`,
+				`  names:`,
+				`    kind: Certificate`,
+				`  validation:`,
+				`    openAPIV3Schema:`,
+				`      type: object`,
+			}, "\n"),
+			wantRefs: map[string]refName{
+				"cert-manager.io/v1": v1Ref,
+			},
+			wantAlias: v1Ref,
+		},
+		{
+			name: "not-crd",
+			crd: strings.Join([]string{
+				`apiVersion: v1`,
+				`kind: ConfigMap`,
+				`metadata:`,
+				`  name: certificates.cert-manager.io`,
+			}, "\n"),
+			wantErr: true,
+		},
+		{
+			name: "missing-group",
+			crd: strings.Join([]string{
+				`apiVersion: apiextensions.k8s.io/v1`,
+				`kind: CustomResourceDefinition`,
+				`metadata:`,
+				`  name: certificates`,
+				`spec:`,
+				`  names:`,
+				`    kind: Certificate`,
+			}, "\n"),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			idx := NewSchemaIndex(nil)
+
+			err := idx.AddCRD(yaml.MustParse(test.crd))
+			if test.wantErr.check(t, err) {
+				return
+			}
+
+			for apiVersion, wantRef := range test.wantRefs {
+				if diff := cmp.Diff(wantRef, idx.resourceRef(apiVersion, "Certificate")); diff != "" {
+					t.Errorf("%s: -want +got:\n%s", apiVersion, diff)
+				}
+
+				if _, found := idx.global.Definitions[wantRef]; !found {
+					t.Errorf("definition %s not found", wantRef)
+				}
+
+				if _, found := openapi.Schema().Definitions[wantRef]; found {
+					t.Errorf("definition %s added to the global schema", wantRef)
+				}
+			}
+
+			if diff := cmp.Diff(test.wantAlias, idx.aliases["Certificate"].ref); diff != "" {
+				t.Errorf("alias: -want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCRDResources(t *testing.T) {
+	cmpOpts := slices.Concat(commonCmpOpts, cmp.Options{
+		cmpopts.IgnoreFields(yaml.Node{}, "Line", "Style", "Column", "Tag"),
+	})
+	cert := strings.Join([]string{
+		`apiVersion: cert-manager.io/v1`,
+		`kind: Certificate`,
+		`metadata:`,
+		`  name: web`,
+		`spec:`,
+		`  secretName: web-tls`,
+		`  additionalOutputFormats:`,
+		`  - type: DER`,
+		`  - type: CombinedPEM`,
+	}, "\n")
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr wantErr
+	}{
+		{
+			name:   "constructor",
+			script: `resources[0] = schema.Certificate({"metadata": {"name": "api"}})`,
+			want: strings.Join([]string{
+				`metadata:`,
+				`  name: api`,
+			}, "\n"),
+		},
+		{
+			name:   "merge-metadata",
+			script: `resources[0].metadata += schema.ObjectMeta({"labels": {"app": "web"}})`,
+			want: strings.Join([]string{
+				`apiVersion: cert-manager.io/v1`,
+				`kind: Certificate`,
+				`metadata:`,
+				`  name: web`,
+				`  labels:`,
+				`    app: web`,
+				`spec:`,
+				`  secretName: web-tls`,
+				`  additionalOutputFormats:`,
+				`  - type: DER`,
+				`  - type: CombinedPEM`,
+			}, "\n"),
+		},
+		{
+			name:   "merge-list-map",
+			script: `resources[0].spec += {"additionalOutputFormats": [{"type": "DER", "suffix": "der"}]}`,
+			want: strings.Join([]string{
+				`apiVersion: cert-manager.io/v1`,
+				`kind: Certificate`,
+				`metadata:`,
+				`  name: web`,
+				`spec:`,
+				`  secretName: web-tls`,
+				`  additionalOutputFormats:`,
+				`  - type: DER`,
+				`    suffix: der`,
+				`  - type: CombinedPEM`,
+			}, "\n"),
+		},
+		{
+			name:    "merge-wrong-type",
+			script:  `resources[0].metadata += schema.Container({"name": "app"})`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		idx := NewSchemaIndex(nil)
+		if err := idx.AddCRD(yaml.MustParse(testCRD)); err != nil {
+			t.Fatal(err)
+		}
+
+		resources := FromRNodes(idx, []*yaml.RNode{yaml.MustParse(cert)})
+
+		runStarlarkTest(t, test.name,
+			test.script,
+			StringDict{
+				"resources": resources,
+				"schema":    idx,
+			},
+			false, test.wantErr,
+			func(t *testing.T, _ StringDict) {
+				got := resources.ynode.Content[0]
+				want := yaml.MustParse(test.want).YNode()
+
+				if diff := cmp.Diff(want, got, cmpOpts...); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
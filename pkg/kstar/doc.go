@@ -23,6 +23,14 @@
 // ignored. Otherwise using the short form when multiple matches exist will
 // cause an error.
 //
+// CRDs registered via SchemaIndex.AddCRD are named after the reversed group,
+// e.g. `schema["io.cert-manager.v1.Certificate"]`, with the short form
+// referring to the storage version.
+//
+// With SchemaIndex.SetStrict enabled the values assigned to the resource
+// fields and list elements are checked against the schema, rejecting unknown
+// fields, wrong scalar types and mismatching lists and maps.
+//
 // ## `match`
 //
 // Creates a shell-like pattern for matching, e.g.:
@@ -33,7 +41,59 @@
 //
 // ## `regex`
 //
-// Creates a regex pattern for matching, similar to `match`.
+// Creates a regex pattern for matching, similar to `match`, e.g.:
+//
+//		regex("^my-", "my-app")
+//		"app:v1" in regex("v[0-9]+$")
+//
+// ## `quantity`
+//
+// Creates a K8s resource quantity from a string, a number or a scalar node,
+// supporting arithmetic and comparison, e.g.:
+//
+//		quantity("500m") + quantity("1") == quantity("1500m")
+//		quantity("1Gi") * 2
+//		quantity("1").milli_value
+//
+// ## `duration`
+//
+// Creates a `time.duration` from a Go duration string or a number of
+// seconds, e.g.:
+//
+//		duration("1m30s") > duration(60)
+//
+// ## `yaml`
+//
+// Encodes values to YAML and decodes YAML documents into nodes, e.g.:
+//
+//		yaml.decode(text).spec.replicas
+//		yaml.encode({"replicas": 3})
+//
+// # Aggregations
+//
+// Sequences provide `count`, `sum`, `distinct`, `group_by`, `sort_by` and
+// `flat_map` methods accepting a dot-separated path or a function, e.g.:
+//
+//	resources.group_by("metadata.namespace")
+//	resources.sum("spec.containers.resources.requests.memory")
+//	resources.flat_map("spec.containers").distinct("image")
+//	resources.sort_by(lambda r: r.metadata.name, reverse=True)
+//
+// # Relationships
+//
+// The items of `resources` provide methods returning the related items of
+// the same sequence, unless the resource has a field with the same name:
+//
+//   - `owners()`, `children()` - via `metadata.ownerReferences`
+//   - `selects()`, `selected_by()` - via the label selectors of Services,
+//     NetworkPolicies, PodDisruptionBudgets and workloads, matching Pods and
+//     pod templates in the same namespace
+//   - `references()`, `referenced_by()` - via volumes, `env`, `envFrom`,
+//     `serviceAccountName` and `imagePullSecrets` of the pod specs
+//
+// E.g.:
+//
+//	resources(lambda r: r.kind == "Service" and not r.selects())
 //
 // # Resource operations
 //
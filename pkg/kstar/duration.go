@@ -0,0 +1,59 @@
+package kstar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sltime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+)
+
+const fnDuration = "duration"
+
+var errInvalidDuration = errors.New("invalid duration")
+
+// toDuration converts the value to time.duration, the numbers are seconds as
+// in K8s *Seconds fields, the strings are Go durations as in metav1.Duration.
+func toDuration(value starlark.Value) (sltime.Duration, error) {
+	switch v := value.(type) {
+	case sltime.Duration:
+		return v, nil
+	case *ScalarNode:
+		scalar, err := v.Value()
+		if err != nil {
+			return 0, err
+		}
+
+		return toDuration(scalar)
+	case starlark.String:
+		duration, err := time.ParseDuration(v.GoString())
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", errInvalidDuration, v.GoString())
+		}
+
+		return sltime.Duration(duration), nil
+	case starlark.Int, starlark.Float:
+		seconds, _ := starlark.AsFloat(v)
+
+		return sltime.Duration(seconds * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("%w: type %s not supported", errInvalidDuration, value.Type())
+	}
+}
+
+func newDuration(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Value
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := toDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	return duration, nil
+}
@@ -19,6 +19,9 @@ type MappingNode struct {
 	schema *NodeSchema
 	ynode  *yaml.Node
 	fields map[string]starlark.Value
+
+	// resources containing the node, set for the top-level resources
+	resources *SequenceNode
 }
 
 var (
@@ -48,9 +51,9 @@ func toMappingNode(value starlark.Value) (*MappingNode, bool) {
 	return node, true
 }
 
+// String returns the node in the YAML flow style, as the dicts are printed.
 func (node *MappingNode) String() string {
-	//REVISIT: maybe return json
-	panic(errNotImplemented)
+	return flowString(node.ynode, node.Type())
 }
 
 func (node *MappingNode) Type() string {
@@ -66,7 +69,7 @@ func (node *MappingNode) Truth() starlark.Bool {
 }
 
 func (node *MappingNode) Hash() (uint32, error) {
-	panic(errNotImplemented)
+	return 0, fmt.Errorf("%w: %s", errUnhashable, node.Type())
 }
 
 func (node *MappingNode) setSchema(ns *NodeSchema) {
@@ -102,6 +105,10 @@ func (node *MappingNode) field(name string) (starlark.Value, error) {
 	}
 
 	if scalar, ok := field.(*ScalarNode); ok {
+		if scalar.isNull() {
+			return starlark.None, nil
+		}
+
 		return scalar.Value()
 	}
 
@@ -109,6 +116,16 @@ func (node *MappingNode) field(name string) (starlark.Value, error) {
 }
 
 func (node *MappingNode) Attr(name string) (starlark.Value, error) {
+	if method, found := resourceMethods[name]; found && node.resources != nil {
+		if node.fields == nil {
+			node.loadFields()
+		}
+
+		if _, isField := node.fields[name]; !isField {
+			return method.BindReceiver(node), nil
+		}
+	}
+
 	return node.field(name)
 }
 
@@ -130,6 +147,10 @@ func (node *MappingNode) SetField(name string, value starlark.Value) error {
 		expr, ok := value.(*nodeExpr)
 		if ok {
 			if expr.target == field {
+				if err := node.checkExpr(name, expr); err != nil {
+					return err
+				}
+
 				return expr.evaluate()
 			}
 		}
@@ -140,6 +161,10 @@ func (node *MappingNode) SetField(name string, value starlark.Value) error {
 		return fmt.Errorf("unable to set %q: %w", name, err)
 	}
 
+	if err := node.schema.checkField(name, newYNode); err != nil {
+		return err
+	}
+
 	if node.fields != nil {
 		node.fields[name] = FromYNode(newYNode)
 	}
@@ -155,6 +180,26 @@ func (node *MappingNode) SetField(name string, value starlark.Value) error {
 	})
 }
 
+// checkExpr checks the result of the in-place expression assigned to the
+// field, e.g. it.spec += {...}, before the field is modified.
+func (node *MappingNode) checkExpr(name string, expr *nodeExpr) error {
+	if !node.schema.strict() {
+		return nil
+	}
+
+	result, err := (&nodeExpr{target: expr.target, ops: expr.ops}).materialize()
+	if err != nil {
+		return err
+	}
+
+	ynode, err := FromStarlark(result)
+	if err != nil {
+		return fmt.Errorf("unable to set %q: %w", name, err)
+	}
+
+	return node.schema.checkField(name, ynode)
+}
+
 func (node *MappingNode) Get(key starlark.Value) (_ starlark.Value, found bool, _ error) {
 	switch key := key.(type) {
 	case starlark.String:
@@ -162,7 +207,7 @@ func (node *MappingNode) Get(key starlark.Value) (_ starlark.Value, found bool,
 		return value, true, err
 	case *MappingNode:
 		//TODO: add match lookup
-		panic(errNotImplemented)
+		return nil, false, fmt.Errorf("%w: mapping keys", ErrNotImplemented)
 	default:
 		return nil, false, fmt.Errorf(
 			"%w: %q",
@@ -180,7 +225,7 @@ func (node *MappingNode) SetKey(key, value starlark.Value) error {
 		return node.SetField(field, value)
 	case *MappingNode:
 		//TODO: add match lookup
-		panic(errNotImplemented)
+		return fmt.Errorf("%w: mapping keys", ErrNotImplemented)
 	default:
 		return fmt.Errorf(
 			"%w: %q",
@@ -229,7 +274,9 @@ func (*MappingNode) exprOp(op syntax.Token, value starlark.Value, side starlark.
 			return left, nil
 		}
 	case syntax.MINUS:
-		panic(errNotImplemented)
+		return func(nodeExprTarget) (nodeExprTarget, error) {
+			return nil, fmt.Errorf("%w: %s of mappings", ErrNotImplemented, op)
+		}
 	default:
 		return nil
 	}
@@ -54,6 +54,16 @@ func TestMappingHasAttrs(t *testing.T) {
 			expr: "bool(node.metadata.annotations)",
 			want: starlark.False,
 		},
+		{
+			name: "string",
+			expr: "str(node.metadata.labels)",
+			want: starlark.String("{app: app1}"),
+		},
+		{
+			name:    "unhashable",
+			expr:    "{node: 1}",
+			wantErr: true,
+		},
 		{
 			name: "dir",
 			expr: "dir(node)",
@@ -297,9 +307,9 @@ func TestMappingHasGet(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:      "mapping-key",
-			expr:      `node[node]`,
-			wantPanic: true,
+			name:    "mapping-key",
+			expr:    `node[node]`,
+			wantErr: true,
 		},
 	}
 
@@ -495,9 +505,9 @@ func TestMappingHasSetKey(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:      "set-mapping-key",
-			script:    `node[node] = "new-value"`,
-			wantPanic: true,
+			name:    "set-mapping-key",
+			script:  `node[node] = "new-value"`,
+			wantErr: true,
 		},
 	}
 
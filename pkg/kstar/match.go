@@ -69,23 +69,44 @@ func (match *matchPattern) Unary(op syntax.Token) (starlark.Value, error) {
 }
 
 func (match *matchPattern) apply(value starlark.Value) (starlark.Value, error) {
-	switch v := value.(type) {
-	case starlark.Iterable:
-		return match.applyIterable(v)
-	default:
-		return match.applySingle(value)
-	}
+	return applyPattern(match, fnMatchPattern, func(text string) bool {
+		ok, _ := path.Match(match.pattern, text)
+
+		return ok != match.inverse
+	}, value)
 }
 
-func (match *matchPattern) applyIterable(value starlark.Iterable) (starlark.Value, error) {
+// applyPattern returns the string if it matches or None otherwise, and the
+// matching strings of the iterable.
+//
+//nolint:lll
+func applyPattern(pattern starlark.Value, fnName string, matches func(string) bool, value starlark.Value) (starlark.Value, error) {
+	single := func(value starlark.Value) (starlark.Value, error) {
+		text, ok := value.(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("%s: type %s not supported", fnName, value.Type())
+		}
+
+		if matches(text.GoString()) {
+			return value, nil
+		}
+
+		return starlark.None, nil
+	}
+
+	iterable, ok := value.(starlark.Iterable)
+	if !ok {
+		return single(value)
+	}
+
 	results := starlark.NewList(nil)
 
-	iter := value.Iterate()
+	iter := iterable.Iterate()
 	defer iter.Done()
 
 	var item starlark.Value
 	for iter.Next(&item) {
-		matched, err := match.applySingle(item)
+		matched, err := single(item)
 		if err != nil {
 			return nil, err
 		}
@@ -96,26 +117,13 @@ func (match *matchPattern) applyIterable(value starlark.Iterable) (starlark.Valu
 
 		err = results.Append(matched)
 		if err != nil {
-			return nil, fmt.Errorf("%s: %w", match.String(), err)
+			return nil, fmt.Errorf("%s: %w", pattern.String(), err)
 		}
 	}
 
 	return results, nil
 }
 
-func (match *matchPattern) applySingle(value starlark.Value) (starlark.Value, error) {
-	switch v := value.(type) {
-	case starlark.String:
-		ok, _ := path.Match(match.pattern, v.GoString())
-		if ok != match.inverse {
-			return value, nil
-		}
-		return starlark.None, nil
-	default:
-		return nil, fmt.Errorf("%s: type %s not supported", fnMatchPattern, value.Type())
-	}
-}
-
 func newMatchPattern(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var pattern string
 	var value starlark.Value
@@ -6,12 +6,18 @@ import (
 	"strconv"
 	"strings"
 
+	sltime "go.starlark.net/lib/time"
 	"go.starlark.net/starlark"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 var (
-	errNotImplemented  = errors.New("not implemented")
+	// ErrNotImplemented is returned, or panicked with by the methods unable
+	// to return errors, for the values not supported yet, e.g. the YAML
+	// alias nodes.
+	ErrNotImplemented = errors.New("not implemented")
+
+	errUnhashable      = errors.New("unhashable type")
 	errUnsupportedType = errors.New("unsupported type")
 	errInvalid         = errors.New("invalid value")
 )
@@ -22,30 +28,32 @@ type nodeValue interface {
 }
 
 func FromRNodes(idx *SchemaIndex, rnodes []*yaml.RNode) *SequenceNode {
-	ref := ""
-	refs := map[string]struct{}{}
-	ynode := yaml.NewListRNode().YNode()
-	elems := []starlark.Value{}
+	resources := &SequenceNode{
+		ynode: yaml.NewListRNode().YNode(),
+		elems: []starlark.Value{},
+	}
 
 	for _, rnode := range rnodes {
-		ref = strings.Trim(rnode.GetApiVersion()+"."+rnode.GetKind(), ".")
-		refs[ref] = struct{}{}
+		node := FromRNode(idx, rnode)
+		node.resources = resources
 
-		schema := &NodeSchema{
-			idx: idx,
-			ref: ref,
-		}
+		resources.ynode.Content = append(resources.ynode.Content, rnode.YNode())
+		resources.elems = append(resources.elems, node)
+	}
+
+	return resources
+}
 
-		ynode.Content = append(ynode.Content, rnode.YNode())
-		elems = append(elems, &MappingNode{
-			schema: schema,
-			ynode:  rnode.YNode(),
-		})
+func FromRNode(idx *SchemaIndex, rnode *yaml.RNode) *MappingNode {
+	schema := &NodeSchema{
+		idx:      idx,
+		ref:      idx.resourceRef(rnode.GetApiVersion(), rnode.GetKind()),
+		resource: rnode.YNode(),
 	}
 
-	return &SequenceNode{
-		ynode: ynode,
-		elems: elems,
+	return &MappingNode{
+		schema: schema,
+		ynode:  rnode.YNode(),
 	}
 }
 
@@ -62,7 +70,7 @@ func FromYNode(ynode *yaml.Node) nodeValue {
 	case yaml.ScalarNode:
 		return &ScalarNode{ynode: ynode}
 	default:
-		panic(errNotImplemented)
+		panic(fmt.Errorf("%w: YAML node kind %d", ErrNotImplemented, kind))
 	}
 }
 
@@ -134,6 +142,10 @@ func FromStarlark(value starlark.Value) (*yaml.Node, error) {
 		return yaml.CopyYNode(value.ynode), nil
 	case *SequenceNode:
 		return yaml.CopyYNode(value.ynode), nil
+	case *Quantity:
+		return yaml.NewStringRNode(value.String()).YNode(), nil
+	case sltime.Duration:
+		return yaml.NewStringRNode(value.String()).YNode(), nil
 	case starlark.String:
 		return &yaml.Node{
 			Kind:  yaml.ScalarNode,
@@ -156,6 +168,8 @@ func FromStarlark(value starlark.Value) (*yaml.Node, error) {
 		ynode := yaml.NewScalarRNode(strconv.FormatBool(bool(value))).YNode()
 		ynode.Tag = yaml.NodeTagBool
 		return ynode, nil
+	case *Record:
+		return value.node()
 	case starlark.IterableMapping:
 		return fromStarlarkEntries(value)
 	case starlark.Iterable:
@@ -166,3 +180,17 @@ func FromStarlark(value starlark.Value) (*yaml.Node, error) {
 		return nil, fmt.Errorf("%w: %s", errUnsupportedType, value.Type())
 	}
 }
+
+// flowString returns the node in the YAML flow style, or the type of the
+// value if the node is not serializable.
+func flowString(ynode *yaml.Node, valueType string) string {
+	flow := yaml.CopyYNode(ynode)
+	flow.Style = yaml.FlowStyle
+
+	text, err := yaml.String(flow)
+	if err != nil {
+		return "<" + valueType + ">"
+	}
+
+	return strings.TrimSuffix(text, "\n")
+}
@@ -0,0 +1,232 @@
+package kstar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"gopkg.in/inf.v0"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	fnQuantity = "quantity"
+	milliScale = 3
+)
+
+var errInvalidQuantity = errors.New("invalid quantity")
+
+// Quantity is a K8s resource quantity, e.g. quantity("500m").
+type Quantity struct {
+	quantity resource.Quantity
+}
+
+var (
+	_ starlark.Value      = new(Quantity)
+	_ starlark.Comparable = new(Quantity)
+	_ starlark.HasBinary  = new(Quantity)
+	_ starlark.HasUnary   = new(Quantity)
+	_ starlark.HasAttrs   = new(Quantity)
+)
+
+func (q *Quantity) String() string {
+	return q.quantity.String()
+}
+
+func (q *Quantity) Type() string {
+	return fnQuantity
+}
+
+func (q *Quantity) Freeze() {
+}
+
+func (q *Quantity) Truth() starlark.Bool {
+	return !starlark.Bool(q.quantity.IsZero())
+}
+
+func (q *Quantity) Hash() (uint32, error) {
+	return starlark.MakeInt64(q.quantity.MilliValue()).Hash()
+}
+
+func (q *Quantity) CompareSameType(op syntax.Token, y starlark.Value, _ int) (bool, error) {
+	other, _ := y.(*Quantity)
+
+	return compareOp(op, q.quantity.Cmp(other.quantity))
+}
+
+func (q *Quantity) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "value":
+		return starlark.MakeInt64(q.quantity.Value()), nil
+	case "milli_value":
+		return starlark.MakeInt64(q.quantity.MilliValue()), nil
+	case "float":
+		return starlark.Float(q.quantity.AsApproximateFloat64()), nil
+	default:
+		return nil, nil
+	}
+}
+
+func (q *Quantity) AttrNames() []string {
+	return []string{"float", "milli_value", "value"}
+}
+
+func (q *Quantity) Unary(op syntax.Token) (starlark.Value, error) {
+	switch op {
+	case syntax.MINUS:
+		result := q.quantity.DeepCopy()
+		result.Neg()
+
+		return &Quantity{result}, nil
+	case syntax.PLUS:
+		return q, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (q *Quantity) Binary(op syntax.Token, value starlark.Value, side starlark.Side) (starlark.Value, error) {
+	switch op {
+	case syntax.PLUS, syntax.MINUS:
+		other, err := toQuantity(value)
+		if err != nil {
+			return nil, nil
+		}
+
+		left, right := q.quantity.DeepCopy(), other
+		if side == starlark.Right {
+			left, right = right, left
+		}
+
+		if op == syntax.PLUS {
+			left.Add(right)
+		} else {
+			left.Sub(right)
+		}
+
+		return &Quantity{left}, nil
+	case syntax.STAR:
+		factor, ok := starlark.AsFloat(value)
+		if !ok {
+			return nil, nil
+		}
+
+		return q.scale(factor, false)
+	case syntax.SLASH:
+		if side == starlark.Right {
+			return nil, nil
+		}
+
+		if other, ok := value.(*Quantity); ok {
+			if other.quantity.IsZero() {
+				return nil, fmt.Errorf("%s: division by zero", fnQuantity)
+			}
+
+			return starlark.Float(q.quantity.AsApproximateFloat64() / other.quantity.AsApproximateFloat64()), nil
+		}
+
+		divisor, ok := starlark.AsFloat(value)
+		if !ok {
+			return nil, nil
+		}
+
+		if divisor == 0 {
+			return nil, fmt.Errorf("%s: division by zero", fnQuantity)
+		}
+
+		return q.scale(divisor, true)
+	default:
+		return nil, nil
+	}
+}
+
+// scale multiplies or divides the quantity by the factor, rounded to milli
+// units. The arithmetic is decimal, so the large quantities do not overflow.
+func (q *Quantity) scale(factor float64, divide bool) (*Quantity, error) {
+	if math.IsNaN(factor) || math.IsInf(factor, 0) {
+		return nil, fmt.Errorf("%w: factor %v", errInvalidQuantity, factor)
+	}
+
+	factorDec, ok := new(inf.Dec).SetString(strconv.FormatFloat(factor, 'f', -1, 64))
+	if !ok {
+		return nil, fmt.Errorf("%w: factor %v", errInvalidQuantity, factor)
+	}
+
+	result := new(inf.Dec)
+	if divide {
+		result.QuoRound(q.quantity.AsDec(), factorDec, milliScale, inf.RoundHalfUp)
+	} else {
+		result.Round(result.Mul(q.quantity.AsDec(), factorDec), milliScale, inf.RoundHalfUp)
+	}
+
+	return &Quantity{*resource.NewDecimalQuantity(*result, q.quantity.Format)}, nil
+}
+
+func toQuantity(value starlark.Value) (resource.Quantity, error) {
+	switch v := value.(type) {
+	case *Quantity:
+		return v.quantity.DeepCopy(), nil
+	case *ScalarNode:
+		return parseQuantity(v.ynode.Value)
+	case starlark.String:
+		return parseQuantity(v.GoString())
+	case starlark.Int:
+		i, ok := v.Int64()
+		if !ok {
+			return resource.Quantity{}, fmt.Errorf("%w: %s", errInvalidQuantity, v)
+		}
+
+		return *resource.NewQuantity(i, resource.DecimalSI), nil
+	case starlark.Float:
+		return parseQuantity(strconv.FormatFloat(float64(v), 'f', -1, 64))
+	default:
+		return resource.Quantity{}, fmt.Errorf("%w: type %s not supported", errInvalidQuantity, value.Type())
+	}
+}
+
+func parseQuantity(value string) (resource.Quantity, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("%w: %q", errInvalidQuantity, value)
+	}
+
+	return quantity, nil
+}
+
+func newQuantity(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Value
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := toQuantity(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	return &Quantity{quantity}, nil
+}
+
+func compareOp(op syntax.Token, cmp int) (bool, error) {
+	switch op {
+	case syntax.EQL:
+		return cmp == 0, nil
+	case syntax.NEQ:
+		return cmp != 0, nil
+	case syntax.LT:
+		return cmp < 0, nil
+	case syntax.LE:
+		return cmp <= 0, nil
+	case syntax.GT:
+		return cmp > 0, nil
+	case syntax.GE:
+		return cmp >= 0, nil
+	default:
+		return false, fmt.Errorf("%w: %s", ErrNotImplemented, op)
+	}
+}
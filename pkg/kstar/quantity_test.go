@@ -0,0 +1,172 @@
+package kstar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestQuantity(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr wantErr
+	}{
+		{
+			name: "ctor-string",
+			expr: `quantity("500m")`,
+			want: `500m`,
+		},
+		{
+			name: "ctor-int",
+			expr: `quantity(2)`,
+			want: `2`,
+		},
+		{
+			name: "ctor-float",
+			expr: `quantity(0.25)`,
+			want: `250m`,
+		},
+		{
+			name: "ctor-scalar-node",
+			expr: `quantity(node)`,
+			want: `128Mi`,
+		},
+		{
+			name:    "ctor-err",
+			expr:    `quantity("abc")`,
+			wantErr: true,
+		},
+		{
+			name: "add",
+			expr: `quantity("500m") + quantity("1")`,
+			want: `1500m`,
+		},
+		{
+			name: "add-string",
+			expr: `"1Gi" + quantity("512Mi")`,
+			want: `1536Mi`,
+		},
+		{
+			name: "sub",
+			expr: `quantity("1") - "250m"`,
+			want: `750m`,
+		},
+		{
+			name: "neg",
+			expr: `-quantity("1")`,
+			want: `-1`,
+		},
+		{
+			name: "mul",
+			expr: `quantity("250m") * 3`,
+			want: `750m`,
+		},
+		{
+			name: "div",
+			expr: `quantity("1") / 4`,
+			want: `250m`,
+		},
+		{
+			name: "mul-large",
+			expr: `quantity("1Ei") * 2`,
+			want: `2Ei`,
+		},
+		{
+			name: "div-large",
+			expr: `quantity("4Ei") / 2`,
+			want: `2Ei`,
+		},
+		{
+			name: "div-third",
+			expr: `quantity("1") / 3`,
+			want: `333m`,
+		},
+		{
+			name:    "mul-inf",
+			expr:    `quantity("1") * float("inf")`,
+			wantErr: true,
+		},
+		{
+			name: "div-quantity",
+			expr: `quantity("500m") / quantity("2")`,
+			want: `0.25`,
+		},
+		{
+			name:    "div-zero",
+			expr:    `quantity("1") / 0`,
+			wantErr: true,
+		},
+		{
+			name: "compare",
+			expr: `[quantity("1000m") == quantity("1"), quantity("1Gi") > quantity("1G"), quantity("1") < quantity("2")]`,
+			want: `[True, True, True]`,
+		},
+		{
+			name: "sum",
+			expr: `sum_quantities(["100m", "200m", "1"])`,
+			want: `1300m`,
+		},
+		{
+			name: "attrs",
+			expr: `[quantity("1500m").value, quantity("1500m").milli_value, quantity("1500m").float]`,
+			want: `[2, 1500, 1.5]`,
+		},
+		{
+			name: "dict-key",
+			expr: `{quantity("1000m"): "a"}[quantity("1")]`,
+			want: `"a"`,
+		},
+		{
+			name: "duration-string",
+			expr: `duration("1h30m")`,
+			want: `1h30m0s`,
+		},
+		{
+			name: "duration-seconds",
+			expr: `duration(90)`,
+			want: `1m30s`,
+		},
+		{
+			name: "duration-compare",
+			expr: `duration("2m") > duration(90)`,
+			want: `True`,
+		},
+		{
+			name:    "duration-err",
+			expr:    `duration("soon")`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		const resultVar = "result"
+		runStarlarkTest(t, test.name,
+			fmt.Sprintf(`
+def sum_quantities(values):
+    total = quantity(0)
+    for value in values:
+        total += value
+    return total
+
+%s = repr(%s)
+`, resultVar, test.expr),
+			StringDict{
+				fnQuantity: starlark.NewBuiltin(fnQuantity, newQuantity),
+				fnDuration: starlark.NewBuiltin(fnDuration, newDuration),
+				"node":     FromYNode(yaml.NewStringRNode("128Mi").YNode()),
+			},
+			false, test.wantErr,
+			func(t *testing.T, gotAll StringDict) {
+				got := gotAll[resultVar]
+				if diff := cmp.Diff(starlark.String(test.want), got); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,146 @@
+package kstar
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const fnRecord = "record"
+
+var errInvalidRecord = errors.New("invalid record")
+
+// Record is the structured output of the scripts, listed by the columnar
+// outputs instead of being grouped as a resource, e.g.
+// output.append(record(name=it.metadata.name, replicas=it.spec.replicas)).
+// The fields keep the order of the arguments.
+type Record struct {
+	fields *starlark.Dict
+}
+
+var (
+	_ starlark.Value           = new(Record)
+	_ starlark.IterableMapping = new(Record)
+	_ starlark.HasAttrs        = new(Record)
+)
+
+// NewRecord returns the record with the fields of the mapping node.
+func NewRecord(fields *yaml.RNode) (*Record, error) {
+	content := fields.YNode().Content
+	record := &Record{fields: starlark.NewDict(len(content) / 2)}
+
+	for idx := range len(content) / 2 {
+		key := content[idx*2]
+
+		value, err := recordValue(FromYNode(content[idx*2+1]))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := record.fields.SetKey(starlark.String(key.Value), value); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	return record, nil
+}
+
+// recordValue unwraps the scalar nodes, so the records hold the plain values.
+func recordValue(value starlark.Value) (starlark.Value, error) {
+	if scalar, ok := value.(*ScalarNode); ok {
+		return scalar.Value()
+	}
+
+	return value, nil
+}
+
+func newRecord(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	value, err := starlark.Call(thread, starlark.Universe["dict"], args, kwargs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	fields, _ := value.(*starlark.Dict)
+
+	for _, item := range fields.Items() {
+		if _, ok := item[0].(starlark.String); !ok {
+			return nil, fmt.Errorf("%s: %w: field name %s is not a string", fn.Name(), errInvalidRecord, item[0])
+		}
+
+		value, err := recordValue(item[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+
+		if err := fields.SetKey(item[0], value); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	return &Record{fields: fields}, nil
+}
+
+func (r *Record) String() string {
+	return fnRecord + "(" + r.fields.String() + ")"
+}
+
+func (r *Record) Type() string {
+	return fnRecord
+}
+
+func (r *Record) Freeze() {
+	r.fields.Freeze()
+}
+
+func (r *Record) Truth() starlark.Bool {
+	return r.fields.Len() > 0
+}
+
+func (r *Record) Hash() (uint32, error) {
+	return 0, fmt.Errorf("%w: %s", errUnsupportedType, fnRecord)
+}
+
+func (r *Record) Get(key starlark.Value) (starlark.Value, bool, error) {
+	return r.fields.Get(key) //nolint:wrapcheck
+}
+
+func (r *Record) Items() []starlark.Tuple {
+	return r.fields.Items()
+}
+
+func (r *Record) Iterate() starlark.Iterator {
+	return r.fields.Iterate()
+}
+
+func (r *Record) Len() int {
+	return r.fields.Len()
+}
+
+func (r *Record) Attr(name string) (starlark.Value, error) {
+	value, _, err := r.fields.Get(starlark.String(name))
+
+	return value, err //nolint:wrapcheck
+}
+
+func (r *Record) AttrNames() []string {
+	names := make([]string, 0, r.fields.Len())
+	for _, key := range r.fields.Keys() {
+		name, _ := starlark.AsString(key)
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// node returns the record fields wrapped as the record node.
+func (r *Record) node() (*yaml.Node, error) {
+	fields, err := fromStarlarkEntries(r.fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.NewRecordNode(fields), nil
+}
@@ -0,0 +1,95 @@
+package kstar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr wantErr
+	}{
+		{
+			name: "kwargs",
+			expr: `record(name="web", replicas=2)`,
+			want: `record({"name": "web", "replicas": 2})`,
+		},
+		{
+			name: "mapping",
+			expr: `record({"name": "web"}, replicas=node)`,
+			want: `record({"name": "web", "replicas": 3})`,
+		},
+		{
+			name: "attr",
+			expr: `[record(name="web").name, record(name="web")["name"], len(record(a=1, b=2))]`,
+			want: `["web", "web", 2]`,
+		},
+		{
+			name: "keys",
+			expr: `list(record(b=1, a=2))`,
+			want: `["b", "a"]`,
+		},
+		{
+			name:    "non-string-key",
+			expr:    `record({1: "web"})`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		const resultVar = "result"
+		runStarlarkTest(t, test.name,
+			fmt.Sprintf(`%s = repr(%s)`, resultVar, test.expr),
+			StringDict{
+				fnRecord: starlark.NewBuiltin(fnRecord, newRecord),
+				"node":   FromYNode(yaml.NewScalarRNode("3").YNode()),
+			},
+			false, test.wantErr,
+			func(t *testing.T, gotAll StringDict) {
+				got := gotAll[resultVar]
+				if diff := cmp.Diff(starlark.String(test.want), got); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
+
+func TestRecordNode(t *testing.T) {
+	fields := yaml.MustParse(strings.Join([]string{
+		`name: web`,
+		`replicas: 2`,
+	}, "\n"))
+
+	record, err := NewRecord(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ynode, err := FromStarlark(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, isRecord := types.RecordFields(yaml.NewRNode(ynode))
+	if !isRecord {
+		t.Fatalf("not a record: %s", yaml.NewRNode(ynode).MustString())
+	}
+
+	if diff := cmp.Diff(fields.MustString(), got.MustString()); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+
+	if _, isRecord := types.RecordFields(fields); isRecord {
+		t.Error("fields reported as a record")
+	}
+}
@@ -0,0 +1,97 @@
+package kstar
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+const fnRegexPattern = "regex"
+
+// regexPattern matches the strings by the RE2 regular expression, as
+// matchPattern does by the shell pattern, e.g. regex("^my-", name).
+type regexPattern struct {
+	re      *regexp.Regexp
+	inverse bool
+}
+
+var (
+	_ starlark.Value     = new(regexPattern)
+	_ starlark.HasBinary = new(regexPattern)
+	_ starlark.HasUnary  = new(regexPattern)
+)
+
+func (pattern *regexPattern) String() string {
+	inverse := ""
+	if pattern.inverse {
+		inverse = "~"
+	}
+
+	return fmt.Sprintf("%s%s(%s)", inverse, fnRegexPattern, syntax.Quote(pattern.re.String(), false))
+}
+
+func (pattern *regexPattern) Type() string {
+	return fnRegexPattern
+}
+
+func (pattern *regexPattern) Freeze() {
+}
+
+func (pattern *regexPattern) Truth() starlark.Bool {
+	return starlark.String(pattern.re.String()).Truth()
+}
+
+func (pattern *regexPattern) Hash() (uint32, error) {
+	return starlark.String(pattern.String()).Hash()
+}
+
+func (pattern *regexPattern) Name() string {
+	return fnRegexPattern
+}
+
+func (pattern *regexPattern) Binary(op syntax.Token, value starlark.Value, _ starlark.Side) (starlark.Value, error) {
+	if op != syntax.IN {
+		return nil, nil
+	}
+
+	return pattern.apply(value)
+}
+
+func (pattern *regexPattern) Unary(op syntax.Token) (starlark.Value, error) {
+	if op != syntax.TILDE {
+		return nil, nil
+	}
+
+	return &regexPattern{pattern.re, !pattern.inverse}, nil
+}
+
+func (pattern *regexPattern) apply(value starlark.Value) (starlark.Value, error) {
+	return applyPattern(pattern, fnRegexPattern, func(text string) bool {
+		return pattern.re.MatchString(text) != pattern.inverse
+	}, value)
+}
+
+func newRegexPattern(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var expr string
+	var value starlark.Value
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &expr, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	pattern := &regexPattern{re, false}
+
+	if len(args) == 1 {
+		return pattern, nil
+	}
+
+	return pattern.apply(value)
+}
@@ -0,0 +1,89 @@
+package kstar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    starlark.Value
+		wantErr wantErr
+	}{
+		{
+			name: "ctor",
+			expr: `str(regex("^my-"))`,
+			want: starlark.String(`regex("^my-")`),
+		},
+		{
+			name: "ctor-inverse",
+			expr: `str(~regex("^my-"))`,
+			want: starlark.String(`~regex("^my-")`),
+		},
+		{
+			name:    "ctor-err",
+			expr:    `regex("my[")`,
+			wantErr: true,
+		},
+		{
+			name: "arg-single-match",
+			expr: `regex("^my-", "my-app")`,
+			want: starlark.String("my-app"),
+		},
+		{
+			name: "arg-single-no-match",
+			expr: `regex("^my-", "other")`,
+			want: starlark.None,
+		},
+		{
+			name: "arg-multi-match",
+			expr: `regex("v[0-9]+$", ["app:v1", "app:latest", "init:v22"])`,
+			want: starlark.NewList([]starlark.Value{
+				starlark.String("app:v1"),
+				starlark.String("init:v22"),
+			}),
+		},
+		{
+			name:    "arg-unsupported",
+			expr:    `regex("^my-", 1)`,
+			wantErr: true,
+		},
+		{
+			name: "in-match",
+			expr: `"my-app" in regex("^my-")`,
+			want: starlark.String("my-app"),
+		},
+		{
+			name: "not-in-match",
+			expr: `"my-app" not in regex("^my-")`,
+			want: starlark.False,
+		},
+		{
+			name: "inverse-in-match",
+			expr: `"other" in ~regex("^my-")`,
+			want: starlark.String("other"),
+		},
+	}
+
+	for _, test := range tests {
+		const resultVar = "result"
+		runStarlarkTest(t, test.name,
+			fmt.Sprintf("%s = %s", resultVar, test.expr),
+			StringDict{
+				fnRegexPattern: starlark.NewBuiltin(fnRegexPattern, newRegexPattern),
+			},
+			false, test.wantErr,
+			func(t *testing.T, gotAll StringDict) {
+				got := gotAll[resultVar]
+				if diff := cmp.Diff(test.want, got, commonCmpOpts...); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,316 @@
+package kstar
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.starlark.net/starlark"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// resourceMethods navigate the relationships between the resource and the
+// other resources of the same sequence. Fields with the same names take
+// precedence.
+//
+//nolint:gochecknoglobals
+var resourceMethods = map[string]*starlark.Builtin{
+	"owners":        starlark.NewBuiltin("owners", resourceRelation(isOwner)),
+	"children":      starlark.NewBuiltin("children", resourceRelation(flipRelation(isOwner))),
+	"selects":       starlark.NewBuiltin("selects", resourceRelation(isSelected)),
+	"selected_by":   starlark.NewBuiltin("selected_by", resourceRelation(flipRelation(isSelected))),
+	"references":    starlark.NewBuiltin("references", resourceRelation(isReferenced)),
+	"referenced_by": starlark.NewBuiltin("referenced_by", resourceRelation(flipRelation(isReferenced))),
+}
+
+//nolint:gochecknoglobals
+var (
+	podTemplateKinds = []string{
+		"DaemonSet",
+		"Deployment",
+		"Job",
+		"ReplicaSet",
+		"ReplicationController",
+		"StatefulSet",
+	}
+
+	labelSelectorPaths = map[string][]string{
+		"DaemonSet":           {"spec", "selector"},
+		"Deployment":          {"spec", "selector"},
+		"Job":                 {"spec", "selector"},
+		"NetworkPolicy":       {"spec", "podSelector"},
+		"PodDisruptionBudget": {"spec", "selector"},
+		"ReplicaSet":          {"spec", "selector"},
+		"StatefulSet":         {"spec", "selector"},
+	}
+)
+
+// relation checks whether the source resource relates to the target one,
+// e.g. the source is owned by the target.
+type relation func(source, target *yaml.RNode) (bool, error)
+
+func flipRelation(rel relation) relation {
+	return func(source, target *yaml.RNode) (bool, error) {
+		return rel(target, source)
+	}
+}
+
+func resourceRelation(rel relation) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		node, _ := fn.Receiver().(*MappingNode)
+		source := yaml.NewRNode(node.ynode)
+		indices := []int{}
+
+		for idx, ynode := range node.resources.ynode.Content {
+			if ynode == node.ynode {
+				continue
+			}
+
+			related, err := rel(source, yaml.NewRNode(ynode))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+			}
+
+			if related {
+				indices = append(indices, idx)
+			}
+		}
+
+		return node.resources.subset(indices), nil
+	}
+}
+
+func isOwner(source, target *yaml.RNode) (bool, error) {
+	refs, err := source.Pipe(yaml.Lookup("metadata", "ownerReferences"))
+	if err != nil || refs == nil {
+		return false, err
+	}
+
+	elems, err := refs.Elements()
+	if err != nil {
+		return false, err
+	}
+
+	targetUID, _ := target.GetString("metadata.uid")
+
+	for _, ref := range elems {
+		uid, _ := ref.GetString("uid")
+		kind, _ := ref.GetString("kind")
+		name, _ := ref.GetString("name")
+
+		if uid != "" && targetUID != "" {
+			if uid == targetUID {
+				return true, nil
+			}
+
+			continue
+		}
+
+		if kind == target.GetKind() && name == target.GetName() &&
+			(target.GetNamespace() == "" || target.GetNamespace() == source.GetNamespace()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func podLabels(rnode *yaml.RNode) (labels.Set, bool) {
+	var path []string
+
+	switch kind := rnode.GetKind(); {
+	case kind == "Pod":
+		path = []string{"metadata", "labels"}
+	case kind == "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "metadata", "labels"}
+	case slices.Contains(podTemplateKinds, kind):
+		path = []string{"spec", "template", "metadata", "labels"}
+	default:
+		return nil, false
+	}
+
+	values := labels.Set{}
+
+	node, err := rnode.Pipe(yaml.Lookup(path...))
+	if err != nil || node == nil {
+		return values, true
+	}
+
+	_ = node.VisitFields(func(field *yaml.MapNode) error {
+		values[yaml.GetValue(field.Key)] = yaml.GetValue(field.Value)
+		return nil
+	})
+
+	return values, true
+}
+
+func selectorOf(rnode *yaml.RNode) (labels.Selector, error) {
+	if rnode.GetKind() == "Service" {
+		node, err := rnode.Pipe(yaml.Lookup("spec", "selector"))
+		if err != nil || node == nil {
+			return nil, err
+		}
+
+		values := labels.Set{}
+
+		_ = node.VisitFields(func(field *yaml.MapNode) error {
+			values[yaml.GetValue(field.Key)] = yaml.GetValue(field.Value)
+			return nil
+		})
+
+		if len(values) == 0 {
+			return nil, nil
+		}
+
+		return labels.SelectorFromSet(values), nil
+	}
+
+	path, found := labelSelectorPaths[rnode.GetKind()]
+	if !found {
+		return nil, nil
+	}
+
+	node, err := rnode.Pipe(yaml.Lookup(path...))
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	body, err := node.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalid, err)
+	}
+
+	selector := &metav1.LabelSelector{}
+	if err := json.Unmarshal(body, selector); err != nil {
+		return nil, fmt.Errorf("%w: %s selector: %v", errInvalid, rnode.GetKind(), err)
+	}
+
+	result, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s selector: %v", errInvalid, rnode.GetKind(), err)
+	}
+
+	return result, nil
+}
+
+func isSelected(source, target *yaml.RNode) (bool, error) {
+	if source.GetNamespace() != target.GetNamespace() {
+		return false, nil
+	}
+
+	targetLabels, found := podLabels(target)
+	if !found {
+		return false, nil
+	}
+
+	selector, err := selectorOf(source)
+	if err != nil || selector == nil {
+		return false, err
+	}
+
+	return selector.Matches(targetLabels), nil
+}
+
+type objectRef struct {
+	kind string
+	name string
+}
+
+func podSpec(rnode *yaml.RNode) *yaml.RNode {
+	var path []string
+
+	switch kind := rnode.GetKind(); {
+	case kind == "Pod":
+		path = []string{"spec"}
+	case kind == "CronJob":
+		path = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	case slices.Contains(podTemplateKinds, kind):
+		path = []string{"spec", "template", "spec"}
+	default:
+		return nil
+	}
+
+	spec, err := rnode.Pipe(yaml.Lookup(path...))
+	if err != nil {
+		return nil
+	}
+
+	return spec
+}
+
+// objectRefs returns the objects referenced by the pod spec: volumes,
+// envFrom, env, serviceAccountName and imagePullSecrets.
+func objectRefs(rnode *yaml.RNode) []objectRef {
+	spec := podSpec(rnode)
+	if spec == nil {
+		return nil
+	}
+
+	refs := []objectRef{}
+	addRef := func(node *yaml.RNode, kind string, path ...string) {
+		value, err := node.Pipe(yaml.Lookup(path...))
+		if err == nil && value != nil && yaml.GetValue(value) != "" {
+			refs = append(refs, objectRef{kind, yaml.GetValue(value)})
+		}
+	}
+	elements := func(node *yaml.RNode, path ...string) []*yaml.RNode {
+		value, err := node.Pipe(yaml.Lookup(path...))
+		if err != nil || value == nil {
+			return nil
+		}
+
+		elems, _ := value.Elements()
+
+		return elems
+	}
+
+	addRef(spec, "ServiceAccount", "serviceAccountName")
+
+	for _, secret := range elements(spec, "imagePullSecrets") {
+		addRef(secret, "Secret", "name")
+	}
+
+	for _, volume := range elements(spec, "volumes") {
+		addRef(volume, "ConfigMap", "configMap", "name")
+		addRef(volume, "Secret", "secret", "secretName")
+		addRef(volume, "PersistentVolumeClaim", "persistentVolumeClaim", "claimName")
+
+		for _, source := range elements(volume, "projected", "sources") {
+			addRef(source, "ConfigMap", "configMap", "name")
+			addRef(source, "Secret", "secret", "name")
+		}
+	}
+
+	containers := slices.Concat(elements(spec, "containers"), elements(spec, "initContainers"))
+
+	for _, container := range containers {
+		for _, envFrom := range elements(container, "envFrom") {
+			addRef(envFrom, "ConfigMap", "configMapRef", "name")
+			addRef(envFrom, "Secret", "secretRef", "name")
+		}
+
+		for _, env := range elements(container, "env") {
+			addRef(env, "ConfigMap", "valueFrom", "configMapKeyRef", "name")
+			addRef(env, "Secret", "valueFrom", "secretKeyRef", "name")
+		}
+	}
+
+	return refs
+}
+
+func isReferenced(source, target *yaml.RNode) (bool, error) {
+	if source.GetNamespace() != target.GetNamespace() {
+		return false, nil
+	}
+
+	ref := objectRef{target.GetKind(), target.GetName()}
+
+	return slices.Contains(objectRefs(source), ref), nil
+}
@@ -0,0 +1,238 @@
+package kstar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+const relationsTestResources = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: ns1
+  uid: d1
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      serviceAccountName: web
+      imagePullSecrets:
+      - name: registry
+      containers:
+      - name: web
+        envFrom:
+        - secretRef:
+            name: credentials
+      volumes:
+      - name: config
+        configMap:
+          name: config
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web-1
+  namespace: ns1
+  uid: r1
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Deployment
+    name: web
+    uid: d1
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1-a
+  namespace: ns1
+  labels:
+    app: web
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: ReplicaSet
+    name: web-1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: ns1
+spec:
+  selector:
+    app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: orphan
+  namespace: ns1
+spec:
+  selector:
+    app: missing
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: web
+  namespace: ns1
+spec:
+  podSelector:
+    matchExpressions:
+    - key: app
+      operator: In
+      values: [web, api]
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+  namespace: ns2
+spec:
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: ns1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: ns2
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+  namespace: ns1
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+  namespace: ns1
+`
+
+func TestResourceRelations(t *testing.T) {
+	rnodes, err := (&kio.ByteReader{
+		Reader:                strings.NewReader(relationsTestResources),
+		OmitReaderAnnotations: true,
+	}).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    []string
+		wantErr wantErr
+	}{
+		{
+			name: "owners",
+			expr: `find("Pod", "web-1-a").owners()`,
+			want: []string{"ReplicaSet/web-1"},
+		},
+		{
+			name: "owners-uid",
+			expr: `find("ReplicaSet", "web-1").owners()`,
+			want: []string{"Deployment/web"},
+		},
+		{
+			name: "children",
+			expr: `find("Deployment", "web").children()`,
+			want: []string{"ReplicaSet/web-1"},
+		},
+		{
+			name: "selects-service",
+			expr: `find("Service", "web").selects()`,
+			want: []string{"Deployment/web", "ReplicaSet/web-1", "Pod/web-1-a"},
+		},
+		{
+			name: "selects-none",
+			expr: `find("Service", "orphan").selects()`,
+			want: []string{},
+		},
+		{
+			name: "selects-other-namespace",
+			expr: `find("PodDisruptionBudget", "web", "ns2").selects()`,
+			want: []string{},
+		},
+		{
+			name: "selected-by",
+			expr: `find("Pod", "web-1-a").selected_by()`,
+			want: []string{"Deployment/web", "ReplicaSet/web-1", "Service/web", "NetworkPolicy/web"},
+		},
+		{
+			name: "references",
+			expr: `find("Deployment", "web").references()`,
+			want: []string{"ConfigMap/config", "Secret/credentials", "ServiceAccount/web"},
+		},
+		{
+			name: "referenced-by",
+			expr: `find("ConfigMap", "config", "ns1").referenced_by()`,
+			want: []string{"Deployment/web"},
+		},
+		{
+			name: "referenced-by-other-namespace",
+			expr: `find("ConfigMap", "config", "ns2").referenced_by()`,
+			want: []string{},
+		},
+		{
+			name: "unused",
+			expr: `resources(lambda r: r.kind == "Service" and not r.selects())`,
+			want: []string{"Service/orphan"},
+		},
+	}
+
+	for _, test := range tests {
+		const resultVar = "result"
+		runStarlarkTest(t, test.name,
+			fmt.Sprintf(`
+def find(kind, name, namespace = "ns1"):
+    for it in resources:
+        if it.kind == kind and it.metadata.name == name and it.metadata.namespace == namespace:
+            return it
+    fail("not found")
+
+%s = ["%%s/%%s" %% (it.kind, it.metadata.name) for it in %s]
+`, resultVar, test.expr),
+			StringDict{
+				"resources": FromRNodes(NewSchemaIndex(nil), rnodes),
+			},
+			false, test.wantErr,
+			func(t *testing.T, gotAll StringDict) {
+				want := []starlark.Value{}
+				for _, item := range test.want {
+					want = append(want, starlark.String(item))
+				}
+
+				if diff := cmp.Diff(starlark.NewList(want), gotAll[resultVar], commonCmpOpts...); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
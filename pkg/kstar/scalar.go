@@ -25,7 +25,16 @@ type ScalarNode struct {
 var _ starlark.Value = new(ScalarNode)
 
 func (node *ScalarNode) String() string {
-	panic(errNotImplemented)
+	if node.isNull() {
+		return starlark.None.String()
+	}
+
+	value, err := node.Value()
+	if err != nil {
+		return node.ynode.Value
+	}
+
+	return value.String()
 }
 
 func (node *ScalarNode) Type() string {
@@ -37,11 +46,20 @@ func (node *ScalarNode) Freeze() {
 }
 
 func (node *ScalarNode) Truth() starlark.Bool {
-	panic(errNotImplemented)
+	if node.isNull() {
+		return starlark.False
+	}
+
+	value, err := node.Value()
+	if err != nil {
+		return len(node.ynode.Value) > 0
+	}
+
+	return value.Truth()
 }
 
 func (node *ScalarNode) Hash() (uint32, error) {
-	panic(errNotImplemented)
+	return 0, fmt.Errorf("%w: %s", errUnhashable, node.Type())
 }
 
 func (node *ScalarNode) Value() (starlark.Value, error) {
@@ -53,6 +71,12 @@ func (node *ScalarNode) Value() (starlark.Value, error) {
 	return node.cached, err
 }
 
+// isNull reports whether the node is the YAML null, which is not a scalar
+// value and is read as None.
+func (node *ScalarNode) isNull() bool {
+	return node.ynode.ShortTag() == yaml.NodeTagNull
+}
+
 func (node *ScalarNode) setSchema(ns *NodeSchema) {
 	node.schema = ns
 }
@@ -82,7 +106,10 @@ func (node *ScalarNode) compute() (starlark.Value, error) {
 		}
 
 		return starlark.Bool(value), nil
-	default:
+	case yaml.NodeTagNull, yaml.NodeTagMap, yaml.NodeTagSeq:
 		panic(fmt.Errorf("%w: %s", errNotAScalarNode, tag))
+	default:
+		// timestamps, binary and custom tags are kept as the text
+		return starlark.String(node.ynode.Value), nil
 	}
 }
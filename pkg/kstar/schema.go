@@ -31,10 +31,13 @@ type NodeSchema struct {
 	schema *spec.Schema
 	ref    refName
 	path   fieldPath
+
+	// resource the schema is bound to, set for the top-level resources
+	resource *yaml.Node
 }
 
 func (ns *NodeSchema) String() string {
-	panic(errNotImplemented)
+	return ns.Name()
 }
 
 func (ns *NodeSchema) Type() string {
@@ -50,7 +53,7 @@ func (ns *NodeSchema) Truth() starlark.Bool {
 }
 
 func (ns *NodeSchema) Hash() (uint32, error) {
-	panic(errNotImplemented)
+	return 0, fmt.Errorf("%w: %s", errUnhashable, ns.Type())
 }
 
 func (ns *NodeSchema) Name() string {
@@ -285,6 +288,9 @@ type SchemaIndex struct {
 	refFields   map[refName]refFields
 	global      *spec.Schema
 	aliases     map[string]*NodeSchema
+	gvkRefs     map[string]refName
+	ownGlobal   bool
+	strict      bool
 }
 
 func NewSchemaIndex(schema *spec.Schema) *SchemaIndex {
@@ -298,9 +304,14 @@ func NewSchemaIndex(schema *spec.Schema) *SchemaIndex {
 		refFields:   map[refName]refFields{},
 		global:      schema,
 		aliases:     aliases,
+		gvkRefs:     map[string]refName{},
 	}
 
-	for ref := range schema.Definitions {
+	for ref, def := range schema.Definitions {
+		for _, gvk := range schemaGVKs(&def) {
+			idx.gvkRefs[gvk] = ref
+		}
+
 		if !strings.HasPrefix(ref, iok8s) {
 			continue
 		}
@@ -341,7 +352,7 @@ func NewSchemaIndex(schema *spec.Schema) *SchemaIndex {
 }
 
 func (idx *SchemaIndex) String() string {
-	panic(errNotImplemented)
+	return idx.Type()
 }
 
 func (idx *SchemaIndex) Type() string {
@@ -357,7 +368,7 @@ func (idx *SchemaIndex) Truth() starlark.Bool {
 }
 
 func (idx *SchemaIndex) Hash() (uint32, error) {
-	panic(errNotImplemented)
+	return 0, fmt.Errorf("%w: %s", errUnhashable, idx.Type())
 }
 
 func (idx *SchemaIndex) Attr(name string) (starlark.Value, error) {
@@ -464,7 +475,7 @@ type schemaLookup struct {
 }
 
 func (sl *schemaLookup) String() string {
-	panic(errNotImplemented)
+	return sl.Type() + "(" + strings.Join(sl.parts, ".") + ")"
 }
 
 func (sl *schemaLookup) Type() string {
@@ -480,7 +491,7 @@ func (sl *schemaLookup) Truth() starlark.Bool {
 }
 
 func (sl *schemaLookup) Hash() (uint32, error) {
-	panic(errNotImplemented)
+	return 0, fmt.Errorf("%w: %s", errUnhashable, sl.Type())
 }
 
 func (sl *schemaLookup) AttrNames() []string {
@@ -20,11 +20,12 @@ var (
 	_ starlark.HasSetKey = new(SequenceNode)
 	_ starlark.Iterable  = new(SequenceNode)
 	_ starlark.Callable  = new(SequenceNode)
+	_ starlark.HasAttrs  = new(SequenceNode)
 )
 
+// String returns the node in the YAML flow style, as the lists are printed.
 func (node *SequenceNode) String() string {
-	//REVISIT: maybe return json
-	panic(errNotImplemented)
+	return flowString(node.ynode, node.Type())
 }
 
 func (node *SequenceNode) Type() string {
@@ -40,7 +41,7 @@ func (node *SequenceNode) Truth() starlark.Bool {
 }
 
 func (node *SequenceNode) Hash() (uint32, error) {
-	panic(errNotImplemented)
+	return 0, fmt.Errorf("%w: %s", errUnhashable, node.Type())
 }
 
 func (node *SequenceNode) setSchema(ns *NodeSchema) {
@@ -83,6 +84,10 @@ func (node *SequenceNode) index(idx int) starlark.Value {
 		return value
 	}
 
+	if scalar.isNull() {
+		return starlark.None
+	}
+
 	scalarValue, err := scalar.Value()
 	if err != nil {
 		return value
@@ -140,6 +145,10 @@ func (node *SequenceNode) SetKey(key, value starlark.Value) error {
 			return err
 		}
 
+		if err := node.schema.checkElement(ynode); err != nil {
+			return err
+		}
+
 		schema := node.schema.Elements()
 		elem := FromYNode(ynode)
 		elem.setSchema(schema)
@@ -178,12 +187,7 @@ func (iter *seqIterator) Done() {
 }
 
 func (node *SequenceNode) filter(th *starlark.Thread, fn starlark.Callable) (*SequenceNode, error) {
-	if node.elems == nil {
-		node.loadElements()
-	}
-
-	ynodes := []*yaml.Node{}
-	elems := []starlark.Value{}
+	indices := []int{}
 
 	for idx := range node.len() {
 		args := starlark.Tuple{node.index(idx)}
@@ -197,19 +201,10 @@ func (node *SequenceNode) filter(th *starlark.Thread, fn starlark.Callable) (*Se
 			continue
 		}
 
-		ynodes = append(ynodes, node.ynode.Content[idx])
-		elems = append(elems, node.elems[idx])
+		indices = append(indices, idx)
 	}
 
-	return &SequenceNode{
-		schema: node.schema,
-		ynode: &yaml.Node{
-			Kind:    yaml.SequenceNode,
-			Tag:     yaml.NodeTagSeq,
-			Content: ynodes,
-		},
-		elems: elems,
-	}, nil
+	return node.subset(indices), nil
 }
 
 func (node *SequenceNode) Name() string { // Callable Name
@@ -402,3 +402,137 @@ func TestSequenceFilter(t *testing.T) {
 		)
 	}
 }
+
+func TestSequenceAggregate(t *testing.T) {
+	pod := func(name, namespace, team string, containers ...string) *yaml.RNode {
+		lines := []string{
+			`apiVersion: v1`,
+			`kind: Pod`,
+			`metadata:`,
+			`  name: ` + name,
+			`  namespace: ` + namespace,
+			`  labels:`,
+			`    example.com/team: ` + team,
+			`spec:`,
+			`  containers:`,
+		}
+
+		for _, container := range containers {
+			parts := strings.Split(container, ",")
+			lines = append(lines,
+				`  - image: `+parts[0],
+				`    resources:`,
+				`      requests:`,
+				`        memory: `+parts[1],
+			)
+		}
+
+		return yaml.MustParse(strings.Join(lines, "\n"))
+	}
+
+	resources := []*yaml.RNode{
+		pod("b", "ns1", "red", "app:v1,128Mi", "sidecar:v1,64Mi"),
+		pod("a", "ns2", "blue", "app:v2,1Gi"),
+		pod("c", "ns1", "red", "app:v1,256Mi"),
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr wantErr
+	}{
+		{
+			name: "count",
+			expr: `resources.count()`,
+			want: `3`,
+		},
+		{
+			name: "count-path",
+			expr: `resources.count("metadata.labels.[example.com/owner]")`,
+			want: `0`,
+		},
+		{
+			name: "sum-quantity",
+			expr: `resources.sum("spec.containers.resources.requests.memory")`,
+			want: `1472Mi`,
+		},
+		{
+			name: "sum-callable",
+			expr: `resources.sum(lambda r: r.spec.containers.count())`,
+			want: `4`,
+		},
+		{
+			name: "sum-empty",
+			expr: `resources.sum("spec.missing")`,
+			want: `0`,
+		},
+		{
+			name: "distinct",
+			expr: `resources.distinct("spec.containers.image")`,
+			want: `["app:v1", "sidecar:v1", "app:v2"]`,
+		},
+		{
+			name: "distinct-escaped",
+			expr: `resources.distinct("metadata.labels.[example.com/team]")`,
+			want: `["red", "blue"]`,
+		},
+		{
+			name: "group-by",
+			expr: `{ns: group.count() for ns, group in resources.group_by("metadata.namespace").items()}`,
+			want: `{"ns1": 2, "ns2": 1}`,
+		},
+		{
+			name: "group-by-multiple",
+			expr: `{image: [it.metadata.name for it in group] for image, group in resources.group_by("spec.containers.image").items()}`,
+			want: `{"app:v1": ["b", "c"], "sidecar:v1": ["b"], "app:v2": ["a"]}`,
+		},
+		{
+			name:    "group-by-node",
+			expr:    `resources.group_by("spec")`,
+			wantErr: true,
+		},
+		{
+			name: "sort-by",
+			expr: `[it.metadata.name for it in resources.sort_by("metadata.name")]`,
+			want: `["a", "b", "c"]`,
+		},
+		{
+			name: "sort-by-reverse",
+			expr: `[it.metadata.name for it in resources.sort_by(lambda r: r.spec.containers.count(), reverse=True)]`,
+			want: `["b", "a", "c"]`,
+		},
+		{
+			name: "flat-map",
+			expr: `resources.flat_map("spec.containers").distinct("image")`,
+			want: `["app:v1", "sidecar:v1", "app:v2"]`,
+		},
+		{
+			name: "flat-map-scalars",
+			expr: `[it for it in resources.flat_map("metadata.namespace")]`,
+			want: `["ns1", "ns2", "ns1"]`,
+		},
+		{
+			name:    "invalid-path",
+			expr:    `resources.sum(1)`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		const resultVar = "result"
+		runStarlarkTest(t, test.name,
+			fmt.Sprintf("%s = repr(%s)", resultVar, test.expr),
+			StringDict{
+				"resources": FromRNodes(NewSchemaIndex(nil), resources),
+			},
+			false, test.wantErr,
+			func(t *testing.T, gotAll StringDict) {
+				got := gotAll[resultVar]
+				if diff := cmp.Diff(starlark.String(test.want), got); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,215 @@
+package kstar
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	preserveUnknownExtension = "x-kubernetes-preserve-unknown-fields"
+	intOrStringExtension     = "x-kubernetes-int-or-string"
+	intOrStringFormat        = "int-or-string"
+	quantityRef              = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+)
+
+var errSchemaViolation = errors.New("schema violation")
+
+// SetStrict enables checking the assigned values against the schema, so
+// unknown fields, wrong scalar types and lists assigned to maps are rejected
+// instead of being silently added to the resources.
+func (idx *SchemaIndex) SetStrict(strict bool) {
+	idx.strict = strict
+}
+
+// resourceName returns the identity of the resource the schema is bound to.
+func (ns *NodeSchema) resourceName() string {
+	for ; ns != nil; ns = ns.parent {
+		if ns.resource == nil {
+			continue
+		}
+
+		rnode := yaml.NewRNode(ns.resource)
+		parts := []string{rnode.GetKind(), rnode.GetNamespace(), rnode.GetName()}
+
+		return strings.Join(slices.DeleteFunc(parts, func(part string) bool { return part == "" }), "/")
+	}
+
+	return ""
+}
+
+func (ns *NodeSchema) violation(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if name := ns.resourceName(); name != "" {
+		msg = name + ": " + msg
+	}
+
+	return fmt.Errorf("%w: %s", errSchemaViolation, msg)
+}
+
+// strict reports whether the assignments to the node are checked.
+func (ns *NodeSchema) strict() bool {
+	return ns != nil && ns.idx != nil && ns.idx.strict
+}
+
+// checkField checks the value assigned to the field of the node in the
+// strict mode.
+func (ns *NodeSchema) checkField(name string, ynode *yaml.Node) error {
+	if !ns.strict() {
+		return nil
+	}
+
+	resolved := ns.Resolve()
+	if resolved.schema == nil {
+		return nil
+	}
+
+	return ns.checkValue(resolved.schema, resolved.ref, resolved.path, &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Content: []*yaml.Node{yaml.NewStringRNode(name).YNode(), ynode},
+	})
+}
+
+// checkElement checks the value assigned to the element of the sequence in
+// the strict mode.
+func (ns *NodeSchema) checkElement(ynode *yaml.Node) error {
+	if !ns.strict() {
+		return nil
+	}
+
+	resolved := ns.Resolve()
+	if resolved.schema == nil {
+		return nil
+	}
+
+	return ns.checkValue(resolved.schema, resolved.ref, resolved.path, &yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Content: []*yaml.Node{ynode},
+	})
+}
+
+func (ns *NodeSchema) checkValue(schema *spec.Schema, ref refName, path fieldPath, ynode *yaml.Node) error {
+	if defRef := strings.TrimPrefix(schema.Ref.String(), schemaDefinitionsPrefix); defRef != "" {
+		resolved, err := openapi.Resolve(&schema.Ref, ns.idx.global)
+		if err != nil || resolved == nil {
+			return nil
+		}
+
+		schema, ref = resolved, defRef
+	}
+
+	if ynode.ShortTag() == yaml.NodeTagNull {
+		return nil
+	}
+
+	switch ynode.Kind {
+	case yaml.MappingNode:
+		return ns.checkMapping(schema, ref, path, ynode)
+	case yaml.SequenceNode:
+		if !schemaAllows(schema, "array") {
+			return ns.violation("%s: %s expected, got list", path, schemaTypeName(schema, ref))
+		}
+
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return nil
+		}
+
+		for _, elem := range ynode.Content {
+			err := ns.checkValue(schema.Items.Schema, ref, slices.Concat(path, fieldPath{openapi.Elements}), elem)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case yaml.ScalarNode:
+		return ns.checkScalar(schema, ref, path, ynode)
+	default:
+		return nil
+	}
+}
+
+func (ns *NodeSchema) checkMapping(schema *spec.Schema, ref refName, path fieldPath, ynode *yaml.Node) error {
+	if !schemaAllows(schema, "object") {
+		return ns.violation("%s: %s expected, got map", path, schemaTypeName(schema, ref))
+	}
+
+	if preserve, _ := schema.Extensions.GetBool(preserveUnknownExtension); preserve {
+		return nil
+	}
+
+	additional := schema.AdditionalProperties
+	if len(schema.Properties) == 0 && additional == nil {
+		return nil
+	}
+
+	for idx := range len(ynode.Content) / 2 {
+		key, value := ynode.Content[idx*2], ynode.Content[idx*2+1]
+		keyPath := slices.Concat(path, fieldPath{key.Value})
+
+		prop, found := schema.Properties[key.Value]
+
+		switch {
+		case found:
+			if err := ns.checkValue(&prop, ref, keyPath, value); err != nil {
+				return err
+			}
+		case additional != nil && additional.Schema != nil:
+			if err := ns.checkValue(additional.Schema, ref, keyPath, value); err != nil {
+				return err
+			}
+		case additional != nil && additional.Allows:
+		default:
+			return ns.violation("%s: unknown field of %s", keyPath, ref)
+		}
+	}
+
+	return nil
+}
+
+func (ns *NodeSchema) checkScalar(schema *spec.Schema, ref refName, path fieldPath, ynode *yaml.Node) error {
+	if len(schema.Type) == 0 {
+		return nil
+	}
+
+	intOrString, _ := schema.Extensions.GetBool(intOrStringExtension)
+	intOrString = intOrString || schema.Format == intOrStringFormat || ref == quantityRef
+
+	var allowed bool
+
+	switch ynode.ShortTag() {
+	case yaml.NodeTagString:
+		allowed = schemaAllows(schema, "string")
+	case yaml.NodeTagInt:
+		allowed = schemaAllows(schema, "integer") || schemaAllows(schema, "number") || intOrString
+	case yaml.NodeTagFloat:
+		allowed = schemaAllows(schema, "number") || ref == quantityRef
+	case yaml.NodeTagBool:
+		allowed = schemaAllows(schema, "boolean")
+	default:
+		allowed = true
+	}
+
+	if !allowed {
+		return ns.violation("%s: %s expected, got %q", path, schemaTypeName(schema, ref), ynode.Value)
+	}
+
+	return nil
+}
+
+func schemaAllows(schema *spec.Schema, typeName string) bool {
+	return len(schema.Type) == 0 || schema.Type.Contains(typeName)
+}
+
+func schemaTypeName(schema *spec.Schema, ref refName) string {
+	if len(schema.Type) == 0 {
+		return ref
+	}
+
+	return strings.Join(schema.Type, ",")
+}
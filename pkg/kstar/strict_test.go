@@ -0,0 +1,132 @@
+package kstar
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestStrictAssignments(t *testing.T) {
+	deploy := strings.Join([]string{
+		`apiVersion: apps/v1`,
+		`kind: Deployment`,
+		`metadata:`,
+		`  name: web`,
+		`  namespace: ns1`,
+		`spec:`,
+		`  template:`,
+		`    spec:`,
+		`      containers:`,
+		`      - name: app`,
+		`        image: app:v1`,
+	}, "\n")
+	custom := strings.Join([]string{
+		`apiVersion: example.com/v1`,
+		`kind: Custom`,
+		`metadata:`,
+		`  name: custom`,
+	}, "\n")
+
+	tests := []struct {
+		name    string
+		script  string
+		strict  bool
+		wantErr wantErr
+	}{
+		{
+			name:   "field",
+			script: `resources[0].spec.replicas = 3`,
+			strict: true,
+		},
+		{
+			name:    "unknown-field",
+			script:  `resources[0].spec.replica = 3`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "unknown-field-not-strict",
+			script: `resources[0].spec.replica = 3`,
+		},
+		{
+			name:    "unknown-nested-field",
+			script:  `resources[0].spec.template.spec.containers[0] = {"name": "app", "imag": "app:v2"}`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "merge",
+			script: `resources[0].spec += {"replicas": 3}`,
+			strict: true,
+		},
+		{
+			name:    "merge-unknown-field",
+			script:  `resources[0].spec += {"replica": 3}`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "merge-unknown-nested-field",
+			script:  `resources[0].spec.template += {"spec": {"hostname": "web", "hostnam": "web"}}`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "merge-unknown-field-not-strict",
+			script: `resources[0].spec += {"replica": 3}`,
+		},
+		{
+			name:    "wrong-scalar-type",
+			script:  `resources[0].spec.replicas = "3"`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "list-instead-of-map",
+			script:  `resources[0].metadata.labels = ["app"]`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:    "map-instead-of-list",
+			script:  `resources[0].spec.template.spec.containers = {"name": "app"}`,
+			strict:  true,
+			wantErr: true,
+		},
+		{
+			name:   "additional-properties",
+			script: `resources[0].metadata.labels = {"app": "web"}`,
+			strict: true,
+		},
+		{
+			name:   "quantity-number",
+			script: `resources[0].spec.template.spec.containers[0].resources = {"limits": {"cpu": 2, "memory": "1Gi"}}`,
+			strict: true,
+		},
+		{
+			name:   "int-or-string",
+			script: `resources[0].spec.strategy = {"rollingUpdate": {"maxSurge": 1, "maxUnavailable": "25%"}}`,
+			strict: true,
+		},
+		{
+			name:   "unknown-kind",
+			script: `resources[1].spec = {"anything": ["goes"]}`,
+			strict: true,
+		},
+	}
+
+	for _, test := range tests {
+		idx := NewSchemaIndex(nil)
+		idx.SetStrict(test.strict)
+
+		runStarlarkTest(t, test.name,
+			test.script,
+			StringDict{
+				"resources": FromRNodes(idx, []*yaml.RNode{yaml.MustParse(deploy), yaml.MustParse(custom)}),
+			},
+			false, test.wantErr,
+			func(_ *testing.T, _ StringDict) {},
+		)
+	}
+}
@@ -0,0 +1,117 @@
+package kstar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const moduleYAML = "yaml"
+
+// YAMLModule encodes Starlark values and decodes documents into nodes, e.g.:
+//
+//	yaml.decode(it.metadata.annotations["example.com/config"]).replicas
+//	yaml.decode_all(text)
+//	yaml.encode({"replicas": 3})
+//
+//nolint:gochecknoglobals
+var YAMLModule = &starlarkstruct.Module{
+	Name: moduleYAML,
+	Members: starlark.StringDict{
+		"decode":     starlark.NewBuiltin(moduleYAML+".decode", yamlDecode),
+		"decode_all": starlark.NewBuiltin(moduleYAML+".decode_all", yamlDecodeAll),
+		"encode":     starlark.NewBuiltin(moduleYAML+".encode", yamlEncode),
+	},
+}
+
+func yamlDocuments(fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) ([]starlark.Value, error) {
+	var text string
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &text)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []starlark.Value{}
+	decoder := yaml.NewDecoder(strings.NewReader(text))
+
+	for {
+		doc := &yaml.Node{}
+
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+		}
+
+		value := starlark.Value(starlark.None)
+
+		if len(doc.Content) > 0 && doc.Content[0].Tag != yaml.NodeTagNull {
+			node := FromYNode(doc.Content[0])
+			value = node
+
+			if scalar, ok := node.(*ScalarNode); ok {
+				value, err = scalar.Value()
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+				}
+			}
+		}
+
+		docs = append(docs, value)
+	}
+}
+
+func yamlDecode(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	docs, err := yamlDocuments(fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(docs) {
+	case 0:
+		return starlark.None, nil
+	case 1:
+		return docs[0], nil
+	default:
+		return nil, fmt.Errorf("%s: %w: %d documents", fn.Name(), errInvalid, len(docs))
+	}
+}
+
+func yamlDecodeAll(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	docs, err := yamlDocuments(fn, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+
+	return starlark.NewList(docs), nil
+}
+
+func yamlEncode(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var value starlark.Value
+
+	err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	ynode, err := FromStarlark(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	text, err := yaml.String(ynode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.Name(), err)
+	}
+
+	return starlark.String(text), nil
+}
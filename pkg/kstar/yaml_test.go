@@ -0,0 +1,116 @@
+package kstar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.starlark.net/starlark"
+)
+
+func TestYAMLModule(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    starlark.Value
+		wantErr wantErr
+	}{
+		{
+			name: "decode-mapping",
+			expr: `yaml.decode("replicas: 3\nimage: myapp").replicas`,
+			want: starlark.MakeInt(3),
+		},
+		{
+			name: "decode-sequence",
+			expr: `[it.name for it in yaml.decode("- name: a\n- name: b")]`,
+			want: starlark.NewList([]starlark.Value{starlark.String("a"), starlark.String("b")}),
+		},
+		{
+			name: "decode-scalar",
+			expr: `yaml.decode("myapp")`,
+			want: starlark.String("myapp"),
+		},
+		{
+			name: "decode-empty",
+			expr: `yaml.decode("")`,
+			want: starlark.None,
+		},
+		{
+			name: "decode-timestamp",
+			expr: `yaml.decode("2024-01-01")`,
+			want: starlark.String("2024-01-01"),
+		},
+		{
+			name: "decode-timestamp-field",
+			expr: `yaml.decode("x: 2024-01-01T10:00:00Z").x`,
+			want: starlark.String("2024-01-01T10:00:00Z"),
+		},
+		{
+			name: "decode-null-field",
+			expr: `yaml.decode("x: ~").x`,
+			want: starlark.None,
+		},
+		{
+			name: "decode-null-item",
+			expr: `yaml.decode("[~]")[0]`,
+			want: starlark.None,
+		},
+		{
+			name: "decode-binary-field",
+			expr: `yaml.decode("x: !!binary aGVsbG8=").x`,
+			want: starlark.String("aGVsbG8="),
+		},
+		{
+			name:    "decode-multiple",
+			expr:    `yaml.decode("a: 1\n---\nb: 2")`,
+			wantErr: true,
+		},
+		{
+			name: "decode-all",
+			expr: `[dir(it) for it in yaml.decode_all("a: 1\n---\nb: 2")]`,
+			want: starlark.NewList([]starlark.Value{
+				starlark.NewList([]starlark.Value{starlark.String("a")}),
+				starlark.NewList([]starlark.Value{starlark.String("b")}),
+			}),
+		},
+		{
+			name:    "decode-invalid",
+			expr:    `yaml.decode("a: [")`,
+			wantErr: true,
+		},
+		{
+			name: "encode",
+			expr: `yaml.encode({"replicas": 3, "ports": [80]})`,
+			want: starlark.String("replicas: 3\nports:\n- 80\n"),
+		},
+		{
+			name: "encode-quantity-duration",
+			expr: `yaml.encode({"cpu": quantity("500m"), "timeout": duration(90)})`,
+			want: starlark.String("cpu: 500m\ntimeout: 1m30s\n"),
+		},
+		{
+			name: "roundtrip",
+			expr: `yaml.encode(yaml.decode("b: 1\na: [x]"))`,
+			want: starlark.String("b: 1\na: [x]\n"),
+		},
+	}
+
+	for _, test := range tests {
+		const resultVar = "result"
+		runStarlarkTest(t, test.name,
+			fmt.Sprintf("%s = %s", resultVar, test.expr),
+			StringDict{
+				moduleYAML: YAMLModule,
+				fnQuantity: starlark.NewBuiltin(fnQuantity, newQuantity),
+				fnDuration: starlark.NewBuiltin(fnDuration, newDuration),
+			},
+			false, test.wantErr,
+			func(t *testing.T, gotAll StringDict) {
+				got := gotAll[resultVar]
+				if diff := cmp.Diff(test.want, got, commonCmpOpts...); diff != "" {
+					t.Fatalf("-want +got:\n%s", diff)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,286 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	argoCDVersion             = "argoproj.io/v1alpha1"
+	argoCDDefaultName         = "ktl"
+	argoCDDefaultNamespace    = "argocd"
+	argoCDDefaultProject      = "default"
+	argoCDDefaultRevision     = "HEAD"
+	argoCDGeneratorList       = "list"
+	argoCDGeneratorClusters   = "clusters"
+	argoCDGeneratorApps       = "applications"
+	argoCDSyncWaveAnnotation  = "argocd.argoproj.io/sync-wave"
+	argoCDSyncWaveCRDs        = -2
+	argoCDSyncWaveNamespaces  = -1
+	argoCDListClusterTemplate = "{{.cluster}}"
+	argoCDClusterNameTemplate = `{{index .metadata.labels "` + clusterLabel + `"}}`
+)
+
+// clusterLabel is the label naming the cluster of ktl in the cluster objects
+// of the deployment tools.
+const clusterLabel = "ktl.mirantis.com/cluster"
+
+var errUnsupportedGenerator = errors.New("unsupported generator")
+
+func newArgoCDOutput(spec *apis.ArgoCDOutput) (*ArgoCDOutput, error) {
+	out := &ArgoCDOutput{
+		RepoURL:        spec.GetRepoUrl(),
+		TargetRevision: spec.GetTargetRevision(),
+		Generator:      spec.GetGenerator(),
+		Name:           spec.GetName(),
+		Namespace:      spec.GetNamespace(),
+		Project:        spec.GetProject(),
+	}
+
+	if len(out.TargetRevision) == 0 {
+		out.TargetRevision = argoCDDefaultRevision
+	}
+
+	if len(out.Generator) == 0 {
+		out.Generator = argoCDGeneratorList
+	}
+
+	if len(out.Name) == 0 {
+		out.Name = argoCDDefaultName
+	}
+
+	if len(out.Namespace) == 0 {
+		out.Namespace = argoCDDefaultNamespace
+	}
+
+	if len(out.Project) == 0 {
+		out.Project = argoCDDefaultProject
+	}
+
+	switch out.Generator {
+	case argoCDGeneratorList, argoCDGeneratorClusters, argoCDGeneratorApps:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedGenerator, out.Generator)
+	}
+
+	if chartSpec := spec.GetHelmChart(); chartSpec != nil {
+		switch deployment := chartSpec.GetDeployment(); deployment {
+		case "", chartDeploymentKustomize:
+		default:
+			return nil, fmt.Errorf("%w: %s with argocd", errUnsupportedDeployment, deployment)
+		}
+
+		layout, err := newChartOutput(chartSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		out.layout = layout
+	} else {
+		out.layout = &ComponentsOutput{}
+	}
+
+	return out, nil
+}
+
+// ArgoCDOutput lays out the overlays of the clusters, using either the
+// Kustomize components or the Helm chart, and the Argo CD objects syncing
+// the overlays: an ApplicationSet or an Application per cluster.
+type ArgoCDOutput struct {
+	RepoURL        string `yaml:"repoURL"`
+	TargetRevision string `yaml:"targetRevision"`
+	Generator      string `yaml:"generator"`
+	Name           string `yaml:"name"`
+	Namespace      string `yaml:"namespace"`
+	Project        string `yaml:"project"`
+	layout         Impl
+}
+
+type argoCDSource struct {
+	RepoURL        string `yaml:"repoURL"`
+	TargetRevision string `yaml:"targetRevision"`
+	Path           string `yaml:"path"`
+}
+
+type argoCDDestination struct {
+	Name   string `yaml:"name,omitempty"`
+	Server string `yaml:"server,omitempty"`
+}
+
+type argoCDSyncPolicy struct {
+	Automated struct {
+		Prune    bool `yaml:"prune"`
+		SelfHeal bool `yaml:"selfHeal"`
+	} `yaml:"automated"`
+}
+
+type argoCDApplicationSpec struct {
+	Project     string            `yaml:"project"`
+	Source      argoCDSource      `yaml:"source"`
+	Destination argoCDDestination `yaml:"destination"`
+	SyncPolicy  argoCDSyncPolicy  `yaml:"syncPolicy"`
+}
+
+type argoCDTemplate struct {
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec argoCDApplicationSpec `yaml:"spec"`
+}
+
+type argoCDApplicationSetSpec struct {
+	GoTemplate        bool             `yaml:"goTemplate"`
+	GoTemplateOptions []string         `yaml:"goTemplateOptions"`
+	Generators        []map[string]any `yaml:"generators"`
+	Template          argoCDTemplate   `yaml:"template"`
+}
+
+// argoCDSyncWave returns the sync wave of the resource, CRDs are applied
+// before the namespaces and the namespaces before the rest.
+func argoCDSyncWave(id resid.ResId) int {
+	switch fluxLayer(id) {
+	case fluxLayerCRDs:
+		return argoCDSyncWaveCRDs
+	case fluxLayerNamespaces:
+		return argoCDSyncWaveNamespaces
+	default:
+		return 0
+	}
+}
+
+// withSyncWaves returns the resources with the CRDs and the namespaces
+// annotated with their sync waves, the rest are kept in the default wave.
+func withSyncWaves(resources *types.ClusterResources) (*types.ClusterResources, error) {
+	result := &types.ClusterResources{
+		Clusters:  resources.Clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{},
+		Records:   resources.Records,
+	}
+
+	for id, byCluster := range resources.Resources {
+		wave := argoCDSyncWave(id)
+		if wave == 0 {
+			result.Resources[id] = byCluster
+			continue
+		}
+
+		annotated := map[types.ClusterID]*yaml.RNode{}
+
+		for clusterID, rnode := range byCluster {
+			rnode = rnode.Copy()
+			if err := rnode.PipeE(yaml.SetAnnotation(argoCDSyncWaveAnnotation, strconv.Itoa(wave))); err != nil {
+				return nil, fmt.Errorf("unable to set sync wave of %s: %w", id, err)
+			}
+
+			annotated[clusterID] = rnode
+		}
+
+		result.Resources[id] = annotated
+	}
+
+	return result, nil
+}
+
+func (out *ArgoCDOutput) Store(env *types.Env, resources *types.ClusterResources) error {
+	resources, err := withSyncWaves(resources)
+	if err != nil {
+		return err
+	}
+
+	if err := out.layout.Store(env, resources); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	objects := []manifestObject{}
+
+	switch out.Generator {
+	case argoCDGeneratorApps:
+		for _, cluster := range resources.Clusters.All() {
+			app := out.application(cluster.Name, argoCDDestination{Name: cluster.Name})
+
+			objects = append(objects, manifestObject{
+				APIVersion: argoCDVersion,
+				Kind:       "Application",
+				Metadata:   objectMeta{Name: app.Metadata.Name, Namespace: out.Namespace},
+				Spec:       app.Spec,
+			})
+		}
+	case argoCDGeneratorClusters:
+		objects = append(objects, out.applicationSet(
+			map[string]any{argoCDGeneratorClusters: map[string]any{
+				"selector": map[string]any{
+					"matchExpressions": []map[string]any{{
+						"key":      clusterLabel,
+						"operator": "In",
+						"values":   out.clusterNames(resources.Clusters),
+					}},
+				},
+			}},
+			out.application(argoCDClusterNameTemplate, argoCDDestination{Server: "{{.server}}"}),
+		))
+	default:
+		elements := []map[string]any{}
+		for _, cluster := range resources.Clusters.All() {
+			elements = append(elements, map[string]any{
+				"cluster": cluster.Name,
+				"tags":    append([]string{}, cluster.Tags...),
+			})
+		}
+
+		objects = append(objects, out.applicationSet(
+			map[string]any{argoCDGeneratorList: map[string]any{"elements": elements}},
+			out.application(argoCDListClusterTemplate, argoCDDestination{Name: argoCDListClusterTemplate}),
+		))
+	}
+
+	return storeObjects(fsutil.Sub(env.FileSys, "argocd"), objects)
+}
+
+func (out *ArgoCDOutput) clusterNames(clusters *types.ClusterIndex) []string {
+	names := []string{}
+	for _, cluster := range clusters.All() {
+		names = append(names, cluster.Name)
+	}
+
+	return names
+}
+
+func (out *ArgoCDOutput) application(clusterName string, destination argoCDDestination) argoCDTemplate {
+	app := argoCDTemplate{
+		Spec: argoCDApplicationSpec{
+			Project: out.Project,
+			Source: argoCDSource{
+				RepoURL:        out.RepoURL,
+				TargetRevision: out.TargetRevision,
+				Path:           "overlays/" + clusterName,
+			},
+			Destination: destination,
+		},
+	}
+	app.Metadata.Name = out.Name + "-" + clusterName
+	app.Spec.SyncPolicy.Automated.Prune = true
+	app.Spec.SyncPolicy.Automated.SelfHeal = true
+
+	return app
+}
+
+func (out *ArgoCDOutput) applicationSet(generator map[string]any, template argoCDTemplate) manifestObject {
+	return manifestObject{
+		APIVersion: argoCDVersion,
+		Kind:       "ApplicationSet",
+		Metadata:   objectMeta{Name: out.Name, Namespace: out.Namespace},
+		Spec: argoCDApplicationSetSpec{
+			GoTemplate:        true,
+			GoTemplateOptions: []string{"missingkey=error"},
+			Generators:        []map[string]any{generator},
+			Template:          template,
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package output_test
+
+import (
+	"embed"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/e2e"
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+//go:embed testdata/argocd
+var argoCDFs embed.FS
+
+func TestArgoCD(t *testing.T) {
+	out, err := output.New(&apis.Output{Argocd: &apis.ArgoCDOutput{
+		RepoUrl: "https://git.example.com/fleet.git",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, layeredTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := e2e.ReadFiles(t, gotFs, ".")
+	want := e2e.ReadFsFiles(t, argoCDFs, "testdata/argocd")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("argocd repository mismatch, +got -want:\n%s", diff)
+	}
+}
+
+func TestArgoCDUnsupportedDeployment(t *testing.T) {
+	_, err := output.New(&apis.Output{Argocd: &apis.ArgoCDOutput{
+		RepoUrl: "https://git.example.com/fleet.git",
+		HelmChart: &apis.HelmChartOutput{
+			Name:       "myapp",
+			Version:    "0.1.0",
+			Deployment: proto.String("helmfile"),
+		},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported chart deployment") {
+		t.Errorf("want unsupported chart deployment, got %v", err)
+	}
+}
+
+func TestArgoCDApplications(t *testing.T) {
+	out, err := output.New(&apis.Output{Argocd: &apis.ArgoCDOutput{
+		RepoUrl:   "https://git.example.com/fleet.git",
+		Generator: proto.String("applications"),
+		HelmChart: &apis.HelmChartOutput{Name: "myapp", Version: "0.1.0"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, layeredTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{
+		"argocd/ktl-dev-a-application.yaml",
+		"argocd/ktl-prod-a-application.yaml",
+		"charts/myapp/Chart.yaml",
+		"overlays/prod-a/kustomization.yaml",
+	} {
+		if !gotFs.Exists(path) {
+			t.Errorf("%s is missing", path)
+		}
+	}
+
+	if _, err := output.New(&apis.Output{Argocd: &apis.ArgoCDOutput{
+		Generator: proto.String("git"),
+	}}); err == nil {
+		t.Error("want error for the unsupported generator")
+	}
+}
@@ -31,17 +31,31 @@ var (
 )
 
 func newChartOutput(spec *apis.HelmChartOutput) (*ChartOutput, error) {
+	if err := validateValuesNaming(spec.GetValuesNaming()); err != nil {
+		return nil, err
+	}
+
+	if err := validateDeployment(spec); err != nil {
+		return nil, err
+	}
+
+	flux, err := newFluxOutput(spec.GetFlux())
+	if err != nil {
+		return nil, err
+	}
+
 	hc := types.HelmChart{
 		Name:    spec.GetName(),
 		Version: spec.GetVersion(),
 	}
 
-	return &ChartOutput{hc, spec}, nil
+	return &ChartOutput{hc, spec, flux}, nil
 }
 
 type ChartOutput struct {
 	HelmChart types.HelmChart `yaml:"helmChart"`
 	spec      *apis.HelmChartOutput
+	flux      *FluxOutput
 }
 
 //nolint:lll
@@ -72,37 +86,77 @@ func (out *ChartOutput) storeChartOverlays(env *types.Env, resources *types.Clus
 	return nil
 }
 
-func (out *ChartOutput) Store(env *types.Env, resources *types.ClusterResources) error {
+// storeChart stores the chart of the resources in charts/<name> with its
+// packages, returning the chart and its directory.
+func (out *ChartOutput) storeChart(env *types.Env, resources *types.ClusterResources) (*Chart, string, error) {
 	chartMeta := out.HelmChart
 	chart := NewChart(chartMeta, out.spec, resources.Clusters)
 	chartDir := filepath.Join("charts", chartMeta.Name)
 	chartFS := fsutil.Sub(env.FileSys, chartDir)
 
 	if err := chartFS.MkdirAll("."); err != nil {
-		return fmt.Errorf("unable to create charts dir: %w", err)
+		return nil, "", fmt.Errorf("unable to create charts dir: %w", err)
+	}
+
+	aliasesFile := out.spec.GetAliasesFile()
+	if len(aliasesFile) > 0 {
+		if err := chart.loadAliases(env.FileSys, aliasesFile); err != nil {
+			return nil, "", err
+		}
 	}
 
 	for id, byCluster := range resources.Resources {
 		if err := chart.Add(id, byCluster); err != nil {
-			return fmt.Errorf("unable to add resources to the chart: %w", err)
+			return nil, "", fmt.Errorf("unable to add resources to the chart: %w", err)
 		}
 	}
 
 	if err := chart.Store(chartFS, "."); err != nil {
-		return fmt.Errorf("unable to store the chart: %w", err)
+		return nil, "", fmt.Errorf("unable to store the chart: %w", err)
+	}
+
+	if len(aliasesFile) > 0 {
+		if err := chart.storeAliases(env.FileSys, aliasesFile); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := out.storePackage(env.FileSys, chartDir); err != nil {
+		return nil, "", err
+	}
+
+	return chart, chartDir, nil
+}
+
+func (out *ChartOutput) Store(env *types.Env, resources *types.ClusterResources) error {
+	chart, chartDir, err := out.storeChart(env, resources)
+	if err != nil {
+		return err
+	}
+
+	valuesFiles, err := out.storeDeployment(env, resources, chart, chartDir)
+	if err != nil {
+		return err
+	}
+
+	if !out.spec.GetVerify() {
+		return nil
 	}
 
-	return out.storeChartOverlays(env, resources, chart, chartDir)
+	values, err := out.deploymentValues(env.FileSys, resources.Clusters, valuesFiles)
+	if err != nil {
+		return err
+	}
+
+	return verifyChart(env.FileSys, resources, chartDir, values)
 }
 
 type chartValues map[string]*yaml.Node
 
-func (cv chartValues) asMap() map[string]any {
-	rNode := yaml.NewMapRNode(nil)
-	for name, value := range cv {
-		if err := rNode.SetMapField(yaml.NewRNode(value), name); err != nil {
-			panic(err)
-		}
+func (chart *Chart) valuesMap(cv chartValues) map[string]any {
+	rNode, err := chart.valuesNode(cv)
+	if err != nil {
+		panic(err)
 	}
 
 	body, err := rNode.MarshalJSON()
@@ -123,6 +177,9 @@ type Chart struct {
 	spec      *apis.HelmChartOutput
 	templates map[resid.ResId]*yaml.RNode
 	crds      map[resid.ResId]*yaml.RNode
+	variables map[string]*chartVariable
+	names     map[string][]string
+	aliases   map[string]string
 
 	token          string
 	presetValues   map[string]chartValues
@@ -145,6 +202,7 @@ func NewChart(meta types.HelmChart, spec *apis.HelmChartOutput, clusters *types.
 		clusterPresets: map[types.ClusterID]sets.String{},
 		templates:      map[resid.ResId]*yaml.RNode{},
 		crds:           map[resid.ResId]*yaml.RNode{},
+		variables:      map[string]*chartVariable{},
 	}
 
 	return chart
@@ -175,23 +233,33 @@ func (chart *Chart) storeCRDs(fileSys filesys.FileSystem, dir string) error {
 }
 
 func (chart *Chart) storeTemplates(fileSys filesys.FileSystem, dir string) error {
+	const errMsgTemplates = "unable to store templates"
+
+	names, err := chart.valueNames()
+	if err != nil {
+		return fmt.Errorf("%s: %w", errMsgTemplates, err)
+	}
+
 	templatePrefix := []byte("# HELM" + chart.token + ": ")
 	store := &resource.FileStore{
 		FileSystem:    fsutil.Sub(fileSys, filepath.Join(dir, "templates")),
 		NameGenerator: chart.templateName,
 		PostProcessor: func(_ string, body []byte) []byte {
-			return bytes.ReplaceAll(body, templatePrefix, []byte{})
+			return chart.resolvePlaceholders(bytes.ReplaceAll(body, templatePrefix, []byte{}), names)
 		},
 	}
 
-	const errMsgTemplates = "unable to store templates"
-
-	err := store.WriteAll(maps.All(chart.templates))
+	err = store.WriteAll(maps.All(chart.templates))
 	if err != nil {
 		return fmt.Errorf("%s: %w", errMsgTemplates, err)
 	}
 
-	err = store.WriteFile("_helpers.tpl", helpersTpl)
+	helpers := helpersTpl
+	if chart.nested() {
+		helpers = nestedHelpersTpl
+	}
+
+	err = store.WriteFile("_helpers.tpl", helpers)
 	if err != nil {
 		return fmt.Errorf("%s: %w", errMsgTemplates, err)
 	}
@@ -208,15 +276,9 @@ func (chart *Chart) storeValues(fileSys filesys.FileSystem, dir string) error {
 			continue
 		}
 
-		vars := chart.presetValues[presetName]
-		preset := yaml.NewMapRNode(nil)
-
-		varNames := slices.Sorted(maps.Keys(vars))
-		for _, varName := range varNames {
-			value := yaml.NewRNode(vars[varName])
-			if err := preset.SetMapField(value, varName); err != nil {
-				panic(err)
-			}
+		preset, err := chart.valuesNode(chart.presetValues[presetName])
+		if err != nil {
+			return fmt.Errorf("unable to store values: %w", err)
 		}
 
 		if err := presets.SetMapField(preset, presetName); err != nil {
@@ -258,6 +320,14 @@ func (chart *Chart) Store(fileSys filesys.FileSystem, dir string) error {
 		return err
 	}
 
+	if err := chart.storeValuesSchema(fileSys, dir); err != nil {
+		return err
+	}
+
+	if err := chart.storeReadme(fileSys, dir); err != nil {
+		return err
+	}
+
 	metaBytes, err := yaml.Marshal(chart.meta)
 	if err != nil {
 		panic(err)
@@ -281,8 +351,14 @@ func (chart *Chart) Instance(cluster types.ClusterID) types.HelmChart {
 		helmChart.ValuesInline["presets"] = slices.Sorted(maps.Keys(presets))
 	}
 
+	global := chart.builtinValues(cluster)
 	if inline, found := chart.inlineValues[cluster]; found {
-		helmChart.ValuesInline["global"] = inline.asMap()
+		global = chart.valuesMap(inline)
+		maps.Copy(global, chart.builtinValues(cluster))
+	}
+
+	if global != nil {
+		helmChart.ValuesInline["global"] = global
 	}
 
 	return helmChart
@@ -300,31 +376,23 @@ func (chart *Chart) Instances(clusters ...types.ClusterID) []types.HelmChart {
 func (chart *Chart) variableName(id resid.ResId, path resource.Query) string {
 	name := fmt.Sprintf("%s/%s/%s.%s", id.Namespace, id.Kind, id.Name, path)
 	name = strings.TrimPrefix(name, "/")
-	name = strings.TrimSuffix(name, ".")
 
-	if chart.spec == nil || len(chart.spec.ValuesAliases) == 0 {
-		return name
-	}
-
-	alias, found := chart.spec.ValuesAliases[name]
-	if !found {
-		return name
-	}
-
-	return alias
+	return strings.TrimSuffix(name, ".")
 }
 
 func (chart *Chart) addCRD(resID resid.ResId, crds map[types.ClusterID]*yaml.RNode) error {
-	// FIXME: merge versions & detect conflicts
-	for _, rnode := range crds {
-		chart.crds[resID] = rnode.Copy()
+	crd, err := mergeCRDs(resID, crds)
+	if err != nil {
+		return err
 	}
 
+	chart.crds[resID] = crd
+
 	return nil
 }
 
 func (chart *Chart) Add(resID resid.ResId, resources map[types.ClusterID]*yaml.RNode) error {
-	if resID.Group == "apiextensions.k8s.io" && resID.Version == "v1" && resID.Kind == "CustomResourceDefinition" {
+	if isCRD(resID) {
 		return chart.addCRD(resID, resources)
 	}
 
@@ -343,12 +411,38 @@ func (chart *Chart) Add(resID resid.ResId, resources map[types.ClusterID]*yaml.R
 		occurrences = append(occurrences, slices.Repeat([]int{0}, max(0, depth+2-len(occurrences)))...)
 		occurrences[depth+1] = len(resIterator.Clusters())
 		isOptional := occurrences[depth+1] < occurrences[depth]
-		varName := chart.variableName(resID, resIterator.Path())
 		variants := resource.GroupByValue(resIterator.Values())
-		value := chart.value(varName, variants, isOptional)
+
+		newVariable := func() *chartVariable {
+			return chart.addVariable(&chartVariable{
+				name:   chart.variableName(resID, path),
+				id:     resID,
+				path:   path,
+				schema: resIterator.Schema(),
+				field:  resIterator.FieldSchema(),
+				toggle: len(variants) == 1,
+			})
+		}
+
+		if len(variants) > 1 && !isOptional && chart.spec.GetSubstringTemplates() {
+			if value, found := chart.substringValue(newVariable, variants); found {
+				if _, err := builder.Set(path, value); err != nil {
+					return fmt.Errorf("chart builder error: %w", err)
+				}
+
+				continue
+			}
+		}
+
+		var variable *chartVariable
+		if len(variants) > 1 || isOptional {
+			variable = newVariable()
+		}
+
+		value := chart.value(variable, variants, isOptional)
 
 		if isOptional {
-			chart.setOptional(varName, value)
+			chart.setOptional(variable, value)
 		}
 
 		_, err := builder.Set(path, value)
@@ -375,14 +469,14 @@ func (chart *Chart) Add(resID resid.ResId, resources map[types.ClusterID]*yaml.R
 	return nil
 }
 
-func (chart *Chart) value(variable string, variants []*resource.ValueGroup, optional bool) *yaml.Node {
+func (chart *Chart) value(variable *chartVariable, variants []*resource.ValueGroup, optional bool) *yaml.Node {
 	if len(variants) == 1 {
 		variant := variants[0]
 		value := variant.Value
 
 		if optional {
 			preset := chart.values(variant.Clusters)
-			preset[variable] = yaml.NewStringRNode("enabled").YNode()
+			preset[variable.name] = chart.toggleValue()
 		}
 
 		return value
@@ -390,11 +484,11 @@ func (chart *Chart) value(variable string, variants []*resource.ValueGroup, opti
 
 	for _, variant := range variants {
 		preset := chart.values(variant.Clusters)
-		preset[variable] = variant.Value
+		preset[variable.name] = variant.Value
 	}
 
 	node := yaml.NewScalarRNode("").YNode()
-	node.LineComment = fmt.Sprintf("HELM%s: {{ index .Values.global \"%s\" }}", chart.token, variable)
+	node.LineComment = fmt.Sprintf("HELM%s: {{ %s }}", chart.token, chart.placeholder("REF", variable))
 
 	return node
 }
@@ -432,9 +526,9 @@ func (chart *Chart) values(ids []types.ClusterID) chartValues {
 	return values
 }
 
-func (chart *Chart) setOptional(name string, node *yaml.Node) {
-	node.HeadComment = fmt.Sprintf("HELM%s: {{- if index .Values.global \"%s\" }}", chart.token, name)
-	node.FootComment = fmt.Sprintf("HELM%s: {{- end }} # %s", chart.token, name)
+func (chart *Chart) setOptional(variable *chartVariable, node *yaml.Node) {
+	node.HeadComment = fmt.Sprintf("HELM%s: {{- if %s }}", chart.token, chart.placeholder("REF", variable))
+	node.FootComment = fmt.Sprintf("HELM%s: {{- end }} # %s", chart.token, chart.placeholder("NAME", variable))
 }
 
 func fixMappingNode(node *yaml.Node) {
@@ -0,0 +1,353 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	chartDeploymentKustomize = "kustomize"
+	chartDeploymentValues    = "values"
+	chartDeploymentHelmfile  = "helmfile"
+	chartDeploymentFlux      = "fluxHelmRelease"
+
+	chartValuesDir         = "values"
+	fluxHelmReleaseVersion = "helm.toolkit.fluxcd.io/v2"
+)
+
+var (
+	errUnsupportedDeployment = errors.New("unsupported chart deployment")
+	errMissingRelease        = errors.New("missing chart release")
+)
+
+func validateDeployment(spec *apis.HelmChartOutput) error {
+	deployment := spec.GetDeployment()
+
+	switch deployment {
+	case "", chartDeploymentKustomize, chartDeploymentValues, chartDeploymentHelmfile, chartDeploymentFlux:
+	default:
+		return fmt.Errorf("%w: %s", errUnsupportedDeployment, deployment)
+	}
+
+	if !spec.GetLayeredValues() {
+		return nil
+	}
+
+	switch deployment {
+	case chartDeploymentValues, chartDeploymentHelmfile:
+		return nil
+	case "":
+		deployment = chartDeploymentKustomize
+	}
+
+	return fmt.Errorf("%w: %s with layered values", errUnsupportedDeployment, deployment)
+}
+
+type helmfileRelease struct {
+	Name        string            `yaml:"name"`
+	Chart       string            `yaml:"chart"`
+	KubeContext string            `yaml:"kubeContext"`
+	Labels      map[string]string `yaml:"labels"`
+	Values      []string          `yaml:"values,omitempty"`
+}
+
+type helmfile struct {
+	Releases []helmfileRelease `yaml:"releases"`
+}
+
+type fluxHelmChartSpec struct {
+	Chart             string        `yaml:"chart"`
+	ReconcileStrategy string        `yaml:"reconcileStrategy"`
+	SourceRef         fluxReference `yaml:"sourceRef"`
+}
+
+type fluxHelmChartTemplate struct {
+	Spec fluxHelmChartSpec `yaml:"spec"`
+}
+
+type fluxHelmReleaseSpec struct {
+	Interval string                `yaml:"interval"`
+	Chart    fluxHelmChartTemplate `yaml:"chart"`
+	Values   map[string]any        `yaml:"values,omitempty"`
+}
+
+// storeDeployment stores the files deploying the chart to the clusters by
+// the deployment of the chart output, returning the values files of the
+// clusters, if any.
+//
+//nolint:lll
+func (out *ChartOutput) storeDeployment(env *types.Env, resources *types.ClusterResources, chart *Chart, chartDir string) (map[types.ClusterID][]string, error) {
+	switch out.spec.GetDeployment() {
+	case chartDeploymentValues:
+		return out.storeValuesFiles(env.FileSys, resources.Clusters, chart)
+	case chartDeploymentHelmfile:
+		valuesFiles, err := out.storeValuesFiles(env.FileSys, resources.Clusters, chart)
+		if err != nil {
+			return nil, err
+		}
+
+		return valuesFiles, out.storeHelmfile(env.FileSys, resources.Clusters, chartDir, valuesFiles)
+	case chartDeploymentFlux:
+		return nil, out.storeHelmReleases(env.FileSys, resources.Clusters, chart, chartDir)
+	default:
+		return nil, out.storeChartOverlays(env, resources, chart, chartDir)
+	}
+}
+
+// deploymentValues returns the values of the clusters read from the stored
+// deployment files, in the order of layering by the deployment.
+//
+//nolint:lll
+func (out *ChartOutput) deploymentValues(fileSys filesys.FileSystem, clusters *types.ClusterIndex, valuesFiles map[types.ClusterID][]string) (map[types.ClusterID][]map[string]any, error) {
+	releases := helmfile{}
+	if out.spec.GetDeployment() == chartDeploymentHelmfile {
+		if err := readYAMLFile(fileSys, "helmfile.yaml", &releases); err != nil {
+			return nil, err
+		}
+	}
+
+	result := map[types.ClusterID][]map[string]any{}
+
+	for clusterID, cluster := range clusters.All() {
+		var files []string
+
+		switch out.spec.GetDeployment() {
+		case chartDeploymentValues:
+			files = valuesFiles[clusterID]
+		case chartDeploymentHelmfile:
+			idx := slices.IndexFunc(releases.Releases, func(release helmfileRelease) bool {
+				return release.KubeContext == cluster.Name
+			})
+			if idx < 0 {
+				return nil, fmt.Errorf("%w: %s in helmfile.yaml", errMissingRelease, cluster.Name)
+			}
+
+			files = releases.Releases[idx].Values
+		case chartDeploymentFlux:
+			release := struct {
+				Spec fluxHelmReleaseSpec `yaml:"spec"`
+			}{}
+
+			releaseFile := filepath.Join("clusters", cluster.Name, strings.ToLower(out.HelmChart.Name+"-HelmRelease.yaml"))
+			if err := readYAMLFile(fileSys, releaseFile, &release); err != nil {
+				return nil, err
+			}
+
+			result[clusterID] = []map[string]any{release.Spec.Values}
+
+			continue
+		default:
+			kust := types.Kustomization{}
+			if err := readYAMLFile(fileSys, filepath.Join("overlays", cluster.Name, "kustomization.yaml"), &kust); err != nil {
+				return nil, err
+			}
+
+			idx := slices.IndexFunc(kust.HelmCharts, func(helmChart types.HelmChart) bool {
+				return helmChart.Name == out.HelmChart.Name
+			})
+			if idx < 0 {
+				return nil, fmt.Errorf("%w: %s in overlays/%s", errMissingRelease, out.HelmChart.Name, cluster.Name)
+			}
+
+			result[clusterID] = []map[string]any{kust.HelmCharts[idx].ValuesInline}
+
+			continue
+		}
+
+		for _, file := range files {
+			values := map[string]any{}
+			if err := readYAMLFile(fileSys, file, &values); err != nil {
+				return nil, err
+			}
+
+			result[clusterID] = append(result[clusterID], values)
+		}
+	}
+
+	return result, nil
+}
+
+func readYAMLFile(fileSys filesys.FileSystem, path string, value any) error {
+	body, err := fileSys.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(body, value); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// storeValuesFiles stores the values of the clusters in values/<cluster>.yaml,
+// preceded by values/<preset>.yaml of their presets with the layered values.
+// It returns the values files of the clusters in the order of layering.
+//
+//nolint:lll
+func (out *ChartOutput) storeValuesFiles(fileSys filesys.FileSystem, clusters *types.ClusterIndex, chart *Chart) (map[types.ClusterID][]string, error) {
+	result := map[types.ClusterID][]string{}
+	presetFiles := map[string]string{}
+
+	for clusterID, cluster := range clusters.All() {
+		values := chart.Instance(clusterID).ValuesInline
+
+		if out.spec.GetLayeredValues() {
+			var presets []string
+
+			presets, values = chart.layeredInstance(clusterID)
+
+			for _, preset := range presets {
+				presetFile, found := presetFiles[preset]
+				if !found {
+					presetFile = filepath.Join(chartValuesDir, preset+".yaml")
+					presetFiles[preset] = presetFile
+
+					if err := storeValuesFile(fileSys, presetFile, chart.presetInstance(preset)); err != nil {
+						return nil, err
+					}
+				}
+
+				result[clusterID] = append(result[clusterID], presetFile)
+			}
+		}
+
+		clusterFile := filepath.Join(chartValuesDir, cluster.Name+".yaml")
+		if err := storeValuesFile(fileSys, clusterFile, values); err != nil {
+			return nil, err
+		}
+
+		result[clusterID] = append(result[clusterID], clusterFile)
+	}
+
+	return result, nil
+}
+
+func storeValuesFile(fileSys filesys.FileSystem, path string, values map[string]any) error {
+	body, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("unable to serialize values: %w", err)
+	}
+
+	if err := fileSys.MkdirAll(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("unable to create values dir: %w", err)
+	}
+
+	if err := fileSys.WriteFile(path, body); err != nil {
+		return fmt.Errorf("unable to store values: %w", err)
+	}
+
+	return nil
+}
+
+// storeHelmfile stores helmfile.yaml with a release of the chart per cluster,
+// the clusters are selected by the kube contexts named as the clusters or by
+// the cluster labels, e.g. helmfile -l cluster=dev-a sync.
+//
+//nolint:lll
+func (out *ChartOutput) storeHelmfile(fileSys filesys.FileSystem, clusters *types.ClusterIndex, chartDir string, valuesFiles map[types.ClusterID][]string) error {
+	releases := helmfile{Releases: []helmfileRelease{}}
+
+	for clusterID, cluster := range clusters.All() {
+		releases.Releases = append(releases.Releases, helmfileRelease{
+			Name:        out.HelmChart.Name,
+			Chart:       "./" + filepath.ToSlash(chartDir),
+			KubeContext: cluster.Name,
+			Labels:      map[string]string{"cluster": cluster.Name},
+			Values:      valuesFiles[clusterID],
+		})
+	}
+
+	body, err := yaml.Marshal(releases)
+	if err != nil {
+		return fmt.Errorf("unable to serialize helmfile: %w", err)
+	}
+
+	if err := fileSys.WriteFile("helmfile.yaml", body); err != nil {
+		return fmt.Errorf("unable to store helmfile: %w", err)
+	}
+
+	return nil
+}
+
+// storeHelmReleases stores the Flux HelmReleases of the chart in
+// clusters/<cluster>, installing the chart from the Flux source with the
+// values of the cluster. The source object is stored along when its URL is
+// set, as the Flux output does.
+//
+//nolint:lll
+func (out *ChartOutput) storeHelmReleases(fileSys filesys.FileSystem, clusters *types.ClusterIndex, chart *Chart, chartDir string) error {
+	for clusterID, cluster := range clusters.All() {
+		objects := []manifestObject{}
+		if len(out.flux.URL) > 0 {
+			objects = append(objects, out.flux.source())
+		}
+
+		objects = append(objects, manifestObject{
+			APIVersion: fluxHelmReleaseVersion,
+			Kind:       "HelmRelease",
+			Metadata:   objectMeta{Name: out.HelmChart.Name, Namespace: fluxNamespace},
+			Spec: fluxHelmReleaseSpec{
+				Interval: out.flux.Interval,
+				Chart: fluxHelmChartTemplate{Spec: fluxHelmChartSpec{
+					Chart:             out.flux.sourcePath(chartDir),
+					ReconcileStrategy: "Revision",
+					SourceRef:         fluxReference{Kind: out.flux.SourceKind, Name: out.flux.SourceName, Namespace: fluxNamespace},
+				}},
+				Values: chart.Instance(clusterID).ValuesInline,
+			},
+		})
+
+		if err := storeObjects(fsutil.Sub(fileSys, filepath.Join("clusters", cluster.Name)), objects); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// layeredInstance returns the presets of the cluster and its own values, the
+// values of the preset named as the cluster are merged into its own values.
+func (chart *Chart) layeredInstance(cluster types.ClusterID) ([]string, map[string]any) {
+	name := chart.clusters.Cluster(cluster).Name
+	values := chartValues{}
+	presets := []string{}
+
+	maps.Copy(values, chart.inlineValues[cluster])
+
+	for _, preset := range slices.Sorted(maps.Keys(chart.clusterPresets[cluster])) {
+		if preset == name {
+			maps.Copy(values, chart.presetValues[preset])
+
+			continue
+		}
+
+		presets = append(presets, preset)
+	}
+
+	global := chart.valuesMap(values)
+	maps.Copy(global, chart.builtinValues(cluster))
+
+	return presets, map[string]any{
+		"presets": []string{},
+		"global":  global,
+	}
+}
+
+// presetInstance returns the values of the preset layered under the values
+// of the clusters.
+func (chart *Chart) presetInstance(preset string) map[string]any {
+	return map[string]any{
+		"global": chart.valuesMap(chart.presetValues[preset]),
+	}
+}
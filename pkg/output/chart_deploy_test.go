@@ -0,0 +1,158 @@
+package output_test
+
+import (
+	"embed"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/e2e"
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+//go:embed testdata/chart-helmfile
+var chartHelmfileFs embed.FS
+
+func TestChartHelmfile(t *testing.T) {
+	out, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:          "myapp",
+		Version:       "0.1.0",
+		Deployment:    proto.String("helmfile"),
+		LayeredValues: proto.Bool(true),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotFs.Exists("overlays") {
+		t.Error("unexpected kustomize overlays")
+	}
+
+	got := map[string]string{}
+	for path, body := range e2e.ReadFiles(t, gotFs, ".") {
+		if !strings.HasPrefix(path, "charts/") {
+			got[path] = body
+		}
+	}
+	want := e2e.ReadFsFiles(t, chartHelmfileFs, "testdata/chart-helmfile")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("helmfile mismatch, +got -want:\n%s", diff)
+	}
+}
+
+func TestChartHelmRelease(t *testing.T) {
+	out, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:       "myapp",
+		Version:    "0.1.0",
+		Deployment: proto.String("fluxHelmRelease"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gotFs.ReadFile("clusters/prod-b/myapp-helmrelease.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `apiVersion: helm.toolkit.fluxcd.io/v2
+kind: HelmRelease
+metadata:
+  name: myapp
+  namespace: flux-system
+spec:
+  interval: 10m
+  chart:
+    spec:
+      chart: ./charts/myapp
+      reconcileStrategy: Revision
+      sourceRef:
+        kind: GitRepository
+        name: flux-system
+        namespace: flux-system
+  values:
+    global:
+      myapp/Deployment/myapp.spec.replicas: 5
+    presets:
+    - prod
+    - prod_test
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("HelmRelease mismatch, -want +got:\n%s", diff)
+	}
+}
+
+func TestChartUnsupportedDeployment(t *testing.T) {
+	_, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:       "myapp",
+		Version:    "0.1.0",
+		Deployment: proto.String("argo"),
+	}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported chart deployment") {
+		t.Errorf("want unsupported chart deployment, got %v", err)
+	}
+}
+
+func TestChartLayeredValuesDeployment(t *testing.T) {
+	_, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:          "myapp",
+		Version:       "0.1.0",
+		Deployment:    proto.String("fluxHelmRelease"),
+		LayeredValues: proto.Bool(true),
+	}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported chart deployment") {
+		t.Errorf("want unsupported chart deployment, got %v", err)
+	}
+}
+
+func TestChartHelmReleaseSource(t *testing.T) {
+	out, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:       "myapp",
+		Version:    "0.1.0",
+		Deployment: proto.String("fluxHelmRelease"),
+		Flux: &apis.FluxOutput{
+			Url:        proto.String("oci://registry.example.com/fleet"),
+			SourceKind: proto.String("OCIRepository"),
+			Path:       proto.String("fleet/myapp"),
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := e2e.ReadFiles(t, gotFs, ".")
+	for path, want := range map[string]string{
+		"clusters/dev-a/fleet-ocirepository.yaml": "url: oci://registry.example.com/fleet\n  ref:\n    tag: latest\n",
+		"clusters/dev-a/myapp-helmrelease.yaml": `      chart: ./fleet/myapp/charts/myapp
+      reconcileStrategy: Revision
+      sourceRef:
+        kind: OCIRepository
+        name: fleet
+        namespace: flux-system
+`,
+	} {
+		if !strings.Contains(got[path], want) {
+			t.Errorf("want %q in %s, got:\n%s", want, path, got[path])
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package output
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/resource"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	valuesNamingPath   = "path"
+	valuesNamingNested = "nested"
+
+	// nestedToggleKey is the key of the toggles in the nested values, so the
+	// optional fields are toggled next to their optional subfields.
+	nestedToggleKey = "enabled"
+)
+
+var (
+	errUnsupportedValuesNaming = errors.New("unsupported values naming")
+	errValuesCollision         = errors.New("values collision")
+
+	//go:embed data/_helpers_nested.tpl
+	nestedHelpersTpl []byte
+
+	nestedPartReplacer = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+	// shortFields are the fields of the containers named by their own names.
+	//
+	//nolint:gochecknoglobals
+	shortFields = []string{"image", "resources", "env"}
+)
+
+func validateValuesNaming(naming string) error {
+	switch naming {
+	case "", valuesNamingPath, valuesNamingNested:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", errUnsupportedValuesNaming, naming)
+	}
+}
+
+func (chart *Chart) nested() bool {
+	return chart.spec.GetValuesNaming() == valuesNamingNested
+}
+
+// addVariable registers the templated field, the field is referred to by
+// the placeholders until the names of all the values are known.
+func (chart *Chart) addVariable(variable *chartVariable) *chartVariable {
+	if existing, found := chart.variables[variable.name]; found {
+		return existing
+	}
+
+	variable.index = len(chart.variables)
+	chart.variables[variable.name] = variable
+	chart.names = nil
+
+	return variable
+}
+
+// toggleValue returns the value enabling the optional fields.
+func (chart *Chart) toggleValue() *yaml.Node {
+	if chart.nested() {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: yaml.NodeTagBool, Value: "true"}
+	}
+
+	return yaml.NewStringRNode(chartToggleValue).YNode()
+}
+
+// nestedPart returns the part of the nested name, the list elements are
+// named by the values of their keys.
+func nestedPart(part string) string {
+	if strings.HasPrefix(part, "[") && strings.HasSuffix(part, "]") {
+		values := []string{}
+
+		for _, keyValue := range strings.Split(strings.Trim(part, "[]"), ",") {
+			_, value, _ := strings.Cut(keyValue, "=")
+			values = append(values, value)
+		}
+
+		part = strings.Join(values, "_")
+	}
+
+	return nestedPartReplacer.ReplaceAllString(part, "_")
+}
+
+// shortField returns the short name of the common field of the resource:
+// the replicas, and the image, the resources and the env of the containers,
+// prefixed by the container name unless named as the resource.
+func shortField(id resid.ResId, path resource.Query) (resource.Query, bool) {
+	if slices.Equal(path, resource.Query{"spec", "replicas"}) {
+		return resource.Query{"replicas"}, true
+	}
+
+	for idx := range len(path) - 2 {
+		if path[idx] != "containers" || !slices.Contains(shortFields, path[idx+2]) {
+			continue
+		}
+
+		container, found := strings.CutPrefix(path[idx+1], "[name=")
+		if !found {
+			continue
+		}
+
+		container = strings.TrimSuffix(container, "]")
+		if container == id.Name {
+			return path[idx+2:], true
+		}
+
+		return append(resource.Query{container}, path[idx+2:]...), true
+	}
+
+	return nil, false
+}
+
+// generatedName returns the name of the value by the naming of the chart,
+// the qualified names are prefixed by the namespace of the resource.
+func (chart *Chart) generatedName(variable *chartVariable, qualified bool) []string {
+	field := variable.path
+
+	short, isShort := shortField(variable.id, variable.path)
+	if isShort && chart.spec.GetShortAliases() {
+		field = short
+	}
+
+	if !chart.nested() {
+		if qualified || !isShort || !chart.spec.GetShortAliases() {
+			return []string{variable.name}
+		}
+
+		return []string{chart.variableName(variable.id, field)}
+	}
+
+	parts := []string{}
+	if qualified && len(variable.id.Namespace) > 0 {
+		parts = append(parts, variable.id.Namespace)
+	}
+
+	parts = append(parts, variable.id.Name, strings.ToLower(variable.id.Kind))
+	for _, part := range field {
+		parts = append(parts, part)
+	}
+
+	if variable.toggle {
+		parts = append(parts, nestedToggleKey)
+	}
+
+	for idx, part := range parts {
+		parts[idx] = nestedPart(part)
+	}
+
+	return parts
+}
+
+// alias returns the name of the value set by the values aliases.
+func (chart *Chart) alias(name string) ([]string, bool) {
+	alias, found := chart.spec.GetValuesAliases()[name]
+	if !found {
+		alias, found = chart.aliases[name]
+	}
+
+	if !found {
+		return nil, false
+	}
+
+	if chart.nested() {
+		return strings.Split(alias, "."), true
+	}
+
+	return []string{alias}, true
+}
+
+// valueNames returns the names of the values by the names of the variables.
+// The generated names shared by several variables are qualified by the
+// namespaces, the names still colliding are reported as errors.
+func (chart *Chart) valueNames() (map[string][]string, error) {
+	if chart.names != nil {
+		return chart.names, nil
+	}
+
+	names := map[string][]string{}
+	counts := map[string]int{}
+	explicit := map[string]bool{}
+
+	for name, variable := range chart.variables {
+		valueName, found := chart.alias(name)
+		if !found {
+			valueName = chart.generatedName(variable, false)
+		}
+
+		names[name] = valueName
+		explicit[name] = found
+		counts[strings.Join(valueName, ".")]++
+	}
+
+	for name, variable := range chart.variables {
+		if !explicit[name] && counts[strings.Join(names[name], ".")] > 1 {
+			names[name] = chart.generatedName(variable, true)
+		}
+	}
+
+	if err := chart.checkCollisions(names); err != nil {
+		return nil, err
+	}
+
+	chart.names = names
+
+	return names, nil
+}
+
+// checkCollisions reports the values sharing the names, and the nested
+// values named by the prefixes of the other values.
+func (chart *Chart) checkCollisions(names map[string][]string) error {
+	byName := map[string]string{}
+	errs := []error{}
+
+	for builtin := range chart.builtinSchemas() {
+		byName[builtin] = "built-in value"
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		valueName := strings.Join(names[name], ".")
+		if other, found := byName[valueName]; found {
+			errs = append(errs, fmt.Errorf("%w: %s and %s are named %s", errValuesCollision, other, name, valueName))
+		}
+
+		byName[valueName] = name
+	}
+
+	if chart.nested() {
+		for _, name := range slices.Sorted(maps.Keys(names)) {
+			valueName := names[name]
+			for idx := 1; idx < len(valueName); idx++ {
+				prefix := strings.Join(valueName[:idx], ".")
+				if other, found := byName[prefix]; found {
+					errs = append(errs, fmt.Errorf("%w: %s named %s is nested in %s named %s",
+						errValuesCollision, name, strings.Join(valueName, "."), other, prefix))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// placeholder returns the reference to the value of the variable or its
+// name, replaced once the names are known.
+func (chart *Chart) placeholder(kind string, variable *chartVariable) string {
+	return fmt.Sprintf("%s%s(%d)", kind, chart.token, variable.index)
+}
+
+// valueRef returns the template expression of the value.
+func (chart *Chart) valueRef(valueName []string) string {
+	if !chart.nested() {
+		return fmt.Sprintf("index .Values.global \"%s\"", valueName[0])
+	}
+
+	keys := []string{}
+	for _, part := range valueName {
+		keys = append(keys, strconv.Quote(part))
+	}
+
+	return fmt.Sprintf("dig %s nil .Values.global", strings.Join(keys, " "))
+}
+
+// resolvePlaceholders replaces the placeholders with the references to the
+// values and their names.
+func (chart *Chart) resolvePlaceholders(body []byte, names map[string][]string) []byte {
+	byIndex := map[int][]string{}
+	for name, variable := range chart.variables {
+		byIndex[variable.index] = names[name]
+	}
+
+	pattern := regexp.MustCompile(`(REF|NAME)` + chart.token + `\((\d+)\)`)
+
+	return pattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		groups := pattern.FindSubmatch(match)
+
+		index, err := strconv.Atoi(string(groups[2]))
+		if err != nil {
+			panic(err)
+		}
+
+		if string(groups[1]) == "NAME" {
+			return []byte(strings.Join(byIndex[index], "."))
+		}
+
+		return []byte(chart.valueRef(byIndex[index]))
+	})
+}
+
+// valuesNode returns the values by their names, nested by the parts of the
+// names with the nested naming.
+func (chart *Chart) valuesNode(values chartValues) (*yaml.RNode, error) {
+	names, err := chart.valueNames()
+	if err != nil {
+		return nil, err
+	}
+
+	root := yaml.NewMapRNode(nil)
+
+	byValueName := map[string]string{}
+	for name := range values {
+		byValueName[strings.Join(names[name], ".")] = name
+	}
+
+	for _, valueName := range slices.Sorted(maps.Keys(byValueName)) {
+		name := byValueName[valueName]
+		path := names[name]
+		value := yaml.NewRNode(values[name])
+
+		parent, err := root.Pipe(yaml.LookupCreate(yaml.MappingNode, path[:len(path)-1]...))
+		if err != nil {
+			return nil, fmt.Errorf("unable to set %s: %w", valueName, err)
+		}
+
+		if err := parent.SetMapField(value, path[len(path)-1]); err != nil {
+			return nil, fmt.Errorf("unable to set %s: %w", valueName, err)
+		}
+	}
+
+	return root, nil
+}
+
+// loadAliases reads the names of the values stored by storeAliases.
+func (chart *Chart) loadAliases(fileSys filesys.FileSystem, path string) error {
+	if !fileSys.Exists(path) {
+		return nil
+	}
+
+	body, err := fileSys.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read values aliases: %w", err)
+	}
+
+	if err := yaml.Unmarshal(body, &chart.aliases); err != nil {
+		return fmt.Errorf("unable to parse values aliases: %w", err)
+	}
+
+	return nil
+}
+
+// storeAliases writes the names of the values by the names of the
+// variables.
+func (chart *Chart) storeAliases(fileSys filesys.FileSystem, path string) error {
+	names, err := chart.valueNames()
+	if err != nil {
+		return err
+	}
+
+	aliases := map[string]string{}
+	for name, valueName := range names {
+		aliases[name] = strings.Join(valueName, ".")
+	}
+
+	body, err := yaml.Marshal(aliases)
+	if err != nil {
+		return fmt.Errorf("unable to serialize values aliases: %w", err)
+	}
+
+	if err := fileSys.WriteFile(path, body); err != nil {
+		return fmt.Errorf("unable to store values aliases: %w", err)
+	}
+
+	return nil
+}
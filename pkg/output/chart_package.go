@@ -0,0 +1,325 @@
+package output
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	chartRepoIndexFile = "index.yaml"
+	chartFileMode      = 0o644
+
+	helmConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+	helmLayerMediaType  = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+)
+
+// chartRepoIndex is index.yaml of the Helm repository, the entries are kept
+// as read to preserve the fields of the other charts.
+type chartRepoIndex struct {
+	APIVersion string                      `yaml:"apiVersion"`
+	Entries    map[string][]map[string]any `yaml:"entries"`
+}
+
+// storePackage stores the archive of the chart with the repository index,
+// and the OCI image layout with the chart.
+func (out *ChartOutput) storePackage(fileSys filesys.FileSystem, chartDir string) error {
+	packageDir := out.spec.GetPackageDir()
+	ociLayout := out.spec.GetOciLayout()
+
+	if len(packageDir) == 0 && len(ociLayout) == 0 {
+		return nil
+	}
+
+	archive, err := chartArchive(fileSys, chartDir, out.HelmChart.Name)
+	if err != nil {
+		return err
+	}
+
+	chartBody, err := fileSys.ReadFile(filepath.Join(chartDir, "Chart.yaml"))
+	if err != nil {
+		return fmt.Errorf("unable to read chart: %w", err)
+	}
+
+	chartMeta := map[string]any{}
+	if err := yaml.Unmarshal(chartBody, &chartMeta); err != nil {
+		return fmt.Errorf("unable to parse chart: %w", err)
+	}
+
+	if len(packageDir) > 0 {
+		if err := out.storeArchive(fileSys, packageDir, archive, chartMeta); err != nil {
+			return err
+		}
+	}
+
+	if len(ociLayout) > 0 {
+		if err := out.storeOCILayout(fileSys, ociLayout, archive, chartMeta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listFiles returns the paths of the files in the directory relative to it.
+func listFiles(fileSys filesys.FileSystem, dir string) ([]string, error) {
+	files := []string{}
+	root := ""
+
+	// the walked paths are absolute with the in-memory file systems, so the
+	// files are named relative to the first walked path, the directory
+	err := fileSys.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if len(root) == 0 {
+			root = path
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		files = append(files, relPath)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s: %w", dir, err)
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// chartArchive returns the chart packaged as by helm package, with the
+// timestamps reset so the archives of the same chart are identical.
+func chartArchive(fileSys filesys.FileSystem, chartDir, name string) ([]byte, error) {
+	files, err := listFiles(fileSys, chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	gzipWriter := gzip.NewWriter(buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, file := range files {
+		body, err := fileSys.ReadFile(filepath.Join(chartDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read chart file: %w", err)
+		}
+
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(name, filepath.ToSlash(file)),
+			Mode:     chartFileMode,
+			Size:     int64(len(body)),
+			ModTime:  time.Unix(0, 0),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to archive chart: %w", err)
+		}
+
+		if _, err := tarWriter.Write(body); err != nil {
+			return nil, fmt.Errorf("unable to archive chart: %w", err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("unable to archive chart: %w", err)
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("unable to archive chart: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// storeArchive stores the archive in the package dir and adds the chart
+// version to index.yaml, replacing the entry of the same version.
+//
+//nolint:lll
+func (out *ChartOutput) storeArchive(fileSys filesys.FileSystem, packageDir string, archive []byte, chartMeta map[string]any) error {
+	archiveName := fmt.Sprintf("%s-%s.tgz", out.HelmChart.Name, out.HelmChart.Version)
+
+	if err := fileSys.MkdirAll(packageDir); err != nil {
+		return fmt.Errorf("unable to create package dir: %w", err)
+	}
+
+	if err := fileSys.WriteFile(filepath.Join(packageDir, archiveName), archive); err != nil {
+		return fmt.Errorf("unable to store chart archive: %w", err)
+	}
+
+	indexPath := filepath.Join(packageDir, chartRepoIndexFile)
+	index := &chartRepoIndex{}
+
+	if fileSys.Exists(indexPath) {
+		body, err := fileSys.ReadFile(indexPath)
+		if err != nil {
+			return fmt.Errorf("unable to read repository index: %w", err)
+		}
+
+		if err := yaml.Unmarshal(body, index); err != nil {
+			return fmt.Errorf("unable to parse repository index: %w", err)
+		}
+	}
+
+	index.APIVersion = "v1"
+	if index.Entries == nil {
+		index.Entries = map[string][]map[string]any{}
+	}
+
+	url := archiveName
+	if baseURL := out.spec.GetPackageUrl(); len(baseURL) > 0 {
+		url = baseURL + "/" + archiveName
+	}
+
+	entry := map[string]any{}
+	for key, value := range chartMeta {
+		entry[key] = value
+	}
+
+	entry["digest"] = digest.FromBytes(archive).Encoded()
+	entry["urls"] = []string{url}
+
+	versions := slices.DeleteFunc(index.Entries[out.HelmChart.Name], func(existing map[string]any) bool {
+		return existing["version"] == out.HelmChart.Version
+	})
+	index.Entries[out.HelmChart.Name] = append([]map[string]any{entry}, versions...)
+
+	body, err := yaml.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("unable to serialize repository index: %w", err)
+	}
+
+	if err := fileSys.WriteFile(indexPath, body); err != nil {
+		return fmt.Errorf("unable to store repository index: %w", err)
+	}
+
+	return nil
+}
+
+// storeOCILayout stores the chart as the OCI artifact pushed by helm push,
+// tagged by the chart version in index.json of the layout.
+//
+//nolint:lll
+func (out *ChartOutput) storeOCILayout(fileSys filesys.FileSystem, layoutDir string, archive []byte, chartMeta map[string]any) error {
+	config, err := json.Marshal(chartMeta)
+	if err != nil {
+		return fmt.Errorf("unable to serialize chart config: %w", err)
+	}
+
+	configDesc, err := storeBlob(fileSys, layoutDir, helmConfigMediaType, config)
+	if err != nil {
+		return err
+	}
+
+	layerDesc, err := storeBlob(fileSys, layoutDir, helmLayerMediaType, archive)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2}, //nolint:mnd
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+		Annotations: map[string]string{
+			ocispec.AnnotationTitle:   out.HelmChart.Name,
+			ocispec.AnnotationVersion: out.HelmChart.Version,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to serialize chart manifest: %w", err)
+	}
+
+	manifestDesc, err := storeBlob(fileSys, layoutDir, ocispec.MediaTypeImageManifest, manifest)
+	if err != nil {
+		return err
+	}
+
+	manifestDesc.Annotations = map[string]string{ocispec.AnnotationRefName: out.HelmChart.Version}
+
+	layout, err := json.Marshal(ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion})
+	if err != nil {
+		return fmt.Errorf("unable to serialize OCI layout: %w", err)
+	}
+
+	if err := fileSys.WriteFile(filepath.Join(layoutDir, ocispec.ImageLayoutFile), layout); err != nil {
+		return fmt.Errorf("unable to store OCI layout: %w", err)
+	}
+
+	indexPath := filepath.Join(layoutDir, ocispec.ImageIndexFile)
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2}, //nolint:mnd
+		MediaType: ocispec.MediaTypeImageIndex,
+	}
+
+	if fileSys.Exists(indexPath) {
+		body, err := fileSys.ReadFile(indexPath)
+		if err != nil {
+			return fmt.Errorf("unable to read OCI index: %w", err)
+		}
+
+		if err := json.Unmarshal(body, &index); err != nil {
+			return fmt.Errorf("unable to parse OCI index: %w", err)
+		}
+	}
+
+	index.Manifests = append(slices.DeleteFunc(index.Manifests, func(desc ocispec.Descriptor) bool {
+		return desc.Annotations[ocispec.AnnotationRefName] == out.HelmChart.Version
+	}), manifestDesc)
+
+	body, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize OCI index: %w", err)
+	}
+
+	if err := fileSys.WriteFile(indexPath, append(body, '\n')); err != nil {
+		return fmt.Errorf("unable to store OCI index: %w", err)
+	}
+
+	return nil
+}
+
+// storeBlob stores the content addressed blob of the OCI layout.
+func storeBlob(fileSys filesys.FileSystem, layoutDir, mediaType string, body []byte) (ocispec.Descriptor, error) {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(body),
+		Size:      int64(len(body)),
+	}
+
+	blobDir := filepath.Join(layoutDir, ocispec.ImageBlobsDir, desc.Digest.Algorithm().String())
+	if err := fileSys.MkdirAll(blobDir); err != nil {
+		return desc, fmt.Errorf("unable to create OCI blobs dir: %w", err)
+	}
+
+	if err := fileSys.WriteFile(filepath.Join(blobDir, desc.Digest.Encoded()), body); err != nil {
+		return desc, fmt.Errorf("unable to store OCI blob: %w", err)
+	}
+
+	return desc, nil
+}
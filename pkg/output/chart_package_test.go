@@ -0,0 +1,170 @@
+package output_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func archiveFiles(t *testing.T, archive []byte) []string {
+	t.Helper()
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{}
+	tarReader := tar.NewReader(gzipReader)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files = append(files, header.Name)
+	}
+
+	return files
+}
+
+func TestChartPackage(t *testing.T) {
+	out, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:       "myapp",
+		Version:    "0.1.0",
+		PackageDir: proto.String("repo"),
+		PackageUrl: proto.String("https://charts.example.com"),
+		OciLayout:  proto.String("oci"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+
+	existingIndex := `apiVersion: v1
+entries:
+  other:
+  - name: other
+    version: 1.0.0
+    urls: [other-1.0.0.tgz]
+`
+	if err := gotFs.WriteFile("repo/index.yaml", []byte(existingIndex)); err != nil {
+		t.Fatal(err)
+	}
+
+	archives := [][]byte{}
+
+	for range 2 {
+		if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+			t.Fatal(err)
+		}
+
+		archive, err := gotFs.ReadFile("repo/myapp-0.1.0.tgz")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		archives = append(archives, archive)
+	}
+
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("archives of the same chart differ")
+	}
+
+	gotFiles := archiveFiles(t, archives[0])
+	for _, want := range []string{"myapp/Chart.yaml", "myapp/values.yaml", "myapp/templates/myapp-deployment.yaml"} {
+		if !slices.Contains(gotFiles, want) {
+			t.Errorf("want %s in %v", want, gotFiles)
+		}
+	}
+
+	sum := sha256.Sum256(archives[0])
+	digest := hex.EncodeToString(sum[:])
+
+	indexBody, err := gotFs.ReadFile("repo/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantIndex := `apiVersion: v1
+entries:
+  myapp:
+  - apiVersion: v2
+    digest: ` + digest + `
+    name: myapp
+    urls:
+    - https://charts.example.com/myapp-0.1.0.tgz
+    version: 0.1.0
+  other:
+  - name: other
+    urls:
+    - other-1.0.0.tgz
+    version: 1.0.0
+`
+	if diff := cmp.Diff(wantIndex, string(indexBody)); diff != "" {
+		t.Errorf("index mismatch, -want +got:\n%s", diff)
+	}
+
+	ociIndexBody, err := gotFs.ReadFile("oci/index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ociIndex struct {
+		Manifests []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(ociIndexBody, &ociIndex); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ociIndex.Manifests) != 1 || ociIndex.Manifests[0].Annotations["org.opencontainers.image.ref.name"] != "0.1.0" {
+		t.Fatalf("unexpected OCI index:\n%s", ociIndexBody)
+	}
+
+	manifestBody, err := gotFs.ReadFile("oci/blobs/sha256/" + ociIndex.Manifests[0].Digest[len("sha256:"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := yaml.Parse(string(manifestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layerDigest, err := manifest.GetString("layers[0].digest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if layerDigest != "sha256:"+digest {
+		t.Errorf("want layer sha256:%s, got %s", digest, layerDigest)
+	}
+
+	if !gotFs.Exists("oci/oci-layout") {
+		t.Error("oci-layout is missing")
+	}
+}
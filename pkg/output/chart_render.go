@@ -0,0 +1,199 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var errInvalidValues = errors.New("values do not match the chart schema")
+
+// renderChart renders the chart stored by Chart.Store with the values, as
+// helm template --include-crds does.
+func renderChart(fileSys filesys.FileSystem, chartDir string, values map[string]any) ([]*yaml.RNode, error) {
+	defaultsBody, err := fileSys.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read chart values: %w", err)
+	}
+
+	defaults := map[string]any{}
+	if err := yaml.Unmarshal(defaultsBody, &defaults); err != nil {
+		return nil, fmt.Errorf("unable to parse chart values: %w", err)
+	}
+
+	files, err := listFiles(fileSys, chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := template.New(chartDir).Option("missingkey=zero")
+	tpl.Funcs(chartTemplateFuncs(tpl))
+
+	templates := []string{}
+	body := bytes.NewBuffer(nil)
+
+	for _, file := range files {
+		content, err := fileSys.ReadFile(filepath.Join(chartDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", file, err)
+		}
+
+		switch dir, name := filepath.Split(filepath.ToSlash(file)); {
+		case dir == "crds/":
+			body.WriteString("---\n")
+			body.Write(content)
+		case dir == "templates/":
+			if _, err := tpl.New(file).Parse(string(content)); err != nil {
+				return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+			}
+
+			if !strings.HasPrefix(name, "_") {
+				templates = append(templates, file)
+			}
+		}
+	}
+
+	data := map[string]any{"Values": coalesceValues(defaults, values)}
+	if err := validateValues(fileSys, chartDir, data["Values"]); err != nil {
+		return nil, err
+	}
+
+	for _, name := range templates {
+		rendered := bytes.NewBuffer(nil)
+		if err := tpl.ExecuteTemplate(rendered, name, data); err != nil {
+			return nil, fmt.Errorf("unable to render %s: %w", name, err)
+		}
+
+		body.WriteString("---\n")
+		body.WriteString(strings.ReplaceAll(rendered.String(), "<no value>", ""))
+		body.WriteString("\n")
+	}
+
+	reader := kio.ByteReader{Reader: body, OmitReaderAnnotations: true}
+
+	rnodes, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse rendered chart: %w", err)
+	}
+
+	return rnodes, nil
+}
+
+// verifyChart renders the chart with the values of every cluster, layered
+// in order as helm does with the values files, and compares the resources to
+// the source ones.
+//
+//nolint:lll
+func verifyChart(fileSys filesys.FileSystem, resources *types.ClusterResources, chartDir string, values map[types.ClusterID][]map[string]any) error {
+	errs := []error{}
+
+	for clusterID, cluster := range resources.Clusters.All() {
+		clusterValues := map[string]any{}
+		for _, layer := range values[clusterID] {
+			clusterValues = coalesceValues(clusterValues, layer)
+		}
+
+		rendered, err := renderChart(fileSys, chartDir, clusterValues)
+		if err != nil {
+			return fmt.Errorf("cluster %s: %w", cluster.Name, err)
+		}
+
+		errs = append(errs, verifyCluster(resources, clusterID, rendered))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateValues validates the values by values.schema.json of the chart,
+// if any, as helm does.
+func validateValues(fileSys filesys.FileSystem, chartDir string, values any) error {
+	schemaPath := filepath.Join(chartDir, "values.schema.json")
+	if !fileSys.Exists(schemaPath) {
+		return nil
+	}
+
+	schemaBody, err := fileSys.ReadFile(schemaPath)
+	if err != nil {
+		return fmt.Errorf("unable to read values schema: %w", err)
+	}
+
+	schema := &jsonschema.Schema{}
+	if err := json.Unmarshal(schemaBody, schema); err != nil {
+		return fmt.Errorf("unable to parse values schema: %w", err)
+	}
+
+	// the keywords of the generated draft-07 schema are the same in the
+	// 2020-12 draft supported by the validator
+	schema.Schema = ""
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("invalid values schema: %w", err)
+	}
+
+	// the values are validated as JSON, e.g. with the numbers as float64
+	valuesBody, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("unable to serialize values: %w", err)
+	}
+
+	var instance any
+	if err := json.Unmarshal(valuesBody, &instance); err != nil {
+		return fmt.Errorf("unable to parse values: %w", err)
+	}
+
+	if err := resolved.Validate(instance); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidValues, err)
+	}
+
+	return nil
+}
+
+// coalesceValues merges the values into the defaults as helm does.
+func coalesceValues(defaults, values map[string]any) map[string]any {
+	result := maps.Clone(defaults)
+
+	for key, value := range values {
+		valueMap, valueIsMap := value.(map[string]any)
+		defaultMap, defaultIsMap := result[key].(map[string]any)
+
+		if valueIsMap && defaultIsMap {
+			result[key] = coalesceValues(defaultMap, valueMap)
+		} else {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// chartTemplateFuncs returns the sprig functions and include, as helm does
+// without the functions reading the environment.
+func chartTemplateFuncs(tpl *template.Template) template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+
+	funcs["include"] = func(name string, data any) (string, error) {
+		buffer := bytes.NewBuffer(nil)
+		if err := tpl.ExecuteTemplate(buffer, name, data); err != nil {
+			return "", err //nolint:wrapcheck
+		}
+
+		return buffer.String(), nil
+	}
+
+	return funcs
+}
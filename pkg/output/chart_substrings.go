@@ -0,0 +1,220 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/resource"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	clusterNameValue = "clusterName"
+	clusterTagsValue = "clusterTags"
+
+	// clusterNameMarker replaces the cluster names in the values until the
+	// templates are rendered.
+	clusterNameMarker = "\x00"
+
+	// substringDelimiters are the characters the common prefixes and
+	// suffixes end at, so the templated parts are whole words.
+	substringDelimiters = "./:-_@=, " + clusterNameMarker
+)
+
+// builtinValues returns the global values set for every instance of the
+// chart.
+func (chart *Chart) builtinValues(cluster types.ClusterID) map[string]any {
+	if !chart.spec.GetSubstringTemplates() {
+		return nil
+	}
+
+	tags := chart.clusters.Cluster(cluster).Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return map[string]any{
+		clusterNameValue: chart.clusters.Cluster(cluster).Name,
+		clusterTagsValue: slices.Sorted(slices.Values(tags)),
+	}
+}
+
+// builtinSchemas returns the schemas of the built-in global values.
+func (chart *Chart) builtinSchemas() map[string]any {
+	if !chart.spec.GetSubstringTemplates() {
+		return nil
+	}
+
+	return map[string]any{
+		clusterNameValue: map[string]any{
+			"description": "Name of the cluster, set by the instances of the chart",
+			"type":        "string",
+		},
+		clusterTagsValue: map[string]any{
+			"description": "Tags of the cluster, set by the instances of the chart",
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+		},
+	}
+}
+
+// commonAffixes returns the longest prefix and suffix shared by the values,
+// ending at the delimiters and not overlapping in any of the values.
+func commonAffixes(values []string) (string, string) {
+	runes := [][]rune{}
+	for _, value := range values {
+		runes = append(runes, []rune(value))
+	}
+
+	shortest := slices.MinFunc(runes, func(a, b []rune) int { return len(a) - len(b) })
+
+	prefixLen := 0
+	for prefixLen < len(shortest) && allRunesEqual(runes, func(value []rune) rune { return value[prefixLen] }) {
+		prefixLen++
+	}
+
+	for prefixLen > 0 && prefixLen < len(shortest) && !strings.ContainsRune(substringDelimiters, shortest[prefixLen-1]) {
+		prefixLen--
+	}
+
+	suffixLen := 0
+	suffixRune := func(value []rune) rune { return value[len(value)-suffixLen-1] }
+	for suffixLen < len(shortest)-prefixLen && allRunesEqual(runes, suffixRune) {
+		suffixLen++
+	}
+
+	for suffixLen > 0 && suffixLen < len(shortest)-prefixLen &&
+		!strings.ContainsRune(substringDelimiters, shortest[len(shortest)-suffixLen]) {
+		suffixLen--
+	}
+
+	return string(shortest[:prefixLen]), string(shortest[len(shortest)-suffixLen:])
+}
+
+func allRunesEqual(values [][]rune, at func(value []rune) rune) bool {
+	for _, value := range values[1:] {
+		if at(value) != at(values[0]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stringValues returns the values by the clusters, unless some of the
+// values are not single line strings.
+func stringValues(variants []*resource.ValueGroup) (map[types.ClusterID]string, bool) {
+	values := map[types.ClusterID]string{}
+
+	for _, variant := range variants {
+		value := variant.Value
+		isString := value.Kind == yaml.ScalarNode && value.ShortTag() == yaml.NodeTagString
+		if !isString || strings.ContainsAny(value.Value, "\n"+clusterNameMarker) {
+			return nil, false
+		}
+
+		for _, cluster := range variant.Clusters {
+			values[cluster] = value.Value
+		}
+	}
+
+	return values, true
+}
+
+// substringValue templates the parts of the string values differing between
+// the clusters: the cluster names are referred to by the clusterName value,
+// and the rest of the differing parts are set by the variable.
+//
+//nolint:lll
+func (chart *Chart) substringValue(newVariable func() *chartVariable, variants []*resource.ValueGroup) (*yaml.Node, bool) {
+	values, isString := stringValues(variants)
+	if !isString {
+		return nil, false
+	}
+
+	withNames := map[types.ClusterID]string{}
+	for cluster, value := range values {
+		name := chart.clusters.Cluster(cluster).Name
+		if len(name) > 0 {
+			value = strings.ReplaceAll(value, name, clusterNameMarker)
+		}
+
+		withNames[cluster] = value
+	}
+
+	distinct := slices.Compact(slices.Sorted(maps.Values(withNames)))
+	if len(distinct) == 1 {
+		return chart.templateNode(distinct[0]), true
+	}
+
+	for _, byCluster := range []map[types.ClusterID]string{withNames, values} {
+		prefix, suffix := commonAffixes(slices.Collect(maps.Values(byCluster)))
+		if len(prefix)+len(suffix) == 0 {
+			continue
+		}
+
+		middles := map[string][]types.ClusterID{}
+		for cluster, value := range byCluster {
+			middle := strings.TrimSuffix(strings.TrimPrefix(value, prefix), suffix)
+			middles[middle] = append(middles[middle], cluster)
+		}
+
+		if slices.ContainsFunc(slices.Collect(maps.Keys(middles)), func(middle string) bool {
+			return strings.Contains(middle, clusterNameMarker)
+		}) {
+			continue
+		}
+
+		variable := newVariable()
+
+		for middle, clusters := range middles {
+			slices.Sort(clusters)
+			preset := chart.values(clusters)
+			preset[variable.name] = yaml.NewStringRNode(middle).YNode()
+		}
+
+		return chart.templateNode(prefix, chart.placeholder("REF", variable), suffix), true
+	}
+
+	return nil, false
+}
+
+// templateNode returns the quoted string rendered by the template from the
+// literal parts, with the cluster names referred to by the clusterName
+// value, and the value references between them. The literal parts are the
+// quoted printf format, so they are not executed by Helm, e.g. {{ in them.
+func (chart *Chart) templateNode(parts ...string) *yaml.Node {
+	format := strings.Builder{}
+	args := []string{}
+
+	for idx, part := range parts {
+		if idx%2 == 1 {
+			format.WriteString("%s")
+			args = append(args, "("+part+")")
+
+			continue
+		}
+
+		for nameIdx, literal := range strings.Split(part, clusterNameMarker) {
+			if nameIdx > 0 {
+				format.WriteString("%s")
+				args = append(args, ".Values.global."+clusterNameValue)
+			}
+
+			format.WriteString(strings.ReplaceAll(literal, "%", "%%"))
+		}
+	}
+
+	node := yaml.NewScalarRNode("").YNode()
+	node.LineComment = fmt.Sprintf(
+		"HELM%s: {{ printf %s | quote }}",
+		chart.token,
+		strings.Join(append([]string{strconv.Quote(format.String())}, args...), " "),
+	)
+
+	return node
+}
@@ -2,6 +2,7 @@ package output_test
 
 import (
 	"embed"
+	"strings"
 	"testing"
 
 	"github.com/Mirantis/ktl/pkg/apis"
@@ -9,6 +10,7 @@ import (
 	"github.com/Mirantis/ktl/pkg/output"
 	"github.com/Mirantis/ktl/pkg/types"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/kustomize/kyaml/resid"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -85,3 +87,374 @@ func TestChart(t *testing.T) {
 		t.Errorf("instances mismatch, +got -want:\n%s", diff)
 	}
 }
+
+func TestChartNestedValues(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+	prodB := clusters.Add(types.Cluster{Name: "prod-b", Tags: []string{"prod"}})
+	resources := map[types.ClusterID]*yaml.RNode{
+		devA:  yaml.MustParse(appDevA),
+		prodA: yaml.MustParse(appProdA),
+		prodB: yaml.MustParse(appProdB),
+	}
+
+	meta := types.HelmChart{Name: "myapp", Version: "v0.1"}
+	chart := output.NewChart(meta, &apis.HelmChartOutput{
+		ValuesNaming: proto.String("nested"),
+		ShortAliases: proto.Bool(true),
+	}, clusters)
+
+	if err := chart.Add(resid.FromRNode(resources[devA]), resources); err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := chart.Store(gotFs, "."); err != nil {
+		t.Fatal(err)
+	}
+
+	gotValues, err := gotFs.ReadFile("values.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantValues := `global: {}
+preset_values:
+  dev:
+    myapp:
+      deployment:
+        image: myapp:v1.2-345
+        metadata:
+          labels:
+            env: dev
+        spec:
+          template:
+            spec:
+              containers:
+                myapp:
+                  args:
+                    enabled: true
+  prod:
+    myapp:
+      deployment:
+        image: myapp:v1.1
+        metadata:
+          labels:
+            env: prod
+presets: []
+`
+	if diff := cmp.Diff(wantValues, string(gotValues)); diff != "" {
+		t.Errorf("values mismatch, -want +got:\n%s", diff)
+	}
+
+	gotTemplate, err := gotFs.ReadFile("templates/myapp-deployment.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`replicas: {{ dig "myapp" "deployment" "replicas" nil .Values.global }}`,
+		`image: {{ dig "myapp" "deployment" "image" nil .Values.global }}`,
+		`{{- end }} # myapp.deployment.spec.template.spec.containers.myapp.args.enabled`,
+	} {
+		if !strings.Contains(string(gotTemplate), want) {
+			t.Errorf("want %q in:\n%s", want, gotTemplate)
+		}
+	}
+
+	gotInstance := chart.Instance(prodB).ValuesInline
+	wantInstance := map[string]any{
+		"presets": []string{"prod"},
+		"global": map[string]any{
+			"myapp": map[string]any{"deployment": map[string]any{"replicas": 5.0}},
+		},
+	}
+
+	if diff := cmp.Diff(wantInstance, gotInstance); diff != "" {
+		t.Errorf("instance mismatch, -want +got:\n%s", diff)
+	}
+}
+
+func TestChartValuesCollision(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+	resources := map[types.ClusterID]*yaml.RNode{
+		devA:  yaml.MustParse(appDevA),
+		prodA: yaml.MustParse(appProdA),
+	}
+
+	chart := output.NewChart(types.HelmChart{Name: "myapp", Version: "v0.1"}, &apis.HelmChartOutput{
+		ValuesNaming: proto.String("nested"),
+		ValuesAliases: map[string]string{
+			"myapp/Deployment/myapp.metadata.labels.env":                              "myapp.env",
+			"myapp/Deployment/myapp.spec.template.spec.containers.[name=myapp].image": "myapp.env.image",
+		},
+	}, clusters)
+
+	if err := chart.Add(resid.FromRNode(resources[devA]), resources); err != nil {
+		t.Fatal(err)
+	}
+
+	err := chart.Store(filesys.MakeFsInMemory(), ".")
+	if err == nil || !strings.Contains(err.Error(), "values collision") {
+		t.Errorf("want values collision, got %v", err)
+	}
+}
+
+func TestChartSubstringTemplates(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+	prodB := clusters.Add(types.Cluster{Name: "prod-b", Tags: []string{"prod"}})
+
+	configMap := func(cluster, env, image string) *yaml.RNode {
+		return yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myapp
+data:
+  host: api.` + cluster + `.example.com
+  url: https://` + env + `.example.com/v1
+  image: ` + image + `
+`)
+	}
+
+	resources := map[types.ClusterID]*yaml.RNode{
+		devA:  configMap("dev-a", "dev", "myapp:v1.2"),
+		prodA: configMap("prod-a", "prod", "other:v1"),
+		prodB: configMap("prod-b", "prod", "other:v1"),
+	}
+
+	meta := types.HelmChart{Name: "myapp", Version: "v0.1"}
+	chart := output.NewChart(meta, &apis.HelmChartOutput{
+		SubstringTemplates: proto.Bool(true),
+	}, clusters)
+
+	if err := chart.Add(resid.FromRNode(resources[devA]), resources); err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := chart.Store(gotFs, "."); err != nil {
+		t.Fatal(err)
+	}
+
+	gotValues, err := gotFs.ReadFile("values.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantValues := `global: {}
+preset_values:
+  dev:
+    ConfigMap/myapp.data.image: myapp:v1.2
+    ConfigMap/myapp.data.url: dev
+  prod:
+    ConfigMap/myapp.data.image: other:v1
+    ConfigMap/myapp.data.url: prod
+presets: []
+`
+	if diff := cmp.Diff(wantValues, string(gotValues)); diff != "" {
+		t.Errorf("values mismatch, -want +got:\n%s", diff)
+	}
+
+	gotTemplate, err := gotFs.ReadFile("templates/myapp-configmap.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`host: {{ printf "api.%s.example.com" .Values.global.clusterName | quote }}`,
+		`url: {{ printf "https://%s.example.com/v1" (index .Values.global "ConfigMap/myapp.data.url") | quote }}`,
+		`image: {{ index .Values.global "ConfigMap/myapp.data.image" }}`,
+	} {
+		if !strings.Contains(string(gotTemplate), want) {
+			t.Errorf("want %q in:\n%s", want, gotTemplate)
+		}
+	}
+
+	gotInstance := chart.Instance(prodB).ValuesInline
+	wantInstance := map[string]any{
+		"presets": []string{"prod"},
+		"global": map[string]any{
+			"clusterName": "prod-b",
+			"clusterTags": []string{"prod"},
+		},
+	}
+
+	if diff := cmp.Diff(wantInstance, gotInstance); diff != "" {
+		t.Errorf("instance mismatch, -want +got:\n%s", diff)
+	}
+}
+
+func TestChartSubstringTemplatesQuoted(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a"})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a"})
+
+	configMap := func(cluster string) *yaml.RNode {
+		rnode := yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: myapp\n")
+		rnode.SetDataMap(map[string]string{
+			"msg":     "msg: " + cluster,
+			"release": "{{ .Release.Name }} on " + cluster,
+			"percent": "100% " + strings.TrimSuffix(cluster, "-a"),
+		})
+
+		return rnode
+	}
+
+	resources := &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(configMap("dev-a")): {devA: configMap("dev-a"), prodA: configMap("prod-a")},
+		},
+	}
+
+	out, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:               "myapp",
+		Version:            "0.1.0",
+		SubstringTemplates: proto.Bool(true),
+		Verify:             proto.Bool(true),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the chart is verified by rendering the templates
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, resources); err != nil {
+		t.Fatal(err)
+	}
+
+	gotTemplate, err := gotFs.ReadFile("charts/myapp/templates/myapp-configmap.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`msg: {{ printf "msg: %s" .Values.global.clusterName | quote }}`,
+		`release: {{ printf "{{ .Release.Name }} on %s" .Values.global.clusterName | quote }}`,
+		`percent: {{ printf "100%% %s" (index .Values.global "ConfigMap/myapp.data.percent") | quote }}`,
+	} {
+		if !strings.Contains(string(gotTemplate), want) {
+			t.Errorf("want %q in:\n%s", want, gotTemplate)
+		}
+	}
+}
+
+func TestChartIntOrStringValues(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a"})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a"})
+
+	deployment := func(maxSurge, cpu string) *yaml.RNode {
+		return yaml.MustParse(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myapp
+spec:
+  strategy:
+    rollingUpdate:
+      maxSurge: ` + maxSurge + `
+  template:
+    spec:
+      containers:
+      - name: myapp
+        resources:
+          limits:
+            cpu: ` + cpu + `
+`)
+	}
+
+	byCluster := map[types.ClusterID]*yaml.RNode{
+		devA:  deployment("1", "1"),
+		prodA: deployment("25%", "500m"),
+	}
+	resources := &types.ClusterResources{
+		Clusters:  clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{resid.FromRNode(byCluster[devA]): byCluster},
+	}
+
+	out, err := output.New(&apis.Output{HelmChart: &apis.HelmChartOutput{
+		Name:    "myapp",
+		Version: "0.1.0",
+		Verify:  proto.Bool(true),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the integer values are valid by the schema of the chart
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, resources); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := gotFs.ReadFile("charts/myapp/values.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := struct {
+		Properties struct {
+			Global struct {
+				Properties map[string]struct {
+					Description string `json:"description"`
+					Type        any    `json:"type"`
+				} `json:"properties"`
+			} `json:"global"`
+		} `json:"properties"`
+	}{}
+	if err := yaml.Unmarshal(body, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string][]any{
+		"Deployment/myapp.spec.strategy.rollingUpdate.maxSurge":                            {"integer", "string"},
+		"Deployment/myapp.spec.template.spec.containers.[name=myapp].resources.limits.cpu": {"integer", "number", "string"},
+	} {
+		got := schema.Properties.Global.Properties[name]
+		if diff := cmp.Diff(want, got.Type); diff != "" {
+			t.Errorf("%s type mismatch, -want +got:\n%s", name, diff)
+		}
+
+		if strings.Contains(got.Description, "IntOrString is a type") || strings.Contains(got.Description, "Quantity is") {
+			t.Errorf("%s has the description of its type: %s", name, got.Description)
+		}
+	}
+}
+
+func TestChartVerify(t *testing.T) {
+	chartSpec := func(spec *apis.HelmChartOutput) *apis.Output {
+		spec.Name, spec.Version, spec.Verify = "myapp", "0.1.0", proto.Bool(true)
+
+		return &apis.Output{HelmChart: spec}
+	}
+
+	for name, spec := range map[string]*apis.Output{
+		"path":      chartSpec(&apis.HelmChartOutput{}),
+		"nested":    chartSpec(&apis.HelmChartOutput{ValuesNaming: proto.String("nested")}),
+		"substring": chartSpec(&apis.HelmChartOutput{SubstringTemplates: proto.Bool(true)}),
+		"values": chartSpec(&apis.HelmChartOutput{
+			Deployment: proto.String("values"), LayeredValues: proto.Bool(true), SubstringTemplates: proto.Bool(true),
+		}),
+		"helmfile": chartSpec(&apis.HelmChartOutput{
+			Deployment: proto.String("helmfile"), LayeredValues: proto.Bool(true), ValuesNaming: proto.String("nested"),
+		}),
+		"fluxHelmRelease": chartSpec(&apis.HelmChartOutput{Deployment: proto.String("fluxHelmRelease")}),
+		"components":      {KustomizeComponents: &apis.KustomizeComponentsOutput{Verify: proto.Bool(true)}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, err := output.New(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := out.Store(&types.Env{FileSys: filesys.MakeFsInMemory()}, chartTestResources()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
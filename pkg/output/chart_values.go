@@ -0,0 +1,275 @@
+package output
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/Mirantis/ktl/pkg/resource"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+const (
+	chartToggleValue = "enabled"
+	chartToggleType  = "toggle"
+	chartAnyType     = "any"
+
+	intOrStringFormat    = "int-or-string"
+	intOrStringExtension = "x-kubernetes-int-or-string"
+	quantityDefinition   = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+)
+
+//go:embed data/chart_readme.md.tpl
+var chartReadmeTpl string
+
+// chartVariable is the templated field of the chart, with the schema of the
+// field, resolved and as declared by the parent field. The toggles only
+// enable the optional fields sharing the value.
+type chartVariable struct {
+	name   string
+	index  int
+	id     resid.ResId
+	path   resource.Query
+	schema *openapi.ResourceSchema
+	field  *spec.Schema
+	toggle bool
+}
+
+// types returns the types of the value: the int-or-string and the quantity
+// fields are typed as strings, but take the numbers as well, e.g. cpu: 1.
+func (variable *chartVariable) types() []string {
+	if variable.toggle {
+		return []string{chartToggleType}
+	}
+
+	if variable.field != nil && strings.HasSuffix(variable.field.Ref.String(), "/"+quantityDefinition) {
+		return []string{"integer", "number", "string"}
+	}
+
+	if variable.schema == nil || variable.schema.Schema == nil {
+		return nil
+	}
+
+	resolved := variable.schema.Schema
+	if intOrString, _ := resolved.Extensions.GetBool(intOrStringExtension); intOrString ||
+		resolved.Format == intOrStringFormat {
+		return []string{"integer", "string"}
+	}
+
+	return resolved.Type
+}
+
+// description returns the description of the field, the descriptions of
+// the referenced types, e.g. of the quantities, are not about the field.
+func (variable *chartVariable) description() string {
+	if variable.field != nil && (len(variable.field.Description) > 0 || len(variable.field.Ref.String()) > 0) {
+		return variable.field.Description
+	}
+
+	if variable.schema == nil || variable.schema.Schema == nil {
+		return ""
+	}
+
+	return variable.schema.Schema.Description
+}
+
+// jsonSchema returns the JSON schema of the value, the fields of unknown
+// types accept any value.
+func (variable *chartVariable) jsonSchema(nested bool) map[string]any {
+	result := map[string]any{}
+
+	if description := variable.description(); len(description) > 0 {
+		result["description"] = description
+	}
+
+	switch fieldTypes := variable.types(); {
+	case variable.toggle && nested:
+		result["type"] = "boolean"
+	case variable.toggle:
+		result["enum"] = []string{chartToggleValue}
+	case len(fieldTypes) == 1:
+		result["type"] = fieldTypes[0]
+	case len(fieldTypes) > 1:
+		result["type"] = fieldTypes
+	}
+
+	return result
+}
+
+func objectSchema() map[string]any {
+	return map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{},
+		"additionalProperties": false,
+	}
+}
+
+// valuesSchema returns the schema of values.yaml, rejecting the values not
+// defined by the chart.
+func (chart *Chart) valuesSchema() (map[string]any, error) {
+	names, err := chart.valueNames()
+	if err != nil {
+		return nil, err
+	}
+
+	values := objectSchema()
+
+	for name, variable := range chart.variables {
+		valueName := names[name]
+		parent := values
+
+		for _, part := range valueName[:len(valueName)-1] {
+			properties, _ := parent["properties"].(map[string]any)
+
+			child, found := properties[part].(map[string]any)
+			if !found {
+				child = objectSchema()
+				properties[part] = child
+			}
+
+			parent = child
+		}
+
+		properties, _ := parent["properties"].(map[string]any)
+		properties[valueName[len(valueName)-1]] = variable.jsonSchema(chart.nested())
+	}
+
+	// the built-in values are only set by the instances, not by the presets
+	global := values
+	if builtins := chart.builtinSchemas(); builtins != nil {
+		global = objectSchema()
+		properties, _ := global["properties"].(map[string]any)
+		valueProperties, _ := values["properties"].(map[string]any)
+		maps.Copy(properties, valueProperties)
+		maps.Copy(properties, builtins)
+	}
+
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]any{
+			"global": global,
+			"preset_values": map[string]any{
+				"type":                 "object",
+				"additionalProperties": values,
+			},
+			"presets": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		},
+		"additionalProperties": false,
+	}, nil
+}
+
+func (chart *Chart) storeValuesSchema(fileSys filesys.FileSystem, dir string) error {
+	schema, err := chart.valuesSchema()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize values schema: %w", err)
+	}
+
+	body = append(body, '\n')
+
+	if err := fileSys.WriteFile(filepath.Join(dir, "values.schema.json"), body); err != nil {
+		return fmt.Errorf("unable to store values schema: %w", err)
+	}
+
+	return nil
+}
+
+type chartReadmeValue struct {
+	Name        string
+	Type        string
+	Description string
+	Presets     []string
+	Clusters    []string
+}
+
+// readmeValues returns the values of the chart with the presets and the
+// clusters setting them.
+func (chart *Chart) readmeValues() ([]chartReadmeValue, error) {
+	names, err := chart.valueNames()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []chartReadmeValue{}
+
+	for _, name := range slices.Sorted(maps.Keys(chart.variables)) {
+		variable := chart.variables[name]
+		value := chartReadmeValue{
+			Name:        strings.Join(names[name], "."),
+			Type:        strings.Join(variable.types(), ", "),
+			Description: strings.ReplaceAll(strings.Join(strings.Fields(variable.description()), " "), "|", `\|`),
+			Presets:     []string{},
+			Clusters:    []string{},
+		}
+
+		if len(value.Type) == 0 {
+			value.Type = chartAnyType
+		}
+
+		for _, presetName := range slices.Sorted(maps.Keys(chart.presetValues)) {
+			_, found := chart.presetValues[presetName][name]
+			if found && !slices.Contains(chart.spec.GetRemovePresets(), presetName) {
+				value.Presets = append(value.Presets, presetName)
+			}
+		}
+
+		for _, clusterID := range chart.clusterIDs {
+			if _, found := chart.inlineValues[clusterID][name]; found {
+				value.Clusters = append(value.Clusters, chart.clusters.Cluster(clusterID).Name)
+			}
+		}
+
+		result = append(result, value)
+	}
+
+	slices.SortFunc(result, func(a, b chartReadmeValue) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return result, nil
+}
+
+func (chart *Chart) storeReadme(fileSys filesys.FileSystem, dir string) error {
+	tpl := template.Must(template.New("chart_readme").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(chartReadmeTpl))
+
+	values, err := chart.readmeValues()
+	if err != nil {
+		return err
+	}
+
+	buffer := bytes.NewBuffer(nil)
+
+	err = tpl.Execute(buffer, map[string]any{
+		"Name":    chart.meta.Name,
+		"Version": chart.meta.Version,
+		"Values":  values,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to render chart readme: %w", err)
+	}
+
+	if err := fileSys.WriteFile(filepath.Join(dir, "README.md"), buffer.Bytes()); err != nil {
+		return fmt.Errorf("unable to store chart readme: %w", err)
+	}
+
+	return nil
+}
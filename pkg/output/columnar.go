@@ -0,0 +1,318 @@
+package output
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	"gopkg.in/inf.v0"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	aggregateCount    = "count"
+	aggregateSum      = "sum"
+	aggregateMin      = "min"
+	aggregateMax      = "max"
+	aggregateDistinct = "distinct"
+
+	descendingPrefix = "-"
+	distinctSep      = ","
+)
+
+var (
+	errUnknownColumn    = errors.New("unknown column")
+	errUnknownAggregate = errors.New("unknown aggregate")
+	errNotANumber       = errors.New("not a number")
+)
+
+// tableRows are the rows of the columnar outputs with the clusters they were
+// produced for.
+type tableRows struct {
+	columns  []ValueRef
+	rows     [][]string
+	clusters []string
+}
+
+func (table *tableRows) column(name string) (int, error) {
+	idx := slices.IndexFunc(table.columns, func(col ValueRef) bool { return col.Name == name })
+	if idx < 0 {
+		return -1, fmt.Errorf("%w: %s", errUnknownColumn, name)
+	}
+
+	return idx, nil
+}
+
+func (table *tableRows) columnIndices(names []string) ([]int, error) {
+	indices := make([]int, 0, len(names))
+
+	for _, name := range names {
+		idx, err := table.column(name)
+		if err != nil {
+			return nil, err
+		}
+
+		indices = append(indices, idx)
+	}
+
+	return indices, nil
+}
+
+// groupBy merges the rows with the same values of the columns, aggregating
+// the other columns. The clusters are kept apart for the pivot.
+func (table *tableRows) groupBy(names []string, byCluster bool) error {
+	keyIndices, err := table.columnIndices(names)
+	if err != nil {
+		return err
+	}
+
+	groups := map[string]int{}
+	grouped := &tableRows{columns: table.columns}
+	values := [][][]string{} // group, column, values
+
+	for rowIdx, row := range table.rows {
+		keyParts := []string{}
+		for _, idx := range keyIndices {
+			keyParts = append(keyParts, row[idx])
+		}
+
+		cluster := ""
+		if byCluster {
+			cluster = table.clusters[rowIdx]
+		}
+
+		key := strings.Join(append(keyParts, cluster), "\x00")
+
+		groupIdx, found := groups[key]
+		if !found {
+			groupIdx = len(grouped.rows)
+			groups[key] = groupIdx
+
+			grouped.rows = append(grouped.rows, slices.Clone(row))
+			grouped.clusters = append(grouped.clusters, cluster)
+			values = append(values, make([][]string, len(row)))
+		}
+
+		for colIdx, value := range row {
+			values[groupIdx][colIdx] = append(values[groupIdx][colIdx], value)
+		}
+	}
+
+	for groupIdx, row := range grouped.rows {
+		for colIdx, col := range table.columns {
+			if slices.Contains(keyIndices, colIdx) {
+				continue
+			}
+
+			row[colIdx], err = aggregate(col.Aggregate, values[groupIdx][colIdx])
+			if err != nil {
+				return fmt.Errorf("column %s: %w", col.Name, err)
+			}
+		}
+	}
+
+	*table = *grouped
+
+	return nil
+}
+
+// pivot lists the values of the column per cluster, the rows are merged by
+// the key columns, the rest of the columns is dropped.
+func (table *tableRows) pivot(name string, keyNames []string, clusters []string) error {
+	pivotIdx, err := table.column(name)
+	if err != nil {
+		return err
+	}
+
+	keyIndices, err := table.columnIndices(keyNames)
+	if err != nil {
+		return err
+	}
+
+	if len(keyNames) == 0 {
+		for colIdx, col := range table.columns {
+			if colIdx != pivotIdx && !strings.Contains(col.Text, types.ClusterPlaceholder) {
+				keyIndices = append(keyIndices, colIdx)
+			}
+		}
+	}
+
+	pivoted := &tableRows{}
+	for _, idx := range keyIndices {
+		pivoted.columns = append(pivoted.columns, table.columns[idx])
+	}
+
+	for _, cluster := range clusters {
+		pivoted.columns = append(pivoted.columns, ValueRef{Name: cluster})
+	}
+
+	groups := map[string]int{}
+	values := [][][]string{} // row, cluster, values
+
+	for rowIdx, row := range table.rows {
+		keyParts := []string{}
+		for _, idx := range keyIndices {
+			keyParts = append(keyParts, row[idx])
+		}
+
+		key := strings.Join(keyParts, "\x00")
+
+		groupIdx, found := groups[key]
+		if !found {
+			groupIdx = len(pivoted.rows)
+			groups[key] = groupIdx
+
+			pivoted.rows = append(pivoted.rows, slices.Concat(keyParts, make([]string, len(clusters))))
+			pivoted.clusters = append(pivoted.clusters, "")
+			values = append(values, make([][]string, len(clusters)))
+		}
+
+		clusterIdx := slices.Index(clusters, table.clusters[rowIdx])
+		if clusterIdx < 0 {
+			continue
+		}
+
+		values[groupIdx][clusterIdx] = append(values[groupIdx][clusterIdx], row[pivotIdx])
+	}
+
+	for groupIdx, row := range pivoted.rows {
+		for clusterIdx := range clusters {
+			row[len(keyIndices)+clusterIdx], _ = aggregate(aggregateDistinct, values[groupIdx][clusterIdx])
+		}
+	}
+
+	*table = *pivoted
+
+	return nil
+}
+
+// sortBy sorts the rows by the columns, the numbers are compared by value.
+func (table *tableRows) sortBy(names []string) error {
+	keys := []int{}
+	descending := []bool{}
+
+	for _, name := range names {
+		idx, err := table.column(strings.TrimPrefix(name, descendingPrefix))
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, idx)
+		descending = append(descending, strings.HasPrefix(name, descendingPrefix))
+	}
+
+	slices.SortStableFunc(table.rows, func(rowa, rowb []string) int {
+		for keyIdx, colIdx := range keys {
+			result := compareValues(rowa[colIdx], rowb[colIdx])
+			if descending[keyIdx] {
+				result = -result
+			}
+
+			if result != 0 {
+				return result
+			}
+		}
+
+		return slices.CompareFunc(rowa, rowb, strings.Compare)
+	})
+
+	return nil
+}
+
+func compareValues(a, b string) int {
+	numa, erra := strconv.ParseFloat(a, 64)
+	numb, errb := strconv.ParseFloat(b, 64)
+
+	if erra == nil && errb == nil {
+		return cmp.Compare(numa, numb)
+	}
+
+	// quantities with suffixes, e.g. 512Mi or 500m
+	qtya, erra := resource.ParseQuantity(a)
+	qtyb, errb := resource.ParseQuantity(b)
+
+	if erra == nil && errb == nil {
+		return qtya.Cmp(qtyb)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// sumValues returns the sum of the decimal numbers, or of the quantities if
+// any value has a suffix, e.g. 512Mi or 500m. The sums are exact, e.g. 0.1
+// and 0.2 add up to 0.3.
+func sumValues(values []string) (string, error) {
+	sum := new(inf.Dec)
+	isDecimal := true
+
+	for _, value := range values {
+		num, isNum := new(inf.Dec).SetString(value)
+		if !isNum {
+			isDecimal = false
+
+			break
+		}
+
+		sum.Add(sum, num)
+	}
+
+	if isDecimal {
+		return sum.String(), nil
+	}
+
+	total := resource.Quantity{}
+
+	for _, value := range values {
+		qty, err := resource.ParseQuantity(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q", errNotANumber, value)
+		}
+
+		total.Add(qty)
+	}
+
+	return total.String(), nil
+}
+
+// ValidateAggregate checks the aggregate of the column is known, the empty
+// aggregate is the default one.
+func ValidateAggregate(name string) error {
+	switch name {
+	case "", aggregateCount, aggregateSum, aggregateMin, aggregateMax, aggregateDistinct:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", errUnknownAggregate, name)
+	}
+}
+
+// aggregate returns the aggregated non-empty values.
+func aggregate(name string, values []string) (string, error) {
+	values = slices.DeleteFunc(slices.Clone(values), func(value string) bool { return value == "" })
+
+	switch name {
+	case aggregateCount:
+		return strconv.Itoa(len(values)), nil
+	case aggregateSum:
+		return sumValues(values)
+	case aggregateMin, aggregateMax:
+		if len(values) == 0 {
+			return "", nil
+		}
+
+		if name == aggregateMin {
+			return slices.MinFunc(values, compareValues), nil
+		}
+
+		return slices.MaxFunc(values, compareValues), nil
+	case "", aggregateDistinct:
+		slices.SortFunc(values, compareValues)
+
+		return strings.Join(slices.Compact(values), distinctSep), nil
+	default:
+		return "", fmt.Errorf("%w: %s", errUnknownAggregate, name)
+	}
+}
@@ -0,0 +1,200 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func columnarTestResources() *types.ClusterResources {
+	clusters := types.NewClusterIndex()
+	devID := clusters.Add(types.Cluster{Name: "dev-a"})
+	prodID := clusters.Add(types.Cluster{Name: "prod-a"})
+
+	return &types.ClusterResources{
+		Clusters: clusters,
+		Records: []types.Record{
+			{Cluster: &devID, Fields: yaml.MustParse("name: web\nimage: web:v2\nreplicas: 1\nmemory: 512Mi\ncpu: 0.1\n")},
+			{Cluster: &devID, Fields: yaml.MustParse("name: api\nimage: api:v2\nreplicas: 2\nmemory: 1Gi\ncpu: 0.2\n")},
+			{Cluster: &devID, Fields: yaml.MustParse("name: db\nimage: db:v1\nreplicas: 1\nmemory: 256Mi\ncpu: 0.3\n")},
+			{Cluster: &prodID, Fields: yaml.MustParse("name: web\nimage: web:v1\nreplicas: 10\nmemory: 2Gi\ncpu: 1\n")},
+			{Cluster: &prodID, Fields: yaml.MustParse("name: api\nimage: api:v1\nreplicas: 3\nmemory: 1536Mi\ncpu: 0.5\n")},
+		},
+	}
+}
+
+func TestColumnarArrange(t *testing.T) {
+	columns := []*apis.ColumnOutput{
+		{Name: "CLUSTER", Text: ptr(types.ClusterPlaceholder)},
+		{Name: "NAME", Field: ptr(".name")},
+		{Name: "IMAGE", Field: ptr(".image")},
+		{Name: "REPLICAS", Field: ptr(".replicas")},
+	}
+
+	tests := []struct {
+		name    string
+		spec    *apis.ColumnarFileOutput
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "sort-numeric-descending",
+			spec: &apis.ColumnarFileOutput{SortBy: []string{"-REPLICAS"}},
+			want: []string{
+				`CLUSTER,NAME,IMAGE,REPLICAS`,
+				`prod-a,web,web:v1,10`,
+				`prod-a,api,api:v1,3`,
+				`dev-a,api,api:v2,2`,
+				`dev-a,db,db:v1,1`,
+				`dev-a,web,web:v2,1`,
+			},
+		},
+		{
+			name: "group-aggregate",
+			spec: &apis.ColumnarFileOutput{
+				GroupBy: []string{"CLUSTER"},
+				SortBy:  []string{"CLUSTER"},
+				Columns: []*apis.ColumnOutput{
+					{Name: "CLUSTER", Text: ptr(types.ClusterPlaceholder)},
+					{Name: "NAME", Field: ptr(".name"), Aggregate: ptr("count")},
+					{Name: "IMAGE", Field: ptr(".image")},
+					{Name: "REPLICAS", Field: ptr(".replicas"), Aggregate: ptr("sum")},
+					{Name: "MAX", Expr: ptr("it.replicas"), Aggregate: ptr("max")},
+				},
+			},
+			want: []string{
+				`CLUSTER,NAME,IMAGE,REPLICAS,MAX`,
+				`dev-a,3,"api:v2,db:v1,web:v2",4,2`,
+				`prod-a,2,"api:v1,web:v1",13,10`,
+			},
+		},
+		{
+			name: "group-quantities",
+			spec: &apis.ColumnarFileOutput{
+				GroupBy: []string{"CLUSTER"},
+				SortBy:  []string{"CLUSTER"},
+				Columns: []*apis.ColumnOutput{
+					{Name: "CLUSTER", Text: ptr(types.ClusterPlaceholder)},
+					{Name: "MEMORY", Field: ptr(".memory"), Aggregate: ptr("sum")},
+					{Name: "MIN", Expr: ptr("it.memory"), Aggregate: ptr("min")},
+					{Name: "MAX", Expr: ptr("it.memory"), Aggregate: ptr("max")},
+				},
+			},
+			want: []string{
+				`CLUSTER,MEMORY,MIN,MAX`,
+				`dev-a,1792Mi,256Mi,1Gi`,
+				`prod-a,3584Mi,1536Mi,2Gi`,
+			},
+		},
+		{
+			name: "group-decimals",
+			spec: &apis.ColumnarFileOutput{
+				GroupBy: []string{"CLUSTER"},
+				SortBy:  []string{"CLUSTER"},
+				Columns: []*apis.ColumnOutput{
+					{Name: "CLUSTER", Text: ptr(types.ClusterPlaceholder)},
+					{Name: "CPU", Field: ptr(".cpu"), Aggregate: ptr("sum")},
+				},
+			},
+			want: []string{
+				`CLUSTER,CPU`,
+				`dev-a,0.6`,
+				`prod-a,1.5`,
+			},
+		},
+		{
+			name: "pivot",
+			spec: &apis.ColumnarFileOutput{Pivot: ptr("IMAGE")},
+			want: []string{
+				`NAME,REPLICAS,dev-a,prod-a`,
+				`api,2,api:v2,`,
+				`api,3,,api:v1`,
+				`db,1,db:v1,`,
+				`web,1,web:v2,`,
+				`web,10,,web:v1`,
+			},
+		},
+		{
+			name: "group-pivot",
+			spec: &apis.ColumnarFileOutput{GroupBy: []string{"NAME"}, Pivot: ptr("IMAGE")},
+			want: []string{
+				`NAME,dev-a,prod-a`,
+				`api,api:v2,api:v1`,
+				`db,db:v1,`,
+				`web,web:v2,web:v1`,
+			},
+		},
+		{
+			name: "sum-not-a-number",
+			spec: &apis.ColumnarFileOutput{
+				GroupBy: []string{"CLUSTER"},
+				Columns: []*apis.ColumnOutput{
+					{Name: "CLUSTER", Text: ptr(types.ClusterPlaceholder)},
+					{Name: "NAME", Field: ptr(".name"), Aggregate: ptr("sum")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown-aggregate",
+			spec: &apis.ColumnarFileOutput{
+				Columns: []*apis.ColumnOutput{
+					{Name: "NAME", Field: ptr(".name"), Aggregate: ptr("avg")},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown-column",
+			spec:    &apis.ColumnarFileOutput{SortBy: []string{"AGE"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := test.spec
+			spec.Path = ptr("-")
+
+			if len(spec.Columns) == 0 {
+				spec.Columns = columns
+			}
+
+			stdout := bytes.NewBuffer(nil)
+			env := &types.Env{
+				FileSys: fsutil.Stdio(filesys.MakeFsInMemory(), bytes.NewBuffer(nil), stdout),
+			}
+
+			err := func() error {
+				out, err := newCSVOutput(spec)
+				if err != nil {
+					return err
+				}
+
+				return out.Store(env, columnarTestResources())
+			}()
+
+			switch {
+			case err != nil && test.wantErr:
+				t.Logf("got expected error: %v", err)
+				return
+			case err != nil:
+				t.Fatalf("want no error, got: %v", err)
+			case test.wantErr:
+				t.Fatalf("want error, got none")
+			}
+
+			want := strings.Join(append(test.want, ""), "\n")
+			if diff := cmp.Diff(want, stdout.String()); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
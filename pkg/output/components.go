@@ -19,11 +19,13 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
-func newComponentsOutput(_ *apis.KustomizeComponentsOutput) (*ComponentsOutput, error) {
-	return &ComponentsOutput{}, nil
+func newComponentsOutput(spec *apis.KustomizeComponentsOutput) (*ComponentsOutput, error) {
+	return &ComponentsOutput{Verify: spec.GetVerify()}, nil
 }
 
-type ComponentsOutput struct{}
+type ComponentsOutput struct {
+	Verify bool `yaml:"verify"`
+}
 
 //nolint:lll
 func (out *ComponentsOutput) storeComponentsOverlays(env *types.Env, resources *types.ClusterResources, comps *Components, compsDir string) error {
@@ -72,7 +74,17 @@ func (out *ComponentsOutput) Store(env *types.Env, resources *types.ClusterResou
 		return fmt.Errorf("unable to store components: %w", err)
 	}
 
-	return out.storeComponentsOverlays(env, resources, comps, compsDir)
+	if err := out.storeComponentsOverlays(env, resources, comps, compsDir); err != nil {
+		return err
+	}
+
+	// the versions of the CRDs are merged by design, so the overlays are
+	// verified against the merged ones
+	if out.Verify {
+		return verifyOverlays(env.FileSys, comps.unifiedResources(resources))
+	}
+
+	return nil
 }
 
 type component struct {
@@ -132,18 +144,36 @@ type Components struct {
 	clusters  *types.ClusterIndex
 	byName    map[string]*component
 	byCluster map[types.ClusterID][]*component
+
+	// unifiedCRDs are the CRDs of the clusters with the unified versions
+	unifiedCRDs map[resid.ResId]map[types.ClusterID]*yaml.RNode
 }
 
 func NewComponents(clusters *types.ClusterIndex) *Components {
 	comps := &Components{
-		clusters:  clusters,
-		byName:    map[string]*component{},
-		byCluster: map[types.ClusterID][]*component{},
+		clusters:    clusters,
+		byName:      map[string]*component{},
+		byCluster:   map[types.ClusterID][]*component{},
+		unifiedCRDs: map[resid.ResId]map[types.ClusterID]*yaml.RNode{},
 	}
 
 	return comps
 }
 
+// unifiedResources returns the resources as the components store them, with
+// the versions of the CRDs unified.
+func (comps *Components) unifiedResources(resources *types.ClusterResources) *types.ClusterResources {
+	result := &types.ClusterResources{
+		Clusters:  resources.Clusters,
+		Resources: maps.Clone(resources.Resources),
+		Records:   resources.Records,
+	}
+
+	maps.Copy(result.Resources, comps.unifiedCRDs)
+
+	return result
+}
+
 var errClusterNotFound = errors.New("cluster not found")
 
 func (comps *Components) Cluster(cluster types.ClusterID) ([]string, error) {
@@ -185,6 +215,18 @@ func (comps *Components) component(ids ...types.ClusterID) *component {
 }
 
 func (comps *Components) Add(resID resid.ResId, resources map[types.ClusterID]*yaml.RNode) error {
+	if isCRD(resID) {
+		// the rest of the CRDs differing between the clusters, e.g. the
+		// annotations, are kept as the patches
+		unified, err := unifyCRDVersions(resID, resources)
+		if err != nil {
+			return err
+		}
+
+		comps.unifiedCRDs[resID] = unified
+		resources = unified
+	}
+
 	mainBuilder := resource.NewBuilder(resID)
 	mainClusterIDs := slices.Collect(maps.Keys(resources))
 	mainComp := comps.component(mainClusterIDs...)
@@ -1,13 +1,18 @@
 package output_test
 
 import (
+	"bytes"
 	"embed"
+	"log/slog"
+	"strings"
 	"testing"
 
+	"github.com/Mirantis/ktl/pkg/apis"
 	"github.com/Mirantis/ktl/pkg/e2e"
 	"github.com/Mirantis/ktl/pkg/output"
 	"github.com/Mirantis/ktl/pkg/types"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	"sigs.k8s.io/kustomize/kyaml/resid"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -49,3 +54,69 @@ func TestComponents(t *testing.T) {
 		t.Errorf("components mismatch, +got -want:\n%s", diff)
 	}
 }
+
+func TestComponentsCRDs(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a"})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a"})
+
+	crd := func(caFrom, versions string) *yaml.RNode {
+		return yaml.MustParse(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+  annotations:
+    cert-manager.io/inject-ca-from: ` + caFrom + `
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+` + versions)
+	}
+
+	v1alpha1 := "  - name: v1alpha1\n    served: true\n    storage: true\n"
+	v1 := "  - name: v1\n    served: true\n    storage: true\n"
+	byCluster := map[types.ClusterID]*yaml.RNode{
+		devA:  crd("ns-a/cert", v1alpha1),
+		prodA: crd("ns-b/cert", strings.Replace(v1alpha1, "storage: true", "storage: false", 1)+v1),
+	}
+
+	resources := &types.ClusterResources{
+		Clusters:  clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{resid.FromRNode(byCluster[devA]): byCluster},
+	}
+
+	out, err := output.New(&apis.Output{KustomizeComponents: &apis.KustomizeComponentsOutput{Verify: proto.Bool(true)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs := bytes.NewBuffer(nil)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+
+	// the versions are merged, the annotations are kept per cluster
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, resources); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Count(logs.String(), "CRD storage version differs"); got != 1 {
+		t.Errorf("want the storage version warning once, got %d:\n%s", got, logs)
+	}
+
+	got := e2e.ReadFiles(t, gotFs, ".")
+	for comp, want := range map[string]string{
+		"all-clusters": "name: v1alpha1",
+		"dev-a":        "cert-manager.io/inject-ca-from: ns-a/cert",
+		"prod-a":       "cert-manager.io/inject-ca-from: ns-b/cert",
+	} {
+		path := "components/" + comp + "/widgets.example.com-customresourcedefinition.yaml"
+		if !strings.Contains(got[path], want) {
+			t.Errorf("want %q in %s, got:\n%s", want, path, got[path])
+		}
+	}
+}
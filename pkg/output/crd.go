@@ -28,37 +28,37 @@ type CRDDescriptionsOutput struct {
 func (out *CRDDescriptionsOutput) Store(env *types.Env, resources *types.ClusterResources) error {
 	result := map[string]string{}
 
-	for resId, byCluster := range resources.Resources {
-		for _, rnode := range byCluster {
-			var crd apiextensionsv1.CustomResourceDefinition
-
-			ystr, err := rnode.String()
-			if err != nil {
-				panic(err)
-			}
+	for resID, byCluster := range resources.Resources {
+		rnode, err := mergeCRDs(resID, byCluster)
+		if err != nil {
+			return err
+		}
 
-			if err := yaml.Unmarshal([]byte(ystr), &crd); err != nil {
-				return fmt.Errorf("unable to process %s: %w", resId, err)
-			}
+		var crd apiextensionsv1.CustomResourceDefinition
 
-			//TODO: parameterize selection
-			slices.SortFunc(
-				crd.Spec.Versions,
-				func(a, b apiextensionsv1.CustomResourceDefinitionVersion) int {
-					return -strings.Compare(a.Name, b.Name)
-				},
-			)
+		ystr, err := rnode.String()
+		if err != nil {
+			panic(err)
+		}
 
-			if len(crd.Spec.Versions) < 1 {
-				continue
-			}
+		if err := yaml.Unmarshal([]byte(ystr), &crd); err != nil {
+			return fmt.Errorf("unable to process %s: %w", resID, err)
+		}
 
-			schema := crd.Spec.Versions[0].Schema.OpenAPIV3Schema
-			updateSchemaAttrs(result, crd.Spec.Names.Kind+"[]", schema, "")
+		//TODO: parameterize selection
+		slices.SortFunc(
+			crd.Spec.Versions,
+			func(a, b apiextensionsv1.CustomResourceDefinitionVersion) int {
+				return -strings.Compare(a.Name, b.Name)
+			},
+		)
 
-			//TODO: handle multi-cluster (error or cluster-specific)
-			break
+		if len(crd.Spec.Versions) < 1 {
+			continue
 		}
+
+		schema := crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+		updateSchemaAttrs(result, crd.Spec.Names.Kind+"[]", schema, "")
 	}
 
 	body, err := json.MarshalIndent(result, "", "  ")
@@ -0,0 +1,252 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var errCRDConflict = errors.New("incompatible CRDs")
+
+func isCRD(id resid.ResId) bool {
+	return id.Group == "apiextensions.k8s.io" && id.Version == "v1" && id.Kind == "CustomResourceDefinition"
+}
+
+// mergeCRDs returns the single CRD for the clusters: the versions are merged
+// as unifyCRDVersions does, and the rest of the CRD is taken from the first
+// cluster, warning about the differences dropped, e.g. the annotations.
+func mergeCRDs(resID resid.ResId, crds map[types.ClusterID]*yaml.RNode) (*yaml.RNode, error) {
+	unified, err := unifyCRDVersions(resID, crds)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := diffFields(resID, unified)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diffs) > 0 {
+		paths := []string{}
+		for _, diff := range diffs {
+			paths = append(paths, diff.path.String())
+		}
+
+		slog.Warn("CRD differs between clusters, keeping the first one", "crd", resID.Name, "fields", paths)
+	}
+
+	return unified[slices.Min(slices.Collect(maps.Keys(unified)))], nil
+}
+
+// unifyCRDVersions returns the CRDs of the clusters with the same versions:
+// the versions are united, a single storage version is kept, and the schemas
+// of the versions are merged unless the types of their fields differ. The
+// rest of the CRDs is kept as is.
+//
+//nolint:lll
+func unifyCRDVersions(resID resid.ResId, crds map[types.ClusterID]*yaml.RNode) (map[types.ClusterID]*yaml.RNode, error) {
+	ids := slices.Sorted(maps.Keys(crds))
+	specs := []*apiextensionsv1.CustomResourceDefinitionSpec{}
+	result := map[types.ClusterID]*yaml.RNode{}
+
+	for _, id := range ids {
+		body, err := crds[id].String()
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize %s: %w", resID, err)
+		}
+
+		var crd apiextensionsv1.CustomResourceDefinition
+		if err := k8syaml.Unmarshal([]byte(body), &crd); err != nil {
+			return nil, fmt.Errorf("unable to process %s: %w", resID, err)
+		}
+
+		specs = append(specs, &crd.Spec)
+		result[id] = crds[id].Copy()
+	}
+
+	first := specs[0]
+	for _, spec := range specs[1:] {
+		if spec.Group != first.Group || spec.Scope != first.Scope ||
+			spec.Names.Kind != first.Names.Kind || spec.Names.Plural != first.Names.Plural {
+			return nil, fmt.Errorf("%w: %s: group, scope or names differ", errCRDConflict, resID.Name)
+		}
+	}
+
+	if !slices.ContainsFunc(specs[1:], func(spec *apiextensionsv1.CustomResourceDefinitionSpec) bool {
+		return !reflect.DeepEqual(spec.Versions, first.Versions)
+	}) {
+		return result, nil
+	}
+
+	versions, err := mergeCRDVersions(resID, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]any{"versions": versions})
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize %s versions: %w", resID, err)
+	}
+
+	specNode, err := yaml.ConvertJSONToYamlNode(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize %s versions: %w", resID, err)
+	}
+
+	for _, crd := range result {
+		versionsNode := specNode.Field("versions").Value.Copy()
+		if err := crd.PipeE(yaml.Lookup("spec"), yaml.SetField("versions", versionsNode)); err != nil {
+			return nil, fmt.Errorf("unable to set %s versions: %w", resID, err)
+		}
+	}
+
+	return result, nil
+}
+
+//nolint:lll
+func mergeCRDVersions(resID resid.ResId, specs []*apiextensionsv1.CustomResourceDefinitionSpec) ([]apiextensionsv1.CustomResourceDefinitionVersion, error) {
+	errs := []error{}
+	versions := []apiextensionsv1.CustomResourceDefinitionVersion{}
+	byName := map[string]int{}
+	storage := []string{}
+
+	for _, spec := range specs {
+		for _, crdVersion := range spec.Versions {
+			if crdVersion.Storage && !slices.Contains(storage, crdVersion.Name) {
+				storage = append(storage, crdVersion.Name)
+			}
+
+			idx, found := byName[crdVersion.Name]
+			if !found {
+				byName[crdVersion.Name] = len(versions)
+				versions = append(versions, *crdVersion.DeepCopy())
+
+				continue
+			}
+
+			merged := &versions[idx]
+			merged.Served = merged.Served || crdVersion.Served
+
+			schema, err := mergeCRDSchemas(resID.Name+"/"+crdVersion.Name, merged.Schema, crdVersion.Schema)
+			if err != nil {
+				errs = append(errs, err)
+			}
+
+			merged.Schema = schema
+
+			other := crdVersion.DeepCopy()
+			other.Served, other.Storage, other.Schema = merged.Served, merged.Storage, merged.Schema
+
+			if !reflect.DeepEqual(merged, other) {
+				slog.Warn("CRD version differs between clusters, keeping the first one",
+					"crd", resID.Name, "version", crdVersion.Name)
+			}
+		}
+	}
+
+	if len(storage) > 1 {
+		slices.SortFunc(storage, func(a, b string) int {
+			return version.CompareKubeAwareVersionStrings(b, a)
+		})
+		slog.Warn("CRD storage version differs between clusters",
+			"crd", resID.Name, "versions", storage, "storage", storage[0])
+	}
+
+	for idx := range versions {
+		versions[idx].Storage = len(storage) > 0 && versions[idx].Name == storage[0]
+	}
+
+	return versions, errors.Join(errs...)
+}
+
+// mergeCRDSchemas returns the schema accepting the objects valid by either
+// of the schemas: the properties are united and only the properties required
+// by both schemas are required. The fields typed differently are conflicts.
+//
+//nolint:lll
+func mergeCRDSchemas(path string, left, right *apiextensionsv1.CustomResourceValidation) (*apiextensionsv1.CustomResourceValidation, error) {
+	if left == nil || left.OpenAPIV3Schema == nil {
+		return right, nil
+	}
+
+	if right == nil || right.OpenAPIV3Schema == nil {
+		return left, nil
+	}
+
+	schema, err := mergeSchemaProps(path, left.OpenAPIV3Schema, right.OpenAPIV3Schema)
+
+	return &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: schema}, err
+}
+
+//nolint:lll
+func mergeSchemaProps(path string, left, right *apiextensionsv1.JSONSchemaProps) (*apiextensionsv1.JSONSchemaProps, error) {
+	if left.Type != right.Type {
+		return left, fmt.Errorf("%w: %s is %s and %s", errCRDConflict, path, left.Type, right.Type)
+	}
+
+	result := left.DeepCopy()
+	errs := []error{}
+
+	for _, name := range slices.Sorted(maps.Keys(right.Properties)) {
+		prop := right.Properties[name]
+
+		existing, found := result.Properties[name]
+		if !found {
+			if result.Properties == nil {
+				result.Properties = map[string]apiextensionsv1.JSONSchemaProps{}
+			}
+
+			result.Properties[name] = *prop.DeepCopy()
+
+			continue
+		}
+
+		merged, err := mergeSchemaProps(path+"."+name, &existing, &prop)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		result.Properties[name] = *merged
+	}
+
+	result.Required = slices.DeleteFunc(result.Required, func(name string) bool {
+		return !slices.Contains(right.Required, name)
+	})
+
+	if result.Items != nil && result.Items.Schema != nil && right.Items != nil && right.Items.Schema != nil {
+		merged, err := mergeSchemaProps(path+"[]", result.Items.Schema, right.Items.Schema)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		result.Items.Schema = merged
+	}
+
+	if len(result.Enum) > 0 {
+		for _, value := range right.Enum {
+			if !slices.ContainsFunc(result.Enum, func(existing apiextensionsv1.JSON) bool {
+				return bytes.Equal(existing.Raw, value.Raw)
+			}) {
+				result.Enum = append(result.Enum, value)
+			}
+		}
+
+		if len(right.Enum) == 0 {
+			result.Enum = nil
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
@@ -3,6 +3,7 @@ package output
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"io"
 	"testing"
 
@@ -69,3 +70,119 @@ func TestCrdSummaryOutput(t *testing.T) {
 		t.Errorf("-want +got:\n%s", diff)
 	}
 }
+
+func testCRD(versions string) *yaml.RNode {
+	return yaml.MustParse(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+` + versions)
+}
+
+func TestMergeCRDs(t *testing.T) {
+	crds := map[types.ClusterID]*yaml.RNode{
+		0: testCRD(`  - name: v1alpha1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: [size]
+            properties:
+              size:
+                type: integer
+`),
+		1: testCRD(`  - name: v1alpha1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+              color:
+                type: string
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`),
+	}
+
+	got, err := mergeCRDs(resid.FromRNode(crds[0]), crds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := testCRD(`  - name: v1alpha1
+    schema:
+      openAPIV3Schema:
+        properties:
+          spec:
+            properties:
+              color:
+                type: string
+              size:
+                type: integer
+            type: object
+        type: object
+    served: true
+    storage: false
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+    served: true
+    storage: true
+`)
+
+	if diff := cmp.Diff(want.MustString(), got.MustString()); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestMergeCRDsConflict(t *testing.T) {
+	crds := map[types.ClusterID]*yaml.RNode{
+		0: testCRD(`  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          size:
+            type: integer
+`),
+		1: testCRD(`  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          size:
+            type: string
+`),
+	}
+
+	_, err := mergeCRDs(resid.FromRNode(crds[0]), crds)
+	if !errors.Is(err, errCRDConflict) {
+		t.Errorf("want %v, got %v", errCRDConflict, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/filters"
 	"github.com/Mirantis/ktl/pkg/resource"
 	"github.com/Mirantis/ktl/pkg/types"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -24,12 +25,24 @@ func newValueRef(spec *apis.ColumnOutput) (ValueRef, error) {
 		}
 	}
 
-	return ValueRef{
+	ref := ValueRef{
 		Name:        spec.GetName(),
 		Description: spec.GetDescription(),
 		Field:       q,
 		Text:        spec.GetText(),
-	}, nil
+		Aggregate:   spec.GetAggregate(),
+	}
+
+	if e := spec.GetExpr(); len(e) > 0 {
+		expr, err := filters.NewExpr(e)
+		if err != nil {
+			return ValueRef{}, err
+		}
+
+		ref.Expr = expr
+	}
+
+	return ref, ref.validate()
 }
 
 type ValueRef struct {
@@ -37,6 +50,8 @@ type ValueRef struct {
 	Description string         `yaml:"description"`
 	Field       resource.Query `yaml:"field"`
 	Text        string         `yaml:"text"`
+	Expr        *filters.Expr  `yaml:"expr"`
+	Aggregate   string         `yaml:"aggregate"`
 }
 
 func (ref *ValueRef) UnmarshalYAML(node *yaml.Node) error {
@@ -48,26 +63,70 @@ func (ref *ValueRef) UnmarshalYAML(node *yaml.Node) error {
 		return err //nolint:wrapcheck
 	}
 
-	if len(raw.Field) > 0 && len(raw.Text) > 0 {
-		return fmt.Errorf("%w: field,text", errMutuallyExclusive)
-	}
-
 	*ref = ValueRef(*raw)
 
-	return nil
+	return ref.validate()
 }
 
-func (ref *ValueRef) text(cluster *types.Cluster) string {
+func (ref *ValueRef) validate() error {
+	set := []string{}
+
+	if len(ref.Field) > 0 {
+		set = append(set, "field")
+	}
+
 	if len(ref.Text) > 0 {
-		return strings.ReplaceAll(ref.Text, types.ClusterPlaceholder, cluster.Name)
+		set = append(set, "text")
+	}
+
+	if ref.Expr != nil {
+		set = append(set, "expr")
+	}
+
+	if len(set) > 1 {
+		return fmt.Errorf("%w: %s", errMutuallyExclusive, strings.Join(set, ","))
+	}
+
+	return ValidateAggregate(ref.Aggregate)
+}
+
+// value returns the text or the expression value of the column.
+func (ref *ValueRef) value(cluster *types.Cluster, node *yaml.RNode) (string, error) {
+	switch {
+	case len(ref.Text) > 0:
+		return strings.ReplaceAll(ref.Text, types.ClusterPlaceholder, cluster.Name), nil
+	case ref.Expr != nil:
+		ynode, err := ref.Expr.Eval(node, cluster)
+		if err != nil {
+			return "", fmt.Errorf("column %s: %w", ref.Name, err)
+		}
+
+		return nodeText(ynode), nil
+	default:
+		return "", nil
+	}
+}
+
+// nodeText returns the scalars as is and the other values in the flow style.
+func nodeText(ynode *yaml.Node) string {
+	if ynode == nil || ynode.ShortTag() == yaml.NodeTagNull {
+		return ""
 	}
 
-	return ""
+	value, _ := yaml.String(ynode, yaml.Trim, yaml.Flow)
+	if strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = strings.Trim(value, `"`)
+	}
+
+	return value
 }
 
 func newCSVOutput(spec *apis.ColumnarFileOutput) (*CSVOutput, error) {
 	impl := &CSVOutput{
-		Path: spec.GetPath(),
+		Path:    spec.GetPath(),
+		SortBy:  spec.GetSortBy(),
+		GroupBy: spec.GetGroupBy(),
+		Pivot:   spec.GetPivot(),
 	}
 
 	for _, colSpec := range spec.GetColumns() {
@@ -84,15 +143,23 @@ func newCSVOutput(spec *apis.ColumnarFileOutput) (*CSVOutput, error) {
 type CSVOutput struct {
 	Columns []ValueRef `yaml:"columns"`
 	Path    string     `yaml:"path"`
+	SortBy  []string   `yaml:"sortBy"`
+	GroupBy []string   `yaml:"groupBy"`
+	Pivot   string     `yaml:"pivot"`
 }
 
-func (out *CSVOutput) initRow(offset int, cluster *types.Cluster) ([]string, *resource.Queries[int], []int) {
-	row := make([]string, len(out.Columns))
-	offsets := make([]int, len(out.Columns))
+func initRow(columns []ValueRef, offset int, cluster *types.Cluster, node *yaml.RNode) ([]string, *resource.Queries[int], []int, error) {
+	row := make([]string, len(columns))
+	offsets := make([]int, len(columns))
 	queries := &resource.Queries[int]{}
 
-	for colIdx, col := range out.Columns {
-		row[colIdx] = col.text(cluster)
+	for colIdx, col := range columns {
+		value, err := col.value(cluster, node)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		row[colIdx] = value
 		offsets[colIdx] = offset
 
 		if len(col.Field) == 0 {
@@ -102,55 +169,145 @@ func (out *CSVOutput) initRow(offset int, cluster *types.Cluster) ([]string, *re
 		queries.Add(col.Field, colIdx)
 	}
 
-	return row, queries, offsets
+	return row, queries, offsets, nil
 }
 
-func (out *CSVOutput) rows(resources *types.ClusterResources) [][]string {
-	rows := [][]string{}
+// appendRows appends the rows of the node, one per the values matched by the
+// wildcard queries.
+func appendRows(rows [][]string, columns []ValueRef, cluster *types.Cluster, node *yaml.RNode) ([][]string, error) {
+	row, queries, offsets, err := initRow(columns, len(rows)-1, cluster, node)
+	if err != nil {
+		return nil, err
+	}
+
+	for colIdx, valueNode := range queries.Scan(node) {
+		value := nodeText(valueNode.YNode())
 
-	header := []string{}
-	for _, ref := range out.Columns {
-		header = append(header, ref.Name)
+		if offsets[colIdx] > len(rows)-1 {
+			rows = append(rows, slices.Clone(row))
+			for oIdx := range offsets {
+				offsets[oIdx] = len(rows) - 1
+			}
+		}
+
+		row[colIdx] = value
+		offsets[colIdx]++
 	}
 
-	rows = append(rows, header)
+	for colIdx := range offsets {
+		if offsets[colIdx] > len(rows)-1 {
+			rows = append(rows, slices.Clone(row))
+			break
+		}
+	}
 
-	for _, byCluster := range resources.Resources {
-		for clusterID, node := range byCluster {
-			cluster := resources.Clusters.Cluster(clusterID)
-			row, queries, offsets := out.initRow(len(rows)-1, &cluster)
+	return rows, nil
+}
 
-			for colIdx, valueNode := range queries.Scan(node) {
-				value, _ := yaml.String(valueNode.YNode(), yaml.Trim, yaml.Flow)
-				if strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
-					value = strings.Trim(value, `"`)
-				}
+// recordColumns returns the cluster and the record fields in the order of
+// appearance.
+func recordColumns(records []types.Record) []ValueRef {
+	columns := []ValueRef{}
+	seen := map[string]bool{}
 
-				if offsets[colIdx] > len(rows)-1 {
-					rows = append(rows, slices.Clone(row))
-					for oIdx := range offsets {
-						offsets[oIdx] = len(rows) - 1
-					}
-				}
+	if slices.ContainsFunc(records, func(record types.Record) bool { return record.Cluster != nil }) {
+		columns = append(columns, ValueRef{Name: "CLUSTER", Text: types.ClusterPlaceholder})
+	}
+
+	for _, record := range records {
+		content := record.Fields.YNode().Content
 
-				row[colIdx] = value
-				offsets[colIdx]++
+		for idx := range len(content) / 2 {
+			key := content[idx*2].Value
+			if seen[key] {
+				continue
 			}
 
-			for colIdx := range offsets {
-				if offsets[colIdx] > len(rows)-1 {
-					rows = append(rows, slices.Clone(row))
-					break
+			seen[key] = true
+			columns = append(columns, ValueRef{Name: key, Field: resource.Query{key}})
+		}
+	}
+
+	return columns
+}
+
+// rows returns the header and the rows of the resources and the records,
+// without the columns only the records are listed, with the columns derived
+// from the record fields. The rows are grouped, pivoted and sorted as
+// configured.
+func (out *CSVOutput) rows(resources *types.ClusterResources) ([][]string, error) {
+	columns := out.Columns
+	recordsOnly := len(columns) == 0 && len(resources.Records) > 0
+
+	if recordsOnly {
+		columns = recordColumns(resources.Records)
+	}
+
+	table := &tableRows{columns: columns}
+	rows := [][]string{nil} // the header is added after the arrangement
+
+	appendClusterRows := func(cluster types.Cluster, node *yaml.RNode) error {
+		var err error
+		if rows, err = appendRows(rows, columns, &cluster, node); err != nil {
+			return err
+		}
+
+		for len(table.clusters) < len(rows)-1 {
+			table.clusters = append(table.clusters, cluster.Name)
+		}
+
+		return nil
+	}
+
+	if !recordsOnly {
+		for _, byCluster := range resources.Resources {
+			for clusterID, node := range byCluster {
+				if err := appendClusterRows(resources.Clusters.Cluster(clusterID), node); err != nil {
+					return nil, err
 				}
 			}
 		}
 	}
 
-	slices.SortFunc(rows[1:], func(rowa, rowb []string) int {
-		return slices.CompareFunc(rowa, rowb, strings.Compare)
-	})
+	for _, record := range resources.Records {
+		cluster := types.Cluster{}
+		if record.Cluster != nil {
+			cluster = resources.Clusters.Cluster(*record.Cluster)
+		}
 
-	return rows
+		if err := appendClusterRows(cluster, record.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	table.rows = rows[1:]
+
+	if err := out.arrange(table, slices.Collect(resources.Clusters.Names(resources.Clusters.IDs()...))); err != nil {
+		return nil, err
+	}
+
+	header := []string{}
+	for _, ref := range table.columns {
+		header = append(header, ref.Name)
+	}
+
+	return append([][]string{header}, table.rows...), nil
+}
+
+func (out *CSVOutput) arrange(table *tableRows, clusters []string) error {
+	if len(out.GroupBy) > 0 {
+		if err := table.groupBy(out.GroupBy, len(out.Pivot) > 0); err != nil {
+			return err
+		}
+	}
+
+	if len(out.Pivot) > 0 {
+		if err := table.pivot(out.Pivot, out.GroupBy, clusters); err != nil {
+			return err
+		}
+	}
+
+	return table.sortBy(out.SortBy)
 }
 
 var errAbsPath = errors.New("absolute path not supported")
@@ -161,13 +318,18 @@ func (out *CSVOutput) Store(env *types.Env, resources *types.ClusterResources) e
 		return fmt.Errorf("invalid csv output path: %w", errAbsPath)
 	}
 
+	rows, err := out.rows(resources)
+	if err != nil {
+		return err
+	}
+
 	buffer := bytes.NewBuffer(nil)
 
-	err := func() error {
+	err = func() error {
 		csvWriter := csv.NewWriter(buffer)
 		defer csvWriter.Flush()
 
-		for _, row := range out.rows(resources) {
+		for _, row := range rows {
 			if err := csvWriter.Write(row); err != nil {
 				return err //nolint:wrapcheck
 			}
@@ -0,0 +1,280 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/resource"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func recordsTestResources() *types.ClusterResources {
+	clusters := types.NewClusterIndex()
+	devID := clusters.Add(types.Cluster{Name: "dev-a"})
+	prodID := clusters.Add(types.Cluster{Name: "prod-a"})
+	deployment := yaml.MustParse("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")
+
+	return &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(deployment): {devID: deployment},
+		},
+		Records: []types.Record{
+			{Cluster: &devID, Fields: yaml.MustParse("name: web\nreplicas: 1\n")},
+			{Cluster: &prodID, Fields: yaml.MustParse("name: web\nreplicas: 3\nready: 2\n")},
+			{Cluster: &devID, Fields: yaml.MustParse("name: web\nreplicas: 1\n")},
+		},
+	}
+}
+
+func TestCSVRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []ValueRef
+		records []types.Record // replaces the test records
+		want    string
+	}{
+		{
+			name: "derived-columns",
+			want: strings.Join([]string{
+				`CLUSTER,name,replicas,ready`,
+				`dev-a,web,1,`,
+				`dev-a,web,1,`,
+				`prod-a,web,3,2`,
+				``,
+			}, "\n"),
+		},
+		{
+			name: "columns",
+			columns: []ValueRef{
+				{Name: "CLUSTER", Text: types.ClusterPlaceholder},
+				{Name: "KIND", Field: resource.Query{"kind"}},
+				{Name: "NAME", Field: resource.Query{"metadata", "name"}},
+				{Name: "REPLICAS", Field: resource.Query{"replicas"}},
+			},
+			want: strings.Join([]string{
+				`CLUSTER,KIND,NAME,REPLICAS`,
+				`dev-a,,,1`,
+				`dev-a,,,1`,
+				`dev-a,Deployment,web,`,
+				`prod-a,,,3`,
+				``,
+			}, "\n"),
+		},
+		{
+			name: "fleet-records",
+			records: []types.Record{
+				{Fields: yaml.MustParse("clusters: 2\ntotal: 4\n")},
+			},
+			want: strings.Join([]string{
+				`clusters,total`,
+				`2,4`,
+				``,
+			}, "\n"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resources := recordsTestResources()
+			if test.records != nil {
+				resources.Records = test.records
+			}
+
+			stdout := bytes.NewBuffer(nil)
+			env := &types.Env{
+				FileSys: fsutil.Stdio(filesys.MakeFsInMemory(), bytes.NewBuffer(nil), stdout),
+			}
+			out := &CSVOutput{Columns: test.columns, Path: "-"}
+
+			if err := out.Store(env, resources); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.want, stdout.String()); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONRecords(t *testing.T) {
+	resources := recordsTestResources()
+	resources.Resources = nil
+
+	stdout := bytes.NewBuffer(nil)
+	env := &types.Env{
+		FileSys: fsutil.Stdio(filesys.MakeFsInMemory(), bytes.NewBuffer(nil), stdout),
+	}
+	out, _ := newJSONOutput(nil)
+
+	if err := out.Store(env, resources); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		`{`,
+		`  "records": [`,
+		`    {`,
+		`      "cluster": "dev-a",`,
+		`      "fields": {`,
+		`        "name": "web",`,
+		`        "replicas": 1`,
+		`      }`,
+		`    },`,
+		`    {`,
+		`      "cluster": "prod-a",`,
+		`      "fields": {`,
+		`        "name": "web",`,
+		`        "ready": 2,`,
+		`        "replicas": 3`,
+		`      }`,
+		`    },`,
+		`    {`,
+		`      "cluster": "dev-a",`,
+		`      "fields": {`,
+		`        "name": "web",`,
+		`        "replicas": 1`,
+		`      }`,
+		`    }`,
+		`  ]`,
+		`}`,
+	}, "\n")
+
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestCSVExprColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []*apis.ColumnOutput
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "expr",
+			columns: []*apis.ColumnOutput{
+				{Name: "NAME", Field: ptr(".metadata.name")},
+				{Name: "IMAGES", Expr: ptr(`",".join([c.image for c in it.spec.template.spec.containers])`)},
+				{Name: "TAGS", Expr: ptr(`cluster.tags`)},
+				{Name: "LABELS", Expr: ptr(`it.metadata.labels`)},
+			},
+			want: strings.Join([]string{
+				`NAME,IMAGES,TAGS,LABELS`,
+				`web,"app:v1,sidecar:v1",[dev],`,
+				``,
+			}, "\n"),
+		},
+		{
+			name: "expr-wildcard",
+			columns: []*apis.ColumnOutput{
+				{Name: "CONTAINER", Field: ptr(".spec.template.spec.containers.*.name")},
+				{Name: "COUNT", Expr: ptr(`it.spec.template.spec.containers.count()`)},
+			},
+			want: strings.Join([]string{
+				`CONTAINER,COUNT`,
+				`app,2`,
+				`sidecar,2`,
+				``,
+			}, "\n"),
+		},
+		{
+			name: "expr-error",
+			columns: []*apis.ColumnOutput{
+				{Name: "BROKEN", Expr: ptr(`it.spec.replicas + "x"`)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "expr-max-steps",
+			columns: []*apis.ColumnOutput{
+				{Name: "BROKEN", Expr: ptr(`len([x for x in range(10000000)])`)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "expr-syntax",
+			columns: []*apis.ColumnOutput{
+				{Name: "BROKEN", Expr: ptr(`it.spec.`)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "exclusive",
+			columns: []*apis.ColumnOutput{
+				{Name: "BROKEN", Field: ptr(".kind"), Expr: ptr(`it.kind`)},
+			},
+			wantErr: true,
+		},
+	}
+
+	deployment := yaml.MustParse(strings.Join([]string{
+		`apiVersion: apps/v1`,
+		`kind: Deployment`,
+		`metadata:`,
+		`  name: web`,
+		`spec:`,
+		`  replicas: 2`,
+		`  template:`,
+		`    spec:`,
+		`      containers:`,
+		`      - name: app`,
+		`        image: app:v1`,
+		`      - name: sidecar`,
+		`        image: sidecar:v1`,
+	}, "\n"))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clusters := types.NewClusterIndex()
+			devID := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+			resources := &types.ClusterResources{
+				Clusters: clusters,
+				Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+					resid.FromRNode(deployment): {devID: deployment},
+				},
+			}
+
+			stdout := bytes.NewBuffer(nil)
+			env := &types.Env{
+				FileSys: fsutil.Stdio(filesys.MakeFsInMemory(), bytes.NewBuffer(nil), stdout),
+			}
+
+			err := func() error {
+				out, err := newCSVOutput(&apis.ColumnarFileOutput{Path: ptr("-"), Columns: test.columns})
+				if err != nil {
+					return err
+				}
+
+				return out.Store(env, resources)
+			}()
+
+			switch {
+			case err != nil && test.wantErr:
+				t.Logf("got expected error: %v", err)
+				return
+			case err != nil:
+				t.Fatalf("want no error, got: %v", err)
+			case test.wantErr:
+				t.Fatalf("want error, got none")
+			}
+
+			if diff := cmp.Diff(test.want, stdout.String()); diff != "" {
+				t.Errorf("-want +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
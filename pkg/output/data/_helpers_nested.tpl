@@ -0,0 +1,7 @@
+{{- define "merge_presets" -}}
+{{- $preset_values := .Values.preset_values -}}
+{{- $global := .Values.global -}}
+{{- range $idx, $preset := .Values.presets -}}
+{{-   $_ := mergeOverwrite $global (deepCopy (index $preset_values $preset | default dict)) -}}
+{{- end -}}
+{{- end -}}
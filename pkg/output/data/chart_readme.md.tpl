@@ -0,0 +1,14 @@
+# {{.Name}}
+
+Version: {{.Version}}
+{{- if .Values}}
+
+| Value | Type | Description | Presets | Clusters |
+| ----- | ---- | ----------- | ------- | -------- |
+{{- range .Values}}
+| `{{.Name}}` | {{.Type}} | {{.Description}} | {{join .Presets ", "}} | {{join .Clusters ", "}} |
+{{- end}}
+{{- else}}
+
+No values.
+{{- end}}
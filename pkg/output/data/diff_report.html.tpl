@@ -0,0 +1,25 @@
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Cluster differences</title>
+</head>
+<body>
+<h1>Cluster differences</h1>
+{{- range .}}
+<h2>{{.Name}}</h2>
+<table>
+<thead><tr><th>Path</th><th>Group</th><th>Clusters</th><th>Value</th></tr></thead>
+<tbody>
+{{- range .Entries}}{{$path := .Path}}
+{{- range .Variants}}
+<tr><td>{{if $path}}<code>{{$path}}</code>{{else}}<em>resource</em>{{end}}</td><td>{{.Group}}</td><td>{{join .Clusters ", "}}</td><td><code>{{.Value}}</code></td></tr>
+{{- end}}
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>No differences.</p>
+{{- end}}
+</body>
+</html>
@@ -0,0 +1,16 @@
+# Cluster differences
+{{- range .}}
+
+## {{.Name}}
+
+| Path | Group | Clusters | Value |
+| ---- | ----- | -------- | ----- |
+{{- range .Entries}}{{$path := .Path}}
+{{- range .Variants}}
+| {{if $path}}`{{$path}}`{{else}}*resource*{{end}} | {{.Group}} | {{join .Clusters ", "}} | {{cell .Value}} |
+{{- end}}
+{{- end}}
+{{- else}}
+
+No differences.
+{{- end}}
@@ -0,0 +1,323 @@
+package output
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/resource"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	diffFormatMarkdown = "markdown"
+	diffFormatHTML     = "html"
+	diffFormatCSV      = "csv"
+
+	diffValuePresent = "<present>"
+	diffValueMissing = "<missing>"
+)
+
+var (
+	errUnsupportedFormat = errors.New("unsupported format")
+
+	//go:embed data/diff_report.md.tpl
+	diffReportMarkdownTpl string
+	//go:embed data/diff_report.html.tpl
+	diffReportHTMLTpl string
+)
+
+func newDiffReportOutput(spec *apis.DiffReportOutput) (*DiffReportOutput, error) {
+	format := spec.GetFormat()
+	if len(format) == 0 {
+		format = diffFormatMarkdown
+	}
+
+	switch format {
+	case diffFormatMarkdown, diffFormatHTML, diffFormatCSV:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedFormat, format)
+	}
+
+	return &DiffReportOutput{Path: spec.GetPath(), Format: format}, nil
+}
+
+// DiffReportOutput lists the fields of the resources differing between the
+// clusters, with the groups of the clusters sharing the values, and the
+// resources missing from some of the clusters.
+type DiffReportOutput struct {
+	Path   string `yaml:"path"`
+	Format string `yaml:"format"`
+}
+
+func (out *DiffReportOutput) Store(env *types.Env, resources *types.ClusterResources) error {
+	path := out.Path
+	if filepath.IsAbs(path) {
+		return fmt.Errorf("invalid diff report output path: %w", errAbsPath)
+	}
+
+	report := NewDiffReport(resources.Clusters)
+
+	for id, byCluster := range resources.Resources {
+		if err := report.Add(id, byCluster); err != nil {
+			return fmt.Errorf("unable to add resources to the diff report: %w", err)
+		}
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+
+	switch out.Format {
+	case diffFormatHTML:
+		body, err = report.HTML()
+	case diffFormatCSV:
+		body, err = report.CSV()
+	default:
+		body, err = report.Markdown()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return env.FileSys.WriteFile(path, body) //nolint:wrapcheck
+}
+
+// DiffVariant is the value shared by the group of the clusters.
+type DiffVariant struct {
+	Group    string
+	Clusters []string
+	Value    string
+}
+
+// DiffEntry is the field of the resource with the values differing between
+// the clusters, the empty path stands for the entire resource.
+type DiffEntry struct {
+	Path     string
+	Variants []DiffVariant
+}
+
+type diffResource struct {
+	ID      resid.ResId
+	Name    string
+	Entries []DiffEntry
+}
+
+type DiffReport struct {
+	clusters  *types.ClusterIndex
+	resources []*diffResource
+}
+
+func NewDiffReport(clusters *types.ClusterIndex) *DiffReport {
+	return &DiffReport{clusters: clusters}
+}
+
+func (report *DiffReport) variant(ids []types.ClusterID, value string) DiffVariant {
+	slices.Sort(ids)
+
+	return DiffVariant{
+		Group:    report.clusters.Group(ids...),
+		Clusters: slices.Collect(report.clusters.Names(ids...)),
+		Value:    value,
+	}
+}
+
+// Add compares the resource across the clusters.
+func (report *DiffReport) Add(resID resid.ResId, resources map[types.ClusterID]*yaml.RNode) error {
+	res := &diffResource{ID: resID, Name: diffResourceName(resID)}
+	presentIDs := slices.Sorted(maps.Keys(resources))
+
+	missingIDs := slices.DeleteFunc(report.clusters.IDs(), func(id types.ClusterID) bool {
+		_, found := resources[id]
+
+		return found
+	})
+	if len(missingIDs) > 0 {
+		res.Entries = append(res.Entries, DiffEntry{
+			Variants: []DiffVariant{
+				report.variant(presentIDs, diffValuePresent),
+				report.variant(missingIDs, diffValueMissing),
+			},
+		})
+	}
+
+	fields, err := diffFields(resID, resources)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		entry := DiffEntry{Path: field.path.String()}
+		for _, variant := range field.variants {
+			entry.Variants = append(entry.Variants, report.variant(slices.Clone(variant.Clusters), diffValue(variant.Value)))
+		}
+
+		if len(field.missing) > 0 {
+			entry.Variants = append(entry.Variants, report.variant(field.missing, diffValueMissing))
+		}
+
+		res.Entries = append(res.Entries, entry)
+	}
+
+	if len(res.Entries) > 0 {
+		report.resources = append(report.resources, res)
+	}
+
+	return nil
+}
+
+// fieldDiff is the path with the values differing between the clusters, with
+// the clusters missing the path but not its parent.
+type fieldDiff struct {
+	path     resource.Query
+	variants []*resource.ValueGroup
+	missing  []types.ClusterID
+}
+
+// diffFields returns the paths of the resource differing between the
+// clusters, the fields missing from some clusters are reported once, at the
+// topmost missing path.
+func diffFields(resID resid.ResId, resources map[types.ClusterID]*yaml.RNode) ([]fieldDiff, error) {
+	diffs := []fieldDiff{}
+	presentIDs := slices.Sorted(maps.Keys(resources))
+	reported := map[string][]types.ClusterID{} // path to missing clusters
+	schema := openapi.SchemaForResourceType(resID.AsTypeMeta())
+
+	resIter := resource.NewIterator(resources, schema)
+	for resIter.Next() {
+		path := resIter.Path()
+		variants := resource.GroupByValue(resIter.Values())
+		missing := slices.DeleteFunc(slices.Clone(presentIDs), func(id types.ClusterID) bool {
+			return slices.ContainsFunc(variants, func(variant *resource.ValueGroup) bool {
+				return slices.Contains(variant.Clusters, id)
+			})
+		})
+
+		reported[path.String()] = missing
+
+		for idx := range len(path) - 1 {
+			parentMissing := reported[path[:idx+1].String()]
+			missing = slices.DeleteFunc(missing, func(id types.ClusterID) bool {
+				return slices.Contains(parentMissing, id)
+			})
+		}
+
+		if len(variants) < 2 && len(missing) == 0 {
+			continue
+		}
+
+		diffs = append(diffs, fieldDiff{path: path, variants: variants, missing: missing})
+	}
+
+	if err := resIter.Error(); err != nil {
+		return nil, fmt.Errorf("error while iterating over %s: %w", resID, err)
+	}
+
+	return diffs, nil
+}
+
+func (report *DiffReport) sorted() []*diffResource {
+	return slices.SortedFunc(slices.Values(report.resources), func(a, b *diffResource) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+}
+
+// Markdown returns the report with a table per resource.
+func (report *DiffReport) Markdown() ([]byte, error) {
+	tpl := template.Must(template.New("diff_report").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"cell": markdownCell,
+	}).Parse(diffReportMarkdownTpl))
+
+	buffer := bytes.NewBuffer(nil)
+	if err := tpl.Execute(buffer, report.sorted()); err != nil {
+		return nil, fmt.Errorf("unable to render diff report: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// HTML returns the report as a standalone page.
+func (report *DiffReport) HTML() ([]byte, error) {
+	tpl := htmltemplate.Must(htmltemplate.New("diff_report").Funcs(htmltemplate.FuncMap{
+		"join": strings.Join,
+	}).Parse(diffReportHTMLTpl))
+
+	buffer := bytes.NewBuffer(nil)
+	if err := tpl.Execute(buffer, report.sorted()); err != nil {
+		return nil, fmt.Errorf("unable to render diff report: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// CSV returns a row per the value of the field.
+func (report *DiffReport) CSV() ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	csvWriter := csv.NewWriter(buffer)
+
+	if err := csvWriter.Write([]string{"RESOURCE", "PATH", "GROUP", "CLUSTERS", "VALUE"}); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	for _, res := range report.sorted() {
+		for _, entry := range res.Entries {
+			for _, variant := range entry.Variants {
+				row := []string{res.Name, entry.Path, variant.Group, strings.Join(variant.Clusters, ","), variant.Value}
+				if err := csvWriter.Write(row); err != nil {
+					return nil, err //nolint:wrapcheck
+				}
+			}
+		}
+	}
+
+	csvWriter.Flush()
+
+	return buffer.Bytes(), csvWriter.Error() //nolint:wrapcheck
+}
+
+func diffResourceName(id resid.ResId) string {
+	kind := id.Kind
+	if len(id.Group) > 0 {
+		kind += "." + id.Group
+	}
+
+	if len(id.Namespace) > 0 {
+		return kind + " " + id.Namespace + "/" + id.Name
+	}
+
+	return kind + " " + id.Name
+}
+
+// diffValue returns the scalars and the lists in the flow style, the maps
+// and the lists merged by keys are compared by their fields.
+func diffValue(node *yaml.Node) string {
+	if node.Kind != yaml.ScalarNode && len(node.Content) == 0 {
+		return diffValuePresent
+	}
+
+	return strings.ReplaceAll(nodeText(node), "\n", " ")
+}
+
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	if strings.Contains(value, "`") {
+		return "`` " + value + " ``"
+	}
+
+	return "`" + value + "`"
+}
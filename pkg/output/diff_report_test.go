@@ -0,0 +1,101 @@
+package output_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func diffReportTestReport(t *testing.T) *output.DiffReport {
+	t.Helper()
+
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+	prodB := clusters.Add(types.Cluster{Name: "prod-b", Tags: []string{"prod"}})
+	testA := clusters.Add(types.Cluster{Name: "test-a", Tags: []string{"test"}})
+	testB := clusters.Add(types.Cluster{Name: "test-b", Tags: []string{"test"}})
+	resources := map[types.ClusterID]*yaml.RNode{
+		devA:  yaml.MustParse(appDevA),
+		prodA: yaml.MustParse(appProdA),
+		prodB: yaml.MustParse(appProdB),
+		testA: yaml.MustParse(appTestA),
+		testB: yaml.MustParse(appTestB),
+	}
+	configMap := yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: myapp-env\n  namespace: myapp\n")
+
+	report := output.NewDiffReport(clusters)
+	if err := report.Add(resid.FromRNode(resources[devA]), resources); err != nil {
+		t.Fatal(err)
+	}
+
+	err := report.Add(resid.FromRNode(configMap), map[types.ClusterID]*yaml.RNode{
+		prodA: configMap,
+		prodB: configMap,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return report
+}
+
+func TestDiffReportCSV(t *testing.T) {
+	got, err := diffReportTestReport(t).CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		`RESOURCE,PATH,GROUP,CLUSTERS,VALUE`,
+		`ConfigMap myapp/myapp-env,,prod,"prod-a,prod-b",<present>`,
+		`ConfigMap myapp/myapp-env,,test_dev,"dev-a,test-a,test-b",<missing>`,
+		`Deployment.apps myapp/myapp,metadata.labels.env,prod,"prod-a,prod-b",prod`,
+		`Deployment.apps myapp/myapp,metadata.labels.env,test,"test-a,test-b",test`,
+		`Deployment.apps myapp/myapp,metadata.labels.env,dev,dev-a,dev`,
+		`Deployment.apps myapp/myapp,spec.replicas,prod-a,prod-a,3`,
+		`Deployment.apps myapp/myapp,spec.replicas,prod-b,prod-b,5`,
+		`Deployment.apps myapp/myapp,spec.replicas,test_dev,"dev-a,test-a,test-b",<missing>`,
+		`Deployment.apps myapp/myapp,spec.template.spec.containers.[name=myapp].args,dev,dev-a,"[""--debug""]"`,
+		`Deployment.apps myapp/myapp,spec.template.spec.containers.[name=myapp].args,prod_test,"prod-a,prod-b,test-a,test-b",<missing>`,
+		`Deployment.apps myapp/myapp,spec.template.spec.containers.[name=myapp].image,prod_test,"prod-a,prod-b,test-a,test-b",myapp:v1.1`,
+		`Deployment.apps myapp/myapp,spec.template.spec.containers.[name=myapp].image,dev,dev-a,myapp:v1.2-345`,
+		``,
+	}, "\n")
+
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Logf("got:\n%s", got)
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
+
+func TestDiffReportMarkdown(t *testing.T) {
+	got, err := output.NewDiffReport(types.NewClusterIndex()).Markdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "# Cluster differences\n\nNo differences.\n"; want != string(got) {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	got, err = diffReportTestReport(t).Markdown()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"## ConfigMap myapp/myapp-env\n",
+		"| *resource* | test_dev | dev-a, test-a, test-b | `<missing>` |\n",
+		"| `spec.template.spec.containers.[name=myapp].args` | dev | dev-a | `[\"--debug\"]` |\n",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("want %q in:\n%s", want, got)
+		}
+	}
+}
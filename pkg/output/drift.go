@@ -0,0 +1,309 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	driftFormatText = "text"
+	driftFormatJSON = "json"
+
+	driftDesired types.ClusterID = 0
+	driftLive    types.ClusterID = 1
+)
+
+// ErrDrift is returned when the live state differs from the desired one.
+var ErrDrift = errors.New("drift detected")
+
+var errAmbiguousCluster = errors.New("ambiguous cluster mapping")
+
+func NewDriftOutput(spec *apis.Drift) (*DriftOutput, error) {
+	format := spec.GetFormat()
+	if len(format) == 0 {
+		format = driftFormatText
+	}
+
+	switch format {
+	case driftFormatText, driftFormatJSON:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedFormat, format)
+	}
+
+	path := spec.GetPath()
+	if len(path) == 0 {
+		path = "-"
+	}
+
+	return &DriftOutput{
+		Path:         path,
+		Format:       format,
+		ClusterNames: spec.GetClusterNames(),
+	}, nil
+}
+
+// DriftOutput lists per cluster the resources added to, removed from and
+// changed in the live state compared to the desired one.
+type DriftOutput struct {
+	Path         string            `yaml:"path"`
+	Format       string            `yaml:"format"`
+	ClusterNames map[string]string `yaml:"clusterNames"`
+}
+
+// DriftField is the field with the desired and the live values.
+type DriftField struct {
+	Path    string `json:"path"`
+	Desired string `json:"desired"`
+	Live    string `json:"live"`
+}
+
+type DriftResource struct {
+	Resource string       `json:"resource"`
+	Fields   []DriftField `json:"fields"`
+}
+
+type DriftCluster struct {
+	Name    string          `json:"name"`
+	Added   []string        `json:"added"`
+	Removed []string        `json:"removed"`
+	Changed []DriftResource `json:"changed"`
+}
+
+func (cluster *DriftCluster) drifted() bool {
+	return len(cluster.Added)+len(cluster.Removed)+len(cluster.Changed) > 0
+}
+
+type DriftReport struct {
+	Clusters []DriftCluster `json:"clusters"`
+}
+
+// Drifted reports whether any cluster differs from the desired state.
+func (report *DriftReport) Drifted() bool {
+	return slices.ContainsFunc(report.Clusters, func(cluster DriftCluster) bool {
+		return cluster.drifted()
+	})
+}
+
+func (out *DriftOutput) liveName(name string) string {
+	if liveName, found := out.ClusterNames[name]; found {
+		return liveName
+	}
+
+	return name
+}
+
+// Compare returns the differences between the resources of the clusters
+// with the matching names, the clusters missing from one of the states have
+// all their resources added or removed. The live clusters matching the same
+// desired cluster, e.g. both dev-a and the live name mapped to dev-a, are
+// rejected as ambiguous.
+func (out *DriftOutput) Compare(desired, live *types.ClusterResources) (*DriftReport, error) {
+	desiredIDs := map[string]types.ClusterID{}
+	liveIDs := map[string]types.ClusterID{}
+	names := []string{}
+
+	for clusterID, cluster := range desired.Clusters.All() {
+		desiredIDs[cluster.Name] = clusterID
+		names = append(names, cluster.Name)
+	}
+
+	liveNames := map[string]string{} // desired name by the live name
+	for _, name := range names {
+		liveName := out.liveName(name)
+		if other, found := liveNames[liveName]; found {
+			return nil, fmt.Errorf("%w: %s and %s are both mapped to %s", errAmbiguousCluster, other, name, liveName)
+		}
+
+		liveNames[liveName] = name
+	}
+
+	matched := map[string]string{} // live name by the desired name
+	for clusterID, cluster := range live.Clusters.All() {
+		name, found := liveNames[cluster.Name]
+		if !found {
+			name = cluster.Name
+		}
+
+		if other, found := matched[name]; found {
+			return nil, fmt.Errorf("%w: %s and %s both match %s", errAmbiguousCluster, other, cluster.Name, name)
+		}
+
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+
+		matched[name] = cluster.Name
+		liveIDs[name] = clusterID
+	}
+
+	report := &DriftReport{}
+
+	for _, name := range names {
+		cluster, err := compareCluster(
+			name,
+			clusterResources(desired, desiredIDs, name),
+			clusterResources(live, liveIDs, name),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Clusters = append(report.Clusters, cluster)
+	}
+
+	return report, nil
+}
+
+//nolint:lll
+func clusterResources(resources *types.ClusterResources, ids map[string]types.ClusterID, name string) map[resid.ResId]*yaml.RNode {
+	byID := map[resid.ResId]*yaml.RNode{}
+
+	clusterID, found := ids[name]
+	if !found {
+		return byID
+	}
+
+	for id, rnode := range resources.All(&clusterID) {
+		byID[id] = rnode
+	}
+
+	return byID
+}
+
+func compareCluster(name string, desired, live map[resid.ResId]*yaml.RNode) (DriftCluster, error) {
+	cluster := DriftCluster{Name: name, Added: []string{}, Removed: []string{}, Changed: []DriftResource{}}
+	ids := slices.SortedFunc(maps.Keys(desired), func(a, b resid.ResId) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, id := range ids {
+		liveNode, found := live[id]
+		if !found {
+			cluster.Removed = append(cluster.Removed, diffResourceName(id))
+			continue
+		}
+
+		fields, err := diffFields(id, map[types.ClusterID]*yaml.RNode{
+			driftDesired: desired[id],
+			driftLive:    liveNode,
+		})
+		if err != nil {
+			return DriftCluster{}, err
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		changed := DriftResource{Resource: diffResourceName(id)}
+		for _, field := range fields {
+			changed.Fields = append(changed.Fields, driftField(field))
+		}
+
+		cluster.Changed = append(cluster.Changed, changed)
+	}
+
+	for _, id := range slices.SortedFunc(maps.Keys(live), func(a, b resid.ResId) int {
+		return strings.Compare(a.String(), b.String())
+	}) {
+		if _, found := desired[id]; !found {
+			cluster.Added = append(cluster.Added, diffResourceName(id))
+		}
+	}
+
+	return cluster, nil
+}
+
+func driftField(field fieldDiff) DriftField {
+	result := DriftField{
+		Path:    field.path.String(),
+		Desired: diffValueMissing,
+		Live:    diffValueMissing,
+	}
+
+	for _, variant := range field.variants {
+		for _, clusterID := range variant.Clusters {
+			switch clusterID {
+			case driftDesired:
+				result.Desired = diffValue(variant.Value)
+			case driftLive:
+				result.Live = diffValue(variant.Value)
+			}
+		}
+	}
+
+	return result
+}
+
+// Text returns the report with a section per cluster.
+func (report *DriftReport) Text() []byte {
+	buffer := bytes.NewBuffer(nil)
+
+	for _, cluster := range report.Clusters {
+		if !cluster.drifted() {
+			fmt.Fprintf(buffer, "%s: no drift\n", cluster.Name)
+			continue
+		}
+
+		fmt.Fprintf(buffer, "%s: %d added, %d removed, %d changed\n",
+			cluster.Name, len(cluster.Added), len(cluster.Removed), len(cluster.Changed))
+
+		for _, name := range cluster.Added {
+			fmt.Fprintf(buffer, "  + %s\n", name)
+		}
+
+		for _, name := range cluster.Removed {
+			fmt.Fprintf(buffer, "  - %s\n", name)
+		}
+
+		for _, changed := range cluster.Changed {
+			fmt.Fprintf(buffer, "  ~ %s\n", changed.Resource)
+
+			for _, field := range changed.Fields {
+				fmt.Fprintf(buffer, "      %s: %s -> %s\n", field.Path, field.Desired, field.Live)
+			}
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+// Store writes the report, returning ErrDrift when any cluster differs from
+// the desired state.
+func (out *DriftOutput) Store(env *types.Env, report *DriftReport) error {
+	if filepath.IsAbs(out.Path) {
+		return fmt.Errorf("invalid drift report path: %w", errAbsPath)
+	}
+
+	body := report.Text()
+
+	if out.Format == driftFormatJSON {
+		var err error
+		if body, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		body = append(body, '\n')
+	}
+
+	if err := env.FileSys.WriteFile(out.Path, body); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if report.Drifted() {
+		return ErrDrift
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func driftTestResources(names []string, manifests ...[]string) *types.ClusterResources {
+	clusters := types.NewClusterIndex()
+	resources := map[resid.ResId]map[types.ClusterID]*yaml.RNode{}
+
+	for idx, name := range names {
+		clusterID := clusters.Add(types.Cluster{Name: name})
+
+		for _, manifest := range manifests[idx] {
+			rnode := yaml.MustParse(manifest)
+			id := resid.FromRNode(rnode)
+
+			if resources[id] == nil {
+				resources[id] = map[types.ClusterID]*yaml.RNode{}
+			}
+
+			resources[id][clusterID] = rnode
+		}
+	}
+
+	return &types.ClusterResources{Clusters: clusters, Resources: resources}
+}
+
+func TestDrift(t *testing.T) {
+	const (
+		deployment = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: app\n"
+		configMap  = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: env\n  namespace: app\n"
+		secret     = "apiVersion: v1\nkind: Secret\nmetadata:\n  name: token\n  namespace: app\n"
+	)
+
+	desired := driftTestResources([]string{"dev-a", "prod-a"},
+		[]string{deployment + "spec:\n  replicas: 2\n", configMap},
+		[]string{deployment + "spec:\n  replicas: 3\n"},
+	)
+	live := driftTestResources([]string{"kind-dev-a", "prod-a"},
+		[]string{deployment + "spec:\n  replicas: 5\n  paused: true\n", secret},
+		[]string{deployment + "spec:\n  replicas: 3\n"},
+	)
+
+	out, err := output.NewDriftOutput(&apis.Drift{
+		ClusterNames: map[string]string{"dev-a": "kind-dev-a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := out.Compare(desired, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := bytes.NewBuffer(nil)
+	env := &types.Env{
+		FileSys: fsutil.Stdio(filesys.MakeFsInMemory(), bytes.NewBuffer(nil), stdout),
+	}
+
+	if err := out.Store(env, report); !errors.Is(err, output.ErrDrift) {
+		t.Errorf("want %v, got %v", output.ErrDrift, err)
+	}
+
+	want := strings.Join([]string{
+		`dev-a: 1 added, 1 removed, 1 changed`,
+		`  + Secret app/token`,
+		`  - ConfigMap app/env`,
+		`  ~ Deployment.apps app/web`,
+		`      spec.replicas: 2 -> 5`,
+		`      spec.paused: <missing> -> true`,
+		`prod-a: no drift`,
+		``,
+	}, "\n")
+
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+
+	// the live clusters named as the desired ones are matched as well
+	report, err = out.Compare(desired, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Drifted() || len(report.Clusters) != 2 {
+		t.Errorf("want 2 clusters without drift, got %v", report)
+	}
+}
+
+func TestDriftAmbiguous(t *testing.T) {
+	tests := []struct {
+		name         string
+		desired      []string
+		live         []string
+		clusterNames map[string]string
+	}{
+		{
+			name:         "live-name-and-mapped-name",
+			desired:      []string{"dev-a", "prod-a"},
+			live:         []string{"dev-a", "kind-dev-a"},
+			clusterNames: map[string]string{"dev-a": "kind-dev-a"},
+		},
+		{
+			name:         "same-live-name",
+			desired:      []string{"dev-a", "prod-a"},
+			live:         []string{"kind"},
+			clusterNames: map[string]string{"dev-a": "kind", "prod-a": "kind"},
+		},
+		{
+			name:         "mapped-to-desired-name",
+			desired:      []string{"dev-a", "prod-a"},
+			live:         []string{"prod-a"},
+			clusterNames: map[string]string{"dev-a": "prod-a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := output.NewDriftOutput(&apis.Drift{ClusterNames: test.clusterNames})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			desired := driftTestResources(test.desired, make([][]string, len(test.desired))...)
+			live := driftTestResources(test.live, make([][]string, len(test.live))...)
+
+			_, err = out.Compare(desired, live)
+			if err == nil {
+				t.Fatal("want error, got none")
+			}
+
+			t.Logf("got expected error: %v", err)
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/resource"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	fluxNamespace            = "flux-system"
+	fluxDefaultSourceName    = "fleet"
+	fluxKustomizationVersion = "kustomize.toolkit.fluxcd.io/v1"
+	fluxSourceVersion        = "source.toolkit.fluxcd.io/v1"
+	fluxGitRepositoryKind    = "GitRepository"
+	fluxOCIRepositoryKind    = "OCIRepository"
+	fluxDefaultInterval      = "10m"
+	fluxDefaultGitRef        = "main"
+	fluxDefaultOCIRef        = "latest"
+	fluxLayerCRDs            = "crds"
+	fluxLayerNamespaces      = "namespaces"
+	fluxLayerApps            = "apps"
+)
+
+var errUnsupportedSourceKind = errors.New("unsupported source kind")
+
+// fluxLayers are applied in order, each layer depends on the previous ones.
+//
+//nolint:gochecknoglobals
+var fluxLayers = []string{fluxLayerCRDs, fluxLayerNamespaces, fluxLayerApps}
+
+func newFluxOutput(spec *apis.FluxOutput) (*FluxOutput, error) {
+	out := &FluxOutput{
+		URL:        spec.GetUrl(),
+		SourceKind: spec.GetSourceKind(),
+		SourceName: spec.GetSourceName(),
+		Ref:        spec.GetRef(),
+		Interval:   spec.GetInterval(),
+		Path:       spec.GetPath(),
+	}
+
+	if len(out.SourceKind) == 0 {
+		out.SourceKind = fluxGitRepositoryKind
+	}
+
+	// the generated source must not replace the flux-system source of
+	// flux bootstrap, which points at the cluster directory
+	switch {
+	case len(out.SourceName) > 0:
+	case len(out.URL) > 0:
+		out.SourceName = fluxDefaultSourceName
+	default:
+		out.SourceName = fluxNamespace
+	}
+
+	if len(out.Interval) == 0 {
+		out.Interval = fluxDefaultInterval
+	}
+
+	switch out.SourceKind {
+	case fluxGitRepositoryKind:
+		if len(out.Ref) == 0 {
+			out.Ref = fluxDefaultGitRef
+		}
+	case fluxOCIRepositoryKind:
+		if len(out.Ref) == 0 {
+			out.Ref = fluxDefaultOCIRef
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedSourceKind, out.SourceKind)
+	}
+
+	return out, nil
+}
+
+// FluxOutput lays out the Flux repository: the resources are grouped into
+// the components per layer (CRDs, namespaces and the rest), the overlays
+// combine the components of the clusters, and clusters/<name> holds the
+// Flux Kustomizations applying the overlays in order.
+type FluxOutput struct {
+	URL        string `yaml:"url"`
+	SourceKind string `yaml:"sourceKind"`
+	SourceName string `yaml:"sourceName"`
+	Ref        string `yaml:"ref"`
+	Interval   string `yaml:"interval"`
+	Path       string `yaml:"path"`
+}
+
+type objectMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type manifestObject struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+	Spec       any        `yaml:"spec,omitempty"`
+}
+
+type fluxReference struct {
+	Kind      string `yaml:"kind,omitempty"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+type fluxKustomizationSpec struct {
+	Interval  string          `yaml:"interval"`
+	Path      string          `yaml:"path"`
+	Prune     bool            `yaml:"prune"`
+	SourceRef fluxReference   `yaml:"sourceRef"`
+	DependsOn []fluxReference `yaml:"dependsOn,omitempty"`
+}
+
+type fluxRepositorySpec struct {
+	Interval string            `yaml:"interval"`
+	URL      string            `yaml:"url"`
+	Ref      map[string]string `yaml:"ref"`
+}
+
+func fluxLayer(id resid.ResId) string {
+	switch {
+	case id.Kind == "CustomResourceDefinition" && id.Group == "apiextensions.k8s.io":
+		return fluxLayerCRDs
+	case id.Kind == "Namespace" && id.Group == "":
+		return fluxLayerNamespaces
+	default:
+		return fluxLayerApps
+	}
+}
+
+func (out *FluxOutput) Store(env *types.Env, resources *types.ClusterResources) error {
+	const compsDir = "components"
+
+	layers := map[string]*Components{}
+
+	for id, byCluster := range resources.Resources {
+		layer := fluxLayer(id)
+
+		comps, found := layers[layer]
+		if !found {
+			comps = NewComponents(resources.Clusters)
+			layers[layer] = comps
+		}
+
+		if err := comps.Add(id, byCluster); err != nil {
+			return fmt.Errorf("unable to add resources to the component: %w", err)
+		}
+	}
+
+	for layer, comps := range layers {
+		if err := comps.Store(fsutil.Sub(env.FileSys, filepath.Join(compsDir, layer))); err != nil {
+			return fmt.Errorf("unable to store components: %w", err)
+		}
+	}
+
+	for clusterID, cluster := range resources.Clusters.All() {
+		objects := []manifestObject{}
+		dependsOn := []fluxReference{}
+
+		if len(out.URL) > 0 {
+			objects = append(objects, out.source())
+		}
+
+		for _, layer := range fluxLayers {
+			comps, found := layers[layer]
+			if !found {
+				continue
+			}
+
+			compNames, err := comps.Cluster(clusterID)
+			if errors.Is(err, errClusterNotFound) {
+				continue // no resources of the layer
+			}
+
+			if err != nil {
+				return err
+			}
+
+			overlayDir := filepath.Join("overlays", cluster.Name, layer)
+			if err := storeFluxOverlay(env.FileSys, overlayDir, filepath.Join(compsDir, layer), compNames); err != nil {
+				return err
+			}
+
+			objects = append(objects, out.kustomization(layer, overlayDir, dependsOn))
+			dependsOn = append(dependsOn, fluxReference{Name: layer})
+		}
+
+		if err := storeObjects(fsutil.Sub(env.FileSys, filepath.Join("clusters", cluster.Name)), objects); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (out *FluxOutput) source() manifestObject {
+	refKey := "branch"
+	if out.SourceKind == fluxOCIRepositoryKind {
+		refKey = "tag"
+	}
+
+	return manifestObject{
+		APIVersion: fluxSourceVersion,
+		Kind:       out.SourceKind,
+		Metadata:   objectMeta{Name: out.SourceName, Namespace: fluxNamespace},
+		Spec: fluxRepositorySpec{
+			Interval: out.Interval,
+			URL:      out.URL,
+			Ref:      map[string]string{refKey: out.Ref},
+		},
+	}
+}
+
+// sourcePath returns the path of the output directory in the source.
+func (out *FluxOutput) sourcePath(dir string) string {
+	return "./" + filepath.ToSlash(filepath.Join(out.Path, dir))
+}
+
+func (out *FluxOutput) kustomization(layer, overlayDir string, dependsOn []fluxReference) manifestObject {
+	return manifestObject{
+		APIVersion: fluxKustomizationVersion,
+		Kind:       "Kustomization",
+		Metadata:   objectMeta{Name: layer, Namespace: fluxNamespace},
+		Spec: fluxKustomizationSpec{
+			Interval:  out.Interval,
+			Path:      out.sourcePath(overlayDir),
+			Prune:     true,
+			SourceRef: fluxReference{Kind: out.SourceKind, Name: out.SourceName},
+			DependsOn: append([]fluxReference(nil), dependsOn...),
+		},
+	}
+}
+
+func storeFluxOverlay(fileSys filesys.FileSystem, overlayDir, compsDir string, compNames []string) error {
+	kust := &types.Kustomization{}
+	kust.Kind = types.KustomizationKind
+
+	for _, compName := range compNames {
+		relPath, err := filepath.Rel(overlayDir, filepath.Join(compsDir, compName))
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+
+		kust.Components = append(kust.Components, relPath)
+	}
+
+	fileStore := resource.FileStore{FileSystem: fsutil.Sub(fileSys, overlayDir)}
+	if err := fileStore.WriteKustomization(kust); err != nil {
+		return fmt.Errorf("unable to store kustomization: %w", err)
+	}
+
+	return nil
+}
+
+// storeObjects writes the objects to the directory, a file per object named
+// after the object name and kind, without a kustomization.yaml.
+func storeObjects(fileSys filesys.FileSystem, objects []manifestObject) error {
+	nodes, err := objectNodes(objects)
+	if err != nil {
+		return err
+	}
+
+	fileStore := &resource.FileStore{
+		FileSystem: fileSys,
+		NameGenerator: func(id resid.ResId) string {
+			return strings.ToLower(id.Name + "-" + id.Kind + ".yaml")
+		},
+	}
+
+	if err := fileStore.WriteAll(maps.All(nodes)); err != nil {
+		return fmt.Errorf("unable to store objects: %w", err)
+	}
+
+	return nil
+}
+
+func objectNodes(objects []manifestObject) (map[resid.ResId]*yaml.RNode, error) {
+	nodes := map[resid.ResId]*yaml.RNode{}
+
+	for _, object := range objects {
+		body, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize %s: %w", object.Metadata.Name, err)
+		}
+
+		rnode, err := yaml.Parse(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize %s: %w", object.Metadata.Name, err)
+		}
+
+		nodes[resid.FromRNode(rnode)] = rnode
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,88 @@
+package output_test
+
+import (
+	"embed"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/e2e"
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+//go:embed testdata/flux
+var fluxFs embed.FS
+
+// layeredTestResources returns the resources of the CRD, namespace and apps
+// layers, shared by the GitOps repository outputs.
+func layeredTestResources() *types.ClusterResources {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+	namespace := yaml.MustParse("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: myapp\n")
+	crd := yaml.MustParse("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: myapps.example.com\n")
+	deployments := map[types.ClusterID]*yaml.RNode{
+		devA:  yaml.MustParse(appDevA),
+		prodA: yaml.MustParse(appProdA),
+	}
+
+	return &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(namespace):         {devA: namespace, prodA: namespace},
+			resid.FromRNode(crd):               {prodA: crd},
+			resid.FromRNode(deployments[devA]): deployments,
+		},
+	}
+}
+
+// chartTestResources returns the deployments of the clusters tagged as
+// dev, prod and test, shared by the outputs generating the chart.
+func chartTestResources() *types.ClusterResources {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a", Tags: []string{"dev"}})
+	prodA := clusters.Add(types.Cluster{Name: "prod-a", Tags: []string{"prod"}})
+	prodB := clusters.Add(types.Cluster{Name: "prod-b", Tags: []string{"prod"}})
+	testA := clusters.Add(types.Cluster{Name: "test-a", Tags: []string{"test"}})
+	testB := clusters.Add(types.Cluster{Name: "test-b", Tags: []string{"test"}})
+	deployments := map[types.ClusterID]*yaml.RNode{
+		devA:  yaml.MustParse(appDevA),
+		prodA: yaml.MustParse(appProdA),
+		prodB: yaml.MustParse(appProdB),
+		testA: yaml.MustParse(appTestA),
+		testB: yaml.MustParse(appTestB),
+	}
+
+	return &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(deployments[devA]): deployments,
+		},
+	}
+}
+
+func TestFlux(t *testing.T) {
+	out, err := output.New(&apis.Output{Flux: &apis.FluxOutput{
+		Url: proto.String("https://git.example.com/fleet.git"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, layeredTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := e2e.ReadFiles(t, gotFs, ".")
+	want := e2e.ReadFsFiles(t, fluxFs, "testdata/flux")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("flux repository mismatch, +got -want:\n%s", diff)
+	}
+}
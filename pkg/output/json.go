@@ -7,6 +7,7 @@ import (
 
 	"github.com/Mirantis/ktl/pkg/apis"
 	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func newJSONOutput(spec *apis.JSONOutput) (*JSONOutput, error) {
@@ -17,6 +18,11 @@ type JSONOutput struct {
 	*apis.JSONOutput
 }
 
+type jsonRecord struct {
+	Cluster string      `json:"cluster,omitempty"`
+	Fields  *yaml.RNode `json:"fields"`
+}
+
 func (out *JSONOutput) Store(env *types.Env, resources *types.ClusterResources) error {
 	path := out.GetPath()
 	if filepath.IsAbs(path) {
@@ -27,7 +33,7 @@ func (out *JSONOutput) Store(env *types.Env, resources *types.ClusterResources)
 
 	for clusterID, _ := range resources.Clusters.All() {
 		for _, rnode := range resources.All(&clusterID) {
-			if len(body) > 0 {
+			if len(body) > 0 || len(resources.Records) > 0 {
 				return fmt.Errorf("JSON output only supports single-object results")
 			}
 
@@ -39,5 +45,24 @@ func (out *JSONOutput) Store(env *types.Env, resources *types.ClusterResources)
 		}
 	}
 
+	if len(resources.Records) > 0 {
+		records := []jsonRecord{}
+
+		for _, record := range resources.Records {
+			item := jsonRecord{Fields: record.Fields}
+			if record.Cluster != nil {
+				item.Cluster = resources.Clusters.Cluster(*record.Cluster).Name
+			}
+
+			records = append(records, item)
+		}
+
+		var err error
+		body, err = json.MarshalIndent(map[string][]jsonRecord{"records": records}, "", "  ")
+		if err != nil {
+			return err
+		}
+	}
+
 	return env.FileSys.WriteFile(path, body) //nolint:wrapcheck
 }
@@ -0,0 +1,377 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/fsutil"
+	"github.com/Mirantis/ktl/pkg/resource"
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	k0rdentVersion             = "k0rdent.mirantis.com/v1beta1"
+	k0rdentManagedLabel        = "k0rdent.mirantis.com/managed"
+	k0rdentDefaultNamespace    = "kcm-system"
+	k0rdentDefaultRepository   = "ktl"
+	k0rdentModeMultiCluster    = "multiClusterService"
+	k0rdentModeClusterDeploy   = "clusterDeployment"
+	k0rdentChartInterval       = "10m"
+	clusterTagLabelPrefix      = "tags.ktl.mirantis.com/"
+	clusterTagLabelValue       = "true"
+	k0rdentOCIRepositoryPrefix = "oci://"
+	k0rdentHelmRepositoryKind  = "HelmRepository"
+	k0rdentOCIRepositoryType   = "oci"
+)
+
+var (
+	errMissingChart    = errors.New("helm chart is required")
+	errUnsupportedMode = errors.New("unsupported mode")
+)
+
+func newK0rdentOutput(spec *apis.K0RdentOutput) (*K0rdentOutput, error) {
+	chartSpec := spec.GetHelmChart()
+	if chartSpec == nil {
+		return nil, errMissingChart
+	}
+
+	if deployment := chartSpec.GetDeployment(); len(deployment) != 0 {
+		return nil, fmt.Errorf("%w: %s with k0rdent", errUnsupportedDeployment, deployment)
+	}
+
+	if chartSpec.GetLayeredValues() {
+		return nil, fmt.Errorf("%w: layered values with k0rdent", errUnsupportedDeployment)
+	}
+
+	chart, err := newChartOutput(chartSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &K0rdentOutput{
+		Namespace:        spec.GetNamespace(),
+		Repository:       spec.GetRepository(),
+		RepositoryURL:    spec.GetRepositoryUrl(),
+		ServiceNamespace: spec.GetServiceNamespace(),
+		Mode:             spec.GetMode(),
+		chart:            chart,
+	}
+
+	if len(out.Namespace) == 0 {
+		out.Namespace = k0rdentDefaultNamespace
+	}
+
+	if len(out.Repository) == 0 {
+		out.Repository = k0rdentDefaultRepository
+	}
+
+	if len(out.ServiceNamespace) == 0 {
+		out.ServiceNamespace = chart.HelmChart.Name
+	}
+
+	if len(out.Mode) == 0 {
+		out.Mode = k0rdentModeMultiCluster
+	}
+
+	switch out.Mode {
+	case k0rdentModeMultiCluster, k0rdentModeClusterDeploy:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedMode, out.Mode)
+	}
+
+	return out, nil
+}
+
+// K0rdentOutput stores the Helm chart of the resources, the ServiceTemplate
+// installing the chart, and the services of the clusters with the values of
+// the chart: the MultiClusterServices selecting the clusters by the labels
+// of their tags, or the ClusterDeployment patches.
+type K0rdentOutput struct {
+	Namespace        string `yaml:"namespace"`
+	Repository       string `yaml:"repository"`
+	RepositoryURL    string `yaml:"repositoryURL"`
+	ServiceNamespace string `yaml:"serviceNamespace"`
+	Mode             string `yaml:"mode"`
+	chart            *ChartOutput
+}
+
+type k0rdentService struct {
+	Template  string `yaml:"template"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+	Values    string `yaml:"values,omitempty"`
+}
+
+type k0rdentServiceSpec struct {
+	Services []k0rdentService `yaml:"services"`
+}
+
+type k0rdentMultiClusterServiceSpec struct {
+	ClusterSelector map[string]any     `yaml:"clusterSelector"`
+	ServiceSpec     k0rdentServiceSpec `yaml:"serviceSpec"`
+}
+
+type k0rdentClusterDeploymentSpec struct {
+	ServiceSpec k0rdentServiceSpec `yaml:"serviceSpec"`
+}
+
+type k0rdentChartSpec struct {
+	Chart     string        `yaml:"chart"`
+	Version   string        `yaml:"version"`
+	Interval  string        `yaml:"interval"`
+	SourceRef fluxReference `yaml:"sourceRef"`
+}
+
+type k0rdentServiceTemplateSpec struct {
+	Helm struct {
+		ChartSpec k0rdentChartSpec `yaml:"chartSpec"`
+	} `yaml:"helm"`
+}
+
+type k0rdentRepositorySpec struct {
+	URL  string `yaml:"url"`
+	Type string `yaml:"type,omitempty"`
+}
+
+// clusterLabels returns the labels of the cluster, naming the cluster and
+// its tags.
+func clusterLabels(cluster types.Cluster) map[string]string {
+	labels := map[string]string{clusterLabel: cluster.Name}
+	for _, tag := range cluster.Tags {
+		labels[clusterTagLabelPrefix+tag] = clusterTagLabelValue
+	}
+
+	return labels
+}
+
+// clusterSelector returns the label selector matching the clusters, by the
+// label of the tag shared exactly by the clusters when there is one,
+// otherwise by the names of the clusters.
+func clusterSelector(clusters *types.ClusterIndex, ids []types.ClusterID) map[string]any {
+	tags := []string{}
+	for _, id := range ids {
+		tags = append(tags, clusters.Cluster(id).Tags...)
+	}
+
+	slices.Sort(tags)
+
+	for _, tag := range slices.Compact(tags) {
+		tagged := []types.ClusterID{}
+
+		for clusterID, cluster := range clusters.All() {
+			if slices.Contains(cluster.Tags, tag) {
+				tagged = append(tagged, clusterID)
+			}
+		}
+
+		if slices.Equal(tagged, ids) {
+			return map[string]any{
+				"matchLabels": map[string]string{clusterTagLabelPrefix + tag: clusterTagLabelValue},
+			}
+		}
+	}
+
+	return map[string]any{
+		"matchExpressions": []map[string]any{{
+			"key":      clusterLabel,
+			"operator": "In",
+			"values":   slices.Collect(clusters.Names(ids...)),
+		}},
+	}
+}
+
+func (out *K0rdentOutput) templateName() string {
+	meta := out.chart.HelmChart
+
+	return meta.Name + "-" + strings.ReplaceAll(meta.Version, ".", "-")
+}
+
+func (out *K0rdentOutput) service(values string) k0rdentService {
+	return k0rdentService{
+		Template:  out.templateName(),
+		Name:      out.chart.HelmChart.Name,
+		Namespace: out.ServiceNamespace,
+		Values:    values,
+	}
+}
+
+// values returns the values of the chart installed to the cluster.
+func (out *K0rdentOutput) values(chart *Chart, clusterID types.ClusterID) (string, error) {
+	inline := chart.Instance(clusterID).ValuesInline
+	if len(inline) == 0 {
+		return "", nil
+	}
+
+	body, err := yaml.Marshal(inline)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize values: %w", err)
+	}
+
+	return string(body), nil
+}
+
+func (out *K0rdentOutput) Store(env *types.Env, resources *types.ClusterResources) error {
+	chart, chartDir, err := out.chart.storeChart(env, resources)
+	if err != nil {
+		return err
+	}
+
+	if out.chart.spec.GetVerify() {
+		values := map[types.ClusterID][]map[string]any{}
+		for clusterID := range resources.Clusters.All() {
+			values[clusterID] = []map[string]any{chart.Instance(clusterID).ValuesInline}
+		}
+
+		if err := verifyChart(env.FileSys, resources, chartDir, values); err != nil {
+			return err
+		}
+	}
+
+	objects := []manifestObject{out.serviceTemplate()}
+	if len(out.RepositoryURL) > 0 {
+		objects = append(objects, out.repository())
+	}
+
+	clusterObjects := []manifestObject{}
+	groups := map[string][]types.ClusterID{} // clusters by the values
+	groupValues := []string{}
+
+	for clusterID, cluster := range resources.Clusters.All() {
+		values, err := out.values(chart, clusterID)
+		if err != nil {
+			return err
+		}
+
+		if _, found := groups[values]; !found {
+			groupValues = append(groupValues, values)
+		}
+
+		groups[values] = append(groups[values], clusterID)
+
+		var spec any
+		if out.Mode == k0rdentModeClusterDeploy {
+			spec = k0rdentClusterDeploymentSpec{
+				ServiceSpec: k0rdentServiceSpec{Services: []k0rdentService{out.service(values)}},
+			}
+		}
+
+		clusterObjects = append(clusterObjects, manifestObject{
+			APIVersion: k0rdentVersion,
+			Kind:       "ClusterDeployment",
+			Metadata: objectMeta{
+				Name:      cluster.Name,
+				Namespace: out.Namespace,
+				Labels:    clusterLabels(cluster),
+			},
+			Spec: spec,
+		})
+	}
+
+	if out.Mode == k0rdentModeMultiCluster {
+		for _, values := range groupValues {
+			ids := groups[values]
+			group := strings.ToLower(strings.ReplaceAll(resources.Clusters.Group(ids...), "_", "-"))
+
+			objects = append(objects, manifestObject{
+				APIVersion: k0rdentVersion,
+				Kind:       "MultiClusterService",
+				Metadata:   objectMeta{Name: out.chart.HelmChart.Name + "-" + group},
+				Spec: k0rdentMultiClusterServiceSpec{
+					ClusterSelector: clusterSelector(resources.Clusters, ids),
+					ServiceSpec:     k0rdentServiceSpec{Services: []k0rdentService{out.service(values)}},
+				},
+			})
+		}
+	}
+
+	if err := storeObjects(fsutil.Sub(env.FileSys, "k0rdent"), objects); err != nil {
+		return err
+	}
+
+	return storePatches(fsutil.Sub(env.FileSys, "k0rdent/clusters"), clusterObjects)
+}
+
+// storePatches writes the patches of the objects defined elsewhere, a file
+// per object, with the Kustomize component applying them, so the directory
+// is included as the component of the kustomization defining the objects.
+func storePatches(fileSys filesys.FileSystem, objects []manifestObject) error {
+	nodes, err := objectNodes(objects)
+	if err != nil {
+		return err
+	}
+
+	kust := &types.Kustomization{}
+	kust.Kind = types.ComponentKind
+	fileStore := &resource.FileStore{
+		FileSystem: fileSys,
+		NameGenerator: func(id resid.ResId) string {
+			return strings.ToLower(id.Name + "-" + id.Kind + "-patch.yaml")
+		},
+	}
+
+	if err := fileStore.WriteAll(maps.All(nodes)); err != nil {
+		return fmt.Errorf("unable to store patches: %w", err)
+	}
+
+	// the patches with the targets skip the objects missing from the
+	// kustomization, e.g. the clusters defined in other directories
+	for id := range nodes {
+		kust.Patches = append(kust.Patches, types.Patch{
+			Path:   fileStore.NameGenerator(id),
+			Target: &types.Selector{ResId: id},
+		})
+	}
+
+	slices.SortFunc(kust.Patches, func(a, b types.Patch) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if err := fileStore.WriteKustomization(kust); err != nil {
+		return fmt.Errorf("unable to store kustomization.yaml: %w", err)
+	}
+
+	return nil
+}
+
+func (out *K0rdentOutput) serviceTemplate() manifestObject {
+	meta := out.chart.HelmChart
+	spec := k0rdentServiceTemplateSpec{}
+	spec.Helm.ChartSpec = k0rdentChartSpec{
+		Chart:     meta.Name,
+		Version:   meta.Version,
+		Interval:  k0rdentChartInterval,
+		SourceRef: fluxReference{Kind: k0rdentHelmRepositoryKind, Name: out.Repository},
+	}
+
+	return manifestObject{
+		APIVersion: k0rdentVersion,
+		Kind:       "ServiceTemplate",
+		Metadata:   objectMeta{Name: out.templateName(), Namespace: out.Namespace},
+		Spec:       spec,
+	}
+}
+
+func (out *K0rdentOutput) repository() manifestObject {
+	spec := k0rdentRepositorySpec{URL: out.RepositoryURL}
+	if strings.HasPrefix(out.RepositoryURL, k0rdentOCIRepositoryPrefix) {
+		spec.Type = k0rdentOCIRepositoryType
+	}
+
+	return manifestObject{
+		APIVersion: fluxSourceVersion,
+		Kind:       k0rdentHelmRepositoryKind,
+		Metadata: objectMeta{
+			Name:      out.Repository,
+			Namespace: out.Namespace,
+			Labels:    map[string]string{k0rdentManagedLabel: "true"},
+		},
+		Spec: spec,
+	}
+}
@@ -0,0 +1,171 @@
+package output_test
+
+import (
+	"embed"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/apis"
+	"github.com/Mirantis/ktl/pkg/e2e"
+	"github.com/Mirantis/ktl/pkg/output"
+	"github.com/Mirantis/ktl/pkg/types"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+//go:embed testdata/k0rdent
+var k0rdentFs embed.FS
+
+func TestK0rdent(t *testing.T) {
+	out, err := output.New(&apis.Output{K0Rdent: &apis.K0RdentOutput{
+		HelmChart:     &apis.HelmChartOutput{Name: "myapp", Version: "0.1.0"},
+		RepositoryUrl: proto.String("oci://registry.example.com/charts"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !gotFs.Exists("charts/myapp/Chart.yaml") {
+		t.Error("chart is missing")
+	}
+
+	got := map[string]string{}
+	for path, body := range e2e.ReadFiles(t, gotFs, ".") {
+		if objectPath, found := strings.CutPrefix(path, "k0rdent/"); found {
+			got[objectPath] = body
+		}
+	}
+	want := e2e.ReadFsFiles(t, k0rdentFs, "testdata/k0rdent")
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("k0rdent objects mismatch, +got -want:\n%s", diff)
+	}
+}
+
+func TestK0rdentUnsupportedDeployment(t *testing.T) {
+	for name, chart := range map[string]*apis.HelmChartOutput{
+		"deployment": {
+			Name:       "myapp",
+			Version:    "0.1.0",
+			Deployment: proto.String("values"),
+		},
+		"layered-values": {
+			Name:          "myapp",
+			Version:       "0.1.0",
+			LayeredValues: proto.Bool(true),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := output.New(&apis.Output{K0Rdent: &apis.K0RdentOutput{HelmChart: chart}})
+			if err == nil || !strings.Contains(err.Error(), "unsupported chart deployment") {
+				t.Errorf("want unsupported chart deployment, got %v", err)
+			}
+		})
+	}
+}
+
+func TestK0rdentClusterDeployments(t *testing.T) {
+	out, err := output.New(&apis.Output{K0Rdent: &apis.K0RdentOutput{
+		HelmChart: &apis.HelmChartOutput{Name: "myapp", Version: "0.1.0"},
+		Mode:      proto.String("clusterDeployment"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := gotFs.ReadFile("k0rdent/clusters/prod-b-clusterdeployment-patch.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `apiVersion: k0rdent.mirantis.com/v1beta1
+kind: ClusterDeployment
+metadata:
+  name: prod-b
+  namespace: kcm-system
+  labels:
+    ktl.mirantis.com/cluster: prod-b
+    tags.ktl.mirantis.com/prod: "true"
+spec:
+  serviceSpec:
+    services:
+    - template: myapp-0-1-0
+      name: myapp
+      namespace: myapp
+      values: |
+        global:
+          myapp/Deployment/myapp.spec.replicas: 5
+        presets:
+        - prod
+        - prod_test
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+
+	if gotFs.Exists("k0rdent/myapp-prod-a-multiclusterservice.yaml") {
+		t.Error("unexpected MultiClusterService")
+	}
+}
+
+func TestK0rdentClusterPatches(t *testing.T) {
+	out, err := output.New(&apis.Output{K0Rdent: &apis.K0RdentOutput{
+		HelmChart: &apis.HelmChartOutput{Name: "myapp", Version: "0.1.0"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotFs := filesys.MakeFsInMemory()
+	if err := out.Store(&types.Env{FileSys: gotFs}, chartTestResources()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the clusters missing from the kustomization are skipped
+	files := map[string]string{
+		"dev-a.yaml":         "apiVersion: k0rdent.mirantis.com/v1beta1\nkind: ClusterDeployment\nmetadata:\n  name: dev-a\n  namespace: kcm-system\nspec:\n  template: aws\n",
+		"kustomization.yaml": "resources:\n- dev-a.yaml\ncomponents:\n- k0rdent/clusters\n",
+	}
+	for path, body := range files {
+		if err := gotFs.WriteFile(path, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(gotFs, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := resMap.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `apiVersion: k0rdent.mirantis.com/v1beta1
+kind: ClusterDeployment
+metadata:
+  labels:
+    ktl.mirantis.com/cluster: dev-a
+    tags.ktl.mirantis.com/dev: "true"
+  name: dev-a
+  namespace: kcm-system
+spec:
+  template: aws
+`
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("-want +got:\n%s", diff)
+	}
+}
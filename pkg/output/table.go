@@ -30,9 +30,14 @@ func (out *TableOutput) Store(env *types.Env, resources *types.ClusterResources)
 		return fmt.Errorf("invalid table output path: %w", errAbsPath)
 	}
 
+	rows, err := out.rows(resources)
+	if err != nil {
+		return err
+	}
+
 	buffer := bytes.NewBuffer(nil)
 
-	err := func() error {
+	err = func() error {
 		tabWriter := printers.GetNewTabWriter(buffer)
 		defer tabWriter.Flush()
 
@@ -40,7 +45,7 @@ func (out *TableOutput) Store(env *types.Env, resources *types.ClusterResources)
 		csvWriter.Comma = '\t'
 		defer csvWriter.Flush()
 
-		for _, row := range out.rows(resources) {
+		for _, row := range rows {
 			if err := csvWriter.Write(row); err != nil {
 				return err //nolint:wrapcheck
 			}
@@ -50,5 +50,21 @@ func New(spec *apis.Output) (Impl, error) {
 		return newJSONOutput(implSpec)
 	}
 
+	if implSpec := spec.GetDiffReport(); implSpec != nil {
+		return newDiffReportOutput(implSpec)
+	}
+
+	if implSpec := spec.GetFlux(); implSpec != nil {
+		return newFluxOutput(implSpec)
+	}
+
+	if implSpec := spec.GetArgocd(); implSpec != nil {
+		return newArgoCDOutput(implSpec)
+	}
+
+	if implSpec := spec.GetK0Rdent(); implSpec != nil {
+		return newK0rdentOutput(implSpec)
+	}
+
 	return nil, errors.New("unsupported output")
 }
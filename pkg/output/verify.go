@@ -0,0 +1,171 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var errVerification = errors.New("output differs from the source resources")
+
+// buildOverlay builds the kustomization in-process as kustomize build does.
+func buildOverlay(fileSys filesys.FileSystem, dir string) ([]*yaml.RNode, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+
+	resMap, err := kustomizer.Run(fileSys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build %s: %w", dir, err)
+	}
+
+	return resMap.ToRNodeSlice(), nil
+}
+
+// verifyOverlays builds overlays/<cluster> of every cluster and compares
+// the resources to the source ones.
+func verifyOverlays(fileSys filesys.FileSystem, resources *types.ClusterResources) error {
+	errs := []error{}
+
+	for clusterID, cluster := range resources.Clusters.All() {
+		built, err := buildOverlay(fileSys, filepath.Join("overlays", cluster.Name))
+		if err != nil {
+			return err
+		}
+
+		errs = append(errs, verifyCluster(resources, clusterID, built))
+	}
+
+	return errors.Join(errs...)
+}
+
+// verifyCluster compares the resources generated for the cluster to its
+// source resources, regardless of the order of the resources and the fields,
+// and of the comments.
+//
+//nolint:lll
+func verifyCluster(resources *types.ClusterResources, clusterID types.ClusterID, generated []*yaml.RNode) error {
+	want := map[string]*yaml.RNode{}
+
+	for id, byCluster := range resources.Resources {
+		if rnode, found := byCluster[clusterID]; found {
+			want[id.String()] = rnode
+		}
+	}
+
+	got := map[string]*yaml.RNode{}
+	for _, rnode := range generated {
+		got[resid.FromRNode(rnode).String()] = rnode
+	}
+
+	diffs := []string{}
+
+	for _, key := range slices.Sorted(maps.Keys(want)) {
+		if _, found := got[key]; !found {
+			diffs = append(diffs, key+": missing")
+
+			continue
+		}
+
+		wantValue, err := resourceValue(want[key])
+		if err != nil {
+			return err
+		}
+
+		gotValue, err := resourceValue(got[key])
+		if err != nil {
+			return err
+		}
+
+		for _, diff := range diffValues("", wantValue, gotValue) {
+			diffs = append(diffs, key+": "+diff)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(got)) {
+		if _, found := want[key]; !found {
+			diffs = append(diffs, key+": unexpected")
+		}
+	}
+
+	if len(diffs) > 0 {
+		cluster := resources.Clusters.Cluster(clusterID).Name
+
+		return fmt.Errorf("%w: %s:\n  %s", errVerification, cluster, strings.Join(diffs, "\n  "))
+	}
+
+	return nil
+}
+
+// resourceValue returns the resource as the plain values, without the
+// comments and the styles of the nodes.
+func resourceValue(rnode *yaml.RNode) (any, error) {
+	var value any
+	if err := rnode.YNode().Decode(&value); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", resid.FromRNode(rnode), err)
+	}
+
+	return value, nil
+}
+
+// diffValues returns the fields differing between the values.
+func diffValues(path string, want, got any) []string {
+	wantMap, wantIsMap := want.(map[string]any)
+	gotMap, gotIsMap := got.(map[string]any)
+
+	if wantIsMap && gotIsMap {
+		diffs := []string{}
+
+		for _, key := range slices.Sorted(maps.Keys(wantMap)) {
+			if _, found := gotMap[key]; !found {
+				diffs = append(diffs, fmt.Sprintf("%s: missing, want %v", joinPath(path, key), wantMap[key]))
+
+				continue
+			}
+
+			diffs = append(diffs, diffValues(joinPath(path, key), wantMap[key], gotMap[key])...)
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(gotMap)) {
+			if _, found := wantMap[key]; !found {
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %v", joinPath(path, key), gotMap[key]))
+			}
+		}
+
+		return diffs
+	}
+
+	wantList, wantIsList := want.([]any)
+	gotList, gotIsList := got.([]any)
+
+	if wantIsList && gotIsList && len(wantList) == len(gotList) {
+		diffs := []string{}
+		for idx := range wantList {
+			diffs = append(diffs, diffValues(fmt.Sprintf("%s[%d]", path, idx), wantList[idx], gotList[idx])...)
+		}
+
+		return diffs
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		return []string{fmt.Sprintf("%s: want %#v, got %#v", path, want, got)}
+	}
+
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,175 @@
+package output
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Mirantis/ktl/pkg/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestVerifyCluster(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a"})
+	source := yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  debug: "true"
+  level: info
+`)
+	resources := &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(source): {devA: source},
+		},
+	}
+
+	same := yaml.MustParse(`# comment
+kind: ConfigMap
+apiVersion: v1
+data:
+  level: info
+  debug: 'true'
+metadata:
+  name: cfg
+`)
+	if err := verifyCluster(resources, devA, []*yaml.RNode{same}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	differing := yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  debug: true
+  extra: value
+`)
+	extra := yaml.MustParse(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: extra
+`)
+
+	err := verifyCluster(resources, devA, []*yaml.RNode{differing, extra})
+	if !errors.Is(err, errVerification) {
+		t.Fatalf("want %v, got %v", errVerification, err)
+	}
+
+	for _, want := range []string{
+		`data.debug: want "true", got true`,
+		`data.extra: unexpected value`,
+		`data.level: missing, want info`,
+		`Namespace.v1.[noGrp]/extra.[noNs]: unexpected`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("want %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestVerifyClusterInvalidNode(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a"})
+	source := yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  debug: "true"
+`)
+	resources := &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(source): {devA: source},
+		},
+	}
+
+	invalid := yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  debug: !!int "true"
+`)
+
+	err := verifyCluster(resources, devA, []*yaml.RNode{invalid})
+	if err == nil || !strings.Contains(err.Error(), "unable to decode ConfigMap.v1.[noGrp]/cfg.[noNs]") {
+		t.Errorf("want decode error, got %v", err)
+	}
+}
+
+func TestVerifyChartValues(t *testing.T) {
+	clusters := types.NewClusterIndex()
+	devA := clusters.Add(types.Cluster{Name: "dev-a"})
+	source := yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  level: debug
+  replicas: "5"
+`)
+	resources := &types.ClusterResources{
+		Clusters: clusters,
+		Resources: map[resid.ResId]map[types.ClusterID]*yaml.RNode{
+			resid.FromRNode(source): {devA: source},
+		},
+	}
+
+	fileSys := filesys.MakeFsInMemory()
+	for path, body := range map[string]string{
+		"chart/values.yaml": "global:\n  level: info\n  replicas: 1\n",
+		"chart/values.schema.json": `{
+  "$schema": "https://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "global": {
+      "type": "object",
+      "properties": {"level": {"type": "string"}, "replicas": {"type": "integer"}},
+      "additionalProperties": false
+    }
+  },
+  "additionalProperties": false
+}
+`,
+		"chart/templates/cfg.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  level: {{ .Values.global.level }}
+  replicas: "{{ .Values.global.replicas }}"
+`,
+	} {
+		if err := fileSys.WriteFile(path, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the values of the cluster override the values of the preset
+	preset := map[string]any{"global": map[string]any{"level": "debug", "replicas": 3}}
+	cluster := map[string]any{"global": map[string]any{"replicas": 5}}
+
+	values := map[types.ClusterID][]map[string]any{devA: {preset, cluster}}
+	if err := verifyChart(fileSys, resources, "chart", values); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	values = map[types.ClusterID][]map[string]any{devA: {cluster, preset}}
+	if err := verifyChart(fileSys, resources, "chart", values); !errors.Is(err, errVerification) {
+		t.Errorf("want %v, got %v", errVerification, err)
+	}
+
+	unknown := map[string]any{"global": map[string]any{"levle": "debug"}}
+
+	values = map[types.ClusterID][]map[string]any{devA: {preset, cluster, unknown}}
+	if err := verifyChart(fileSys, resources, "chart", values); !errors.Is(err, errInvalidValues) {
+		t.Errorf("want %v, got %v", errInvalidValues, err)
+	}
+}
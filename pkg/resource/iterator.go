@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/Mirantis/ktl/pkg/types"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 	"sigs.k8s.io/kustomize/kyaml/yaml/schema"
@@ -56,6 +57,13 @@ func (it *Iterator) Schema() *openapi.ResourceSchema {
 	return it.current.schema
 }
 
+// FieldSchema returns the schema of the current field as declared by its
+// parent, before the reference to the type of the field is resolved, e.g.
+// with the description of the field rather than of its type.
+func (it *Iterator) FieldSchema() *spec.Schema {
+	return it.current.field
+}
+
 func (it *Iterator) Clusters() []types.ClusterID {
 	clusters := make([]types.ClusterID, 0, len(it.clusters))
 	for cluster := range it.Values() {
@@ -108,6 +116,7 @@ func (it *Iterator) Next() bool {
 
 type iteratorState struct {
 	schema  *openapi.ResourceSchema
+	field   *spec.Schema
 	path    Query
 	values  []*yaml.Node
 	indices []int
@@ -199,6 +208,7 @@ func (is *iteratorState) mappingFields() ([]*iteratorState, error) {
 
 				if is.schema != nil {
 					state.schema = is.schema.Field(pathPart)
+					state.field = is.fieldSchema(pathPart)
 				}
 			}
 
@@ -210,6 +220,24 @@ func (is *iteratorState) mappingFields() ([]*iteratorState, error) {
 	return slices.Collect(maps.Values(states)), nil
 }
 
+// fieldSchema returns the unresolved schema of the field, as
+// openapi.ResourceSchema.Field looks it up.
+func (is *iteratorState) fieldSchema(name string) *spec.Schema {
+	if is.schema.Schema == nil {
+		return nil
+	}
+
+	if field, found := is.schema.Schema.Properties[name]; found {
+		return &field
+	}
+
+	if additional := is.schema.Schema.AdditionalProperties; additional != nil && additional.Schema != nil {
+		return additional.Schema
+	}
+
+	return nil
+}
+
 var errInvalidKV = errors.New("invalid keys/values")
 
 func kvPathPart(key, values []string) string {
@@ -228,6 +256,12 @@ func kvPathPart(key, values []string) string {
 func (is *iteratorState) listElements() ([]*iteratorState, error) {
 	schema := is.schema.Elements()
 	key := is.mergeKey()
+
+	var field *spec.Schema
+	if is.schema != nil && is.schema.Schema != nil && is.schema.Schema.Items != nil {
+		field = is.schema.Schema.Items.Schema
+	}
+
 	states := map[string]*iteratorState{}
 	allKeyValues := make([][][]string, len(is.values))
 
@@ -254,6 +288,7 @@ func (is *iteratorState) listElements() ([]*iteratorState, error) {
 			if !exists {
 				state = &iteratorState{
 					schema:  schema,
+					field:   field,
 					path:    append(slices.Clone(is.path), pathPart),
 					values:  make([]*yaml.Node, len(is.values)),
 					indices: make([]int, len(is.indices)),
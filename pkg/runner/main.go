@@ -1,9 +1,12 @@
 package runner
 
 import (
+	"cmp"
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/Mirantis/ktl/pkg/apis"
 	"github.com/Mirantis/ktl/pkg/filters"
@@ -23,13 +26,22 @@ var (
 	defaultsYaml []byte
 
 	errUnsupportedKind = errors.New("unsupported source")
+	errDriftOutput     = errors.New("output not supported in drift mode")
 )
 
 type Pipeline struct {
 	Source Source `yaml:"source"`
 	Output Output `yaml:"output"`
 
-	Filters []kfilters.KFilter `yaml:"filters"`
+	Filters      []kfilters.KFilter    `yaml:"filters"`
+	FleetFilters []filters.FleetFilter `yaml:"-"`
+	Drift        *Drift                `yaml:"-"`
+}
+
+// Drift compares the source with the live state passing the same filters.
+type Drift struct {
+	Live   Source
+	Output *output.DriftOutput
 }
 
 type rekustomization Pipeline
@@ -66,9 +78,29 @@ func NewPipeline(spec *apis.Pipeline, args *yaml.RNode) (*Pipeline, error) {
 		return nil, err
 	}
 
-	out, err := output.New(spec.GetOutput())
-	if err != nil {
-		return nil, err
+	if driftSpec := spec.GetDrift(); driftSpec != nil {
+		if spec.GetOutput() != nil {
+			return nil, errDriftOutput
+		}
+
+		live, err := source.New(driftSpec.GetLive())
+		if err != nil {
+			return nil, err
+		}
+
+		driftOut, err := output.NewDriftOutput(driftSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline.Drift = &Drift{Live: Source{live}, Output: driftOut}
+	} else {
+		out, err := output.New(spec.GetOutput())
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline.Output = Output{out}
 	}
 
 	defaultFilters := true
@@ -87,8 +119,16 @@ func NewPipeline(spec *apis.Pipeline, args *yaml.RNode) (*Pipeline, error) {
 		pipeline.Filters = append(pipeline.Filters, filter)
 	}
 
+	for _, filterSpec := range spec.GetFleetFilters() {
+		filter, err := filters.NewFleet(filterSpec, args)
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline.FleetFilters = append(pipeline.FleetFilters, filter)
+	}
+
 	pipeline.Source = Source{src}
-	pipeline.Output = Output{out}
 
 	if defaultFilters {
 		pipeline.Filters = append(pipeline.Filters, defaults.Filters...)
@@ -98,20 +138,60 @@ func NewPipeline(spec *apis.Pipeline, args *yaml.RNode) (*Pipeline, error) {
 }
 
 func (cfg *Pipeline) Run(env *types.Env) error {
-	filters := []kio.Filter{}
+	return cfg.RunContext(context.Background(), env)
+}
+
+// RunContext runs the pipeline, cancelling the running scripts when the
+// context is done.
+func (cfg *Pipeline) RunContext(ctx context.Context, env *types.Env) error {
+	scope := filters.NewScope(env)
+	scope.Context = ctx
+
+	cres, err := cfg.load(env, scope, cfg.Source)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Drift != nil {
+		live, err := cfg.load(env, scope, cfg.Drift.Live)
+		if err != nil {
+			return err
+		}
+
+		report, err := cfg.Drift.Output.Compare(cres, live)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return cfg.Drift.Output.Store(env, report) //nolint:wrapcheck
+	}
+
+	return cfg.Output.Store(env, cres) //nolint:wrapcheck
+}
+
+// load returns the resources of the source passed through the filters.
+func (cfg *Pipeline) load(env *types.Env, scope *filters.Scope, src Source) (*types.ClusterResources, error) {
+	kioFilters := []kio.Filter{}
 
 	for i := range cfg.Filters {
-		filters = append(filters, cfg.Filters[i].Filter)
+		if scoped, ok := cfg.Filters[i].Filter.(filters.Scoped); ok {
+			scoped.SetScope(scope)
+		}
+
+		kioFilters = append(kioFilters, cfg.Filters[i].Filter)
 	}
 
-	sres, err := cfg.Source.Load(env)
+	sres, err := src.Load(env)
 	if err != nil {
-		return err //nolint:wrapcheck
+		return nil, err //nolint:wrapcheck
 	}
 
 	ridx := map[resid.ResId]map[types.ClusterID]*yaml.RNode{}
+	records := []types.Record{}
 
 	for clusterID, nodes := range sres.Resources {
+		cluster := sres.Clusters.Cluster(clusterID)
+		scope.Cluster = &cluster
 		filtered := &kio.PackageBuffer{}
 		pipeline := &kio.Pipeline{
 			Inputs: []kio.Reader{
@@ -120,14 +200,19 @@ func (cfg *Pipeline) Run(env *types.Env) error {
 				},
 			},
 			Outputs: []kio.Writer{filtered},
-			Filters: filters,
+			Filters: kioFilters,
 		}
 
 		if err := pipeline.Execute(); err != nil {
-			return err //nolint:wrapcheck
+			return nil, err //nolint:wrapcheck
 		}
 
 		for _, node := range filtered.Nodes {
+			if fields, isRecord := types.RecordFields(node); isRecord {
+				records = append(records, types.Record{Cluster: &clusterID, Fields: fields})
+				continue
+			}
+
 			nodeID := resid.FromRNode(node)
 
 			byCluster, idFound := ridx[nodeID]
@@ -140,10 +225,29 @@ func (cfg *Pipeline) Run(env *types.Env) error {
 		}
 	}
 
+	// the clusters are filtered in random order
+	slices.SortStableFunc(records, func(a, b types.Record) int {
+		return cmp.Compare(*a.Cluster, *b.Cluster)
+	})
+
 	cres := &types.ClusterResources{
 		Clusters:  sres.Clusters,
 		Resources: ridx,
+		Records:   records,
 	}
 
-	return cfg.Output.Store(env, cres) //nolint:wrapcheck
+	scope.Cluster = nil
+
+	for _, filter := range cfg.FleetFilters {
+		if scoped, ok := filter.(filters.Scoped); ok {
+			scoped.SetScope(scope)
+		}
+
+		cres, err = filter.FilterFleet(cres)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	return cres, nil
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"iter"
@@ -274,9 +275,8 @@ func (o *orderTagsBySizeAndName) Swap(a, b int) {
 }
 
 func (o *orderTagsBySizeAndName) Less(a, b int) bool { //nolint:varnamelen
-	delta := o.bitmaps[a].GetCardinality() - o.bitmaps[b].GetCardinality()
-	if delta != 0 {
-		return delta > 0
+	if d := cmp.Compare(o.bitmaps[a].GetCardinality(), o.bitmaps[b].GetCardinality()); d != 0 {
+		return d > 0 // descending
 	}
 
 	return strings.Compare(o.tags[a], o.tags[b]) < 0
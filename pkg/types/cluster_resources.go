@@ -10,6 +10,7 @@ import (
 type ClusterResources struct {
 	Clusters  *ClusterIndex
 	Resources map[resid.ResId]map[ClusterID]*yaml.RNode
+	Records   []Record
 }
 
 func (res *ClusterResources) All(cluster *ClusterID) iter.Seq2[resid.ResId, *yaml.RNode] {
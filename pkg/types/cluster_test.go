@@ -59,3 +59,19 @@ func TestClusterIndexGroup(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterIndexGroupTagOrder(t *testing.T) {
+	// the tags covering more clusters name the group first, regardless of
+	// the order of the tags in the index, which is rebuilt from a map
+	for range 20 {
+		idx := types.NewClusterIndex()
+		c1 := idx.Add(types.Cluster{Name: "c1", Tags: []string{"big", "small"}}) //nolint:varnamelen
+		c2 := idx.Add(types.Cluster{Name: "c2", Tags: []string{"big"}})          //nolint:varnamelen
+		c3 := idx.Add(types.Cluster{Name: "c3", Tags: []string{"big"}})          //nolint:varnamelen
+		idx.Add(types.Cluster{Name: "c4"})
+
+		if got, want := idx.Group(c1, c2, c3), "big"; got != want {
+			t.Fatalf("got: %s, want: %s", got, want)
+		}
+	}
+}
@@ -149,6 +149,16 @@ func (sel *PatternSelector) Select(names []string) []string {
 	return result
 }
 
+// MatchAny checks if any of the names is included and none is excluded,
+// e.g. to match a set of cluster tags.
+func (sel *PatternSelector) MatchAny(names []string) bool {
+	if slices.ContainsFunc(names, sel.Exclude.Match) {
+		return false
+	}
+
+	return len(sel.Include) == 0 || slices.ContainsFunc(names, sel.Include.Match)
+}
+
 func (sel *PatternSelector) UnmarshalYAML(node *yaml.Node) error {
 	if node == nil {
 		*sel = PatternSelector{}
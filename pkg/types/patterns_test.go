@@ -164,3 +164,54 @@ func TestPatternSelector(t *testing.T) {
 		})
 	}
 }
+
+func TestPatternSelectorMatchAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector types.PatternSelector
+		input    []string
+		want     bool
+	}{
+		{
+			name:  "no patterns",
+			input: []string{"dev"},
+			want:  true,
+		},
+		{
+			name:     "include",
+			selector: types.PatternSelector{Include: types.Patterns{"prod*"}},
+			input:    []string{"dev", "prod-eu"},
+			want:     true,
+		},
+		{
+			name:     "include-no-match",
+			selector: types.PatternSelector{Include: types.Patterns{"prod*"}},
+			input:    []string{"dev"},
+			want:     false,
+		},
+		{
+			name:     "include-empty",
+			selector: types.PatternSelector{Include: types.Patterns{"prod*"}},
+			want:     false,
+		},
+		{
+			name:     "exclude",
+			selector: types.PatternSelector{Exclude: types.Patterns{"prod*"}},
+			input:    []string{"dev", "prod-eu"},
+			want:     false,
+		},
+		{
+			name:     "exclude-empty",
+			selector: types.PatternSelector{Exclude: types.Patterns{"prod*"}},
+			want:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.selector.MatchAny(test.input); got != test.want {
+				t.Errorf("want %v, got %v", test.want, got)
+			}
+		})
+	}
+}
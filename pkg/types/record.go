@@ -0,0 +1,50 @@
+package types
+
+import (
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	RecordAPIVersion = "ktl.mirantis.com/v1"
+	RecordKind       = "Record"
+
+	recordFieldsKey = "fields"
+)
+
+// Record is the structured output of the scripts, e.g. the report row, kept
+// apart from the resources grouped by the resource ID.
+type Record struct {
+	// Cluster is nil for the records not related to a single cluster.
+	Cluster *ClusterID
+	Fields  *yaml.RNode
+}
+
+// NewRecordNode wraps the record fields, so the record passes the resource
+// filters unchanged.
+func NewRecordNode(fields *yaml.Node) *yaml.Node {
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			yaml.NewStringRNode(yaml.APIVersionField).YNode(),
+			yaml.NewStringRNode(RecordAPIVersion).YNode(),
+			yaml.NewStringRNode(yaml.KindField).YNode(),
+			yaml.NewStringRNode(RecordKind).YNode(),
+			yaml.NewStringRNode(recordFieldsKey).YNode(),
+			fields,
+		},
+	}
+}
+
+// RecordFields returns the fields of the node wrapped by NewRecordNode.
+func RecordFields(rnode *yaml.RNode) (*yaml.RNode, bool) {
+	if rnode.GetApiVersion() != RecordAPIVersion || rnode.GetKind() != RecordKind {
+		return nil, false
+	}
+
+	fields := rnode.Field(recordFieldsKey)
+	if fields == nil {
+		return yaml.NewMapRNode(nil), true
+	}
+
+	return fields.Value, true
+}